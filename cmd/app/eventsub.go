@@ -0,0 +1,129 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/esfands/retpaladinbot/config"
+	"github.com/esfands/retpaladinbot/internal/services/eventsub"
+)
+
+const eventSubUsage = `Usage: retpaladinbot eventsub simulate [flags] <type>
+
+Sends a signed EventSub webhook message to the API, the way Twitch would.
+
+Types:
+%s
+Flags:
+`
+
+// runEventSub runs the eventsub subcommand and returns the exit code
+func runEventSub(args []string) int {
+	if len(args) == 0 || args[0] != "simulate" {
+		fmt.Fprintln(os.Stderr, "Usage: retpaladinbot eventsub simulate [flags] <type>")
+		return 2
+	}
+
+	fs := flag.NewFlagSet("eventsub simulate", flag.ContinueOnError)
+	fs.Usage = func() {
+		var types strings.Builder
+		for _, t := range eventsub.Types {
+			fmt.Fprintf(&types, "  %s\n", t.Name)
+		}
+		fmt.Fprintf(fs.Output(), eventSubUsage, types.String())
+		fs.PrintDefaults()
+	}
+
+	url := fs.String("url", "http://localhost:3000/v1/twitch/eventsub/", "the EventSub endpoint the message is sent to")
+	message := fs.String("message", eventsub.MessageNotification, "the message type: notification, verification or revocation")
+	secret := fs.String("secret", "", "the secret the message is signed with (default is eventsub_secret of the config)")
+	broadcasterID := fs.String("broadcaster", "", "the ID of the broadcaster (default is channel_id of the config)")
+	broadcasterLogin := fs.String("broadcaster-login", "", "the login of the broadcaster (default is channel of the config)")
+	userID := fs.String("user", "12345678", "the ID of the user who follows, subs, cheers, raids and so on")
+	userLogin := fs.String("user-login", "simulated_user", "the login of the user who follows, subs, cheers, raids and so on")
+	rewardID := fs.String("reward", "", "the ID of the channel point reward which is redeemed (default is a random one)")
+	input := fs.String("input", "", "what the redeemer typed for a channel point reward")
+
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+
+	// The config is only needed for what wasn't passed as a flag
+	if *secret == "" || *broadcasterID == "" || *broadcasterLogin == "" {
+		cfg, err := config.New(Version, time.Now())
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Pass --secret and --broadcaster when there's no config:", err)
+			return 1
+		}
+
+		if *secret == "" {
+			*secret = cfg.Twitch.Helix.EventSubSecret
+		}
+		if *broadcasterID == "" {
+			*broadcasterID = cfg.Twitch.Bot.ChannelID
+		}
+		if *broadcasterLogin == "" {
+			*broadcasterLogin = cfg.Twitch.Bot.Channel
+		}
+	}
+
+	messageType := *message
+	if messageType == "verification" {
+		messageType = eventsub.MessageVerification
+	}
+
+	sim, err := eventsub.Simulate(eventsub.SimulateOptions{
+		Type:             fs.Arg(0),
+		MessageType:      messageType,
+		Callback:         *url,
+		BroadcasterID:    *broadcasterID,
+		BroadcasterLogin: *broadcasterLogin,
+		UserID:           *userID,
+		UserLogin:        *userLogin,
+		RewardID:         *rewardID,
+		Input:            *input,
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	req, err := sim.Request(*url, *secret)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Couldn't build the request:", err)
+		return 1
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	res, err := client.Do(req)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Couldn't send the message:", err)
+		return 1
+	}
+	defer res.Body.Close()
+
+	body, _ := io.ReadAll(res.Body)
+	fmt.Printf("%s %s -> %s\n", sim.MessageType, fs.Arg(0), res.Status)
+	if len(body) > 0 {
+		fmt.Println(string(body))
+	}
+
+	if res.StatusCode/100 != 2 {
+		return 1
+	}
+	if sim.Challenge != "" && strings.TrimSpace(string(body)) != sim.Challenge {
+		fmt.Fprintln(os.Stderr, "The response isn't the challenge", sim.Challenge)
+		return 1
+	}
+
+	return 0
+}
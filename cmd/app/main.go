@@ -14,10 +14,18 @@ import (
 	"github.com/esfands/retpaladinbot/internal/bot"
 	"github.com/esfands/retpaladinbot/internal/global"
 	"github.com/esfands/retpaladinbot/internal/rest"
+	"github.com/esfands/retpaladinbot/internal/services/announcements"
 	"github.com/esfands/retpaladinbot/internal/services/auth"
+	"github.com/esfands/retpaladinbot/internal/services/cooldowns"
+	"github.com/esfands/retpaladinbot/internal/services/events"
+	"github.com/esfands/retpaladinbot/internal/services/eventsub"
 	"github.com/esfands/retpaladinbot/internal/services/helix"
+	"github.com/esfands/retpaladinbot/internal/services/modulestore"
+	"github.com/esfands/retpaladinbot/internal/services/rewards"
 	"github.com/esfands/retpaladinbot/internal/services/scheduler"
+	"github.com/esfands/retpaladinbot/internal/services/timers"
 	"github.com/esfands/retpaladinbot/internal/services/turso"
+	"github.com/esfands/retpaladinbot/internal/services/usernotices"
 )
 
 var (
@@ -26,6 +34,10 @@ var (
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "eventsub" {
+		os.Exit(runEventSub(os.Args[2:]))
+	}
+
 	Timestamp = time.Now().Format(time.RFC3339)
 
 	version := os.Getenv("VERSION")
@@ -61,7 +73,8 @@ func main() {
 	{
 		slog.Info("Setting up Turso database")
 		gctx.Crate().Turso, err = turso.Setup(gctx, turso.SetupOptions{
-			URL: cfg.Turso.URL,
+			URL:              cfg.Turso.URL,
+			DefaultChannelID: cfg.Twitch.Bot.ChannelID,
 		})
 		if err != nil {
 			slog.Error("Error setting up Turso database", "error", err)
@@ -71,6 +84,36 @@ func main() {
 		slog.Info("Turso database setup complete")
 	}
 
+	{
+		slog.Info("Setting up cooldown store")
+		gctx.Crate().Cooldowns, err = cooldowns.Setup(cooldowns.SetupOptions{
+			Backend: cfg.Twitch.Bot.CooldownStore,
+			Queries: gctx.Crate().Turso.Queries(),
+		})
+		if err != nil {
+			slog.Error("Error setting up cooldown store", "error", err)
+			cancel()
+			return
+		}
+
+		slog.Info("Cooldown store setup complete")
+	}
+
+	{
+		slog.Info("Setting up module store")
+		gctx.Crate().Modules, err = modulestore.Setup(gctx, gctx.Crate().Turso.Queries())
+		if err != nil {
+			slog.Error("Error setting up module store", "error", err)
+			cancel()
+			return
+		}
+
+		slog.Info("Module store setup complete")
+	}
+
+	// The API, the bot and the modules tell each other about streams, chat messages and EventSub events through here
+	gctx.Crate().Events = events.New()
+
 	{
 		slog.Info("Setting up scheduler")
 		gctx.Crate().Scheduler, err = scheduler.Setup(gctx)
@@ -83,12 +126,28 @@ func main() {
 		slog.Info("Scheduler setup complete")
 	}
 
+	{
+		slog.Info("Setting up announcements")
+		gctx.Crate().Announcements, err = announcements.Setup(gctx, gctx.Crate().Turso.Queries(), gctx.Crate().Scheduler)
+		if err != nil {
+			slog.Error("Error setting up announcements", "error", err)
+			cancel()
+			return
+		}
+
+		slog.Info("Announcements setup complete")
+	}
+
+	gctx.Crate().Timers = timers.NewStore(gctx.Crate().Turso.Queries())
+	gctx.Crate().UserNotices = usernotices.NewStore(gctx.Crate().Turso.Queries())
+
 	{
 		slog.Info("Setting up Helix API")
 		gctx.Crate().Helix, err = helix.Setup(gctx, gctx.Crate().Scheduler, helix.SetupOptions{
 			ClientID:     cfg.Twitch.Helix.ClientID,
 			ClientSecret: cfg.Twitch.Helix.ClientSecret,
 			RedirectURI:  cfg.Twitch.Helix.RedirectURI,
+			Queries:      gctx.Crate().Turso.Queries(),
 		})
 		if err != nil {
 			slog.Error("Error setting up Helix API", "error", err)
@@ -98,6 +157,19 @@ func main() {
 
 		slog.Info("Helix API setup complete")
 	}
+
+	gctx.Crate().Rewards = rewards.New(gctx.Crate().Turso.Queries(), gctx.Crate().Helix)
+
+	gctx.Crate().EventSub = eventsub.Setup(eventsub.SetupOptions{
+		Helix:            gctx.Crate().Helix,
+		Queries:          gctx.Crate().Turso.Queries(),
+		Events:           gctx.Crate().Events,
+		Transport:        cfg.Twitch.Helix.EventSubTransport,
+		DefaultChannelID: cfg.Twitch.Bot.ChannelID,
+		Callback:         cfg.Twitch.Helix.EventSubCallback,
+		Secret:           cfg.Twitch.Helix.EventSubSecret,
+		WebSocketURL:     cfg.Twitch.Helix.EventSubWebSocketURL,
+	})
 	{
 		slog.Info("Setting up auth")
 		gctx.Crate().Auth = auth.Setup(
@@ -133,6 +205,7 @@ func main() {
 		fmt.Println("shutting down")
 
 		wg.Wait()
+		gctx.Crate().Events.Close()
 
 		close(done)
 	}()
@@ -146,6 +219,31 @@ func main() {
 		slog.Info("Bot stopped")
 	}()
 
+	// Twitch verifies new subscriptions through the API, so they're reconciled while it starts. The WebSocket
+	// subscribes every session it starts on its own.
+	if cfg.Twitch.Helix.EventSubTransport == eventsub.TransportWebSocket {
+		go func() {
+			slog.Info("Connecting to the EventSub WebSocket")
+			if err := gctx.Crate().EventSub.Connect(gctx); err != nil {
+				slog.Error("Error receiving EventSub notifications", "error", err)
+			}
+			slog.Info("EventSub WebSocket disconnected")
+		}()
+	} else if cfg.Twitch.Helix.EventSubCallback != "" {
+		go func() {
+			slog.Info("Reconciling EventSub subscriptions")
+			result, err := gctx.Crate().EventSub.Reconcile(gctx)
+			if err != nil {
+				slog.Error("Error reconciling EventSub subscriptions", "error", err)
+				return
+			}
+
+			slog.Info("EventSub subscriptions reconciled", "created", len(result.Created), "deleted", len(result.Deleted), "kept", result.Kept)
+		}()
+	} else {
+		slog.Warn("No EventSub callback configured, subscriptions aren't managed")
+	}
+
 	go func() {
 		defer wg.Done()
 
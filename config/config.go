@@ -20,13 +20,27 @@ type Config struct {
 			ChannelID string `mapstructure:"channel_id" json:"channel_id"`
 			Username  string `mapstructure:"username" json:"username"`
 			OAuth     string `mapstructure:"oauth" json:"oauth"`
+			// CooldownStore is where command cooldowns are kept, memory (default) or database
+			CooldownStore string `mapstructure:"cooldown_store" json:"cooldown_store"`
+			// Admins are the Twitch user IDs which may manage every channel and the EventSub subscriptions from the
+			// dashboard, along with the broadcaster of Channel. Other broadcasters may only manage their own channel.
+			Admins []string `mapstructure:"admins" json:"admins"`
 		} `mapstructure:"bot" json:"bot"`
 
 		Helix struct {
 			ClientID       string `mapstructure:"client_id" json:"client_id"`
 			ClientSecret   string `mapstructure:"client_secret" json:"client_secret"`
 			EventSubSecret string `mapstructure:"eventsub_secret" json:"eventsub_secret"`
-			RedirectURI    string `mapstructure:"redirect_uri" json:"redirect_uri"`
+			// EventSubCallback is the https URL of the EventSub endpoint, subscriptions are managed on boot when it's set
+			EventSubCallback string `mapstructure:"eventsub_callback" json:"eventsub_callback"`
+			// EventSubTransport is how notifications are received, webhook (default) or websocket. The WebSocket doesn't
+			// need a public callback, which makes it the one to use for local development. It subscribes with the token
+			// the broadcaster of the bot's channel connected, so other channels only get the subscriptions which need
+			// no permission.
+			EventSubTransport string `mapstructure:"eventsub_transport" json:"eventsub_transport"`
+			// EventSubWebSocketURL overrides the EventSub WebSocket of Twitch, e.g. with the mock server of the Twitch CLI
+			EventSubWebSocketURL string `mapstructure:"eventsub_websocket_url" json:"eventsub_websocket_url"`
+			RedirectURI          string `mapstructure:"redirect_uri" json:"redirect_uri"`
 		} `mapstructure:"helix" json:"helix"`
 	} `mapstructure:"twitch" json:"twitch"`
 
@@ -14,6 +14,7 @@ require (
 	github.com/tursodatabase/libsql-client-go v0.0.0-20240711072234-6d998acd081f
 	github.com/valyala/fasthttp v1.51.0
 	golang.org/x/oauth2 v0.18.0
+	nhooyr.io/websocket v1.8.10
 )
 
 require (
@@ -57,5 +58,4 @@ require (
 	gopkg.in/ini.v1 v1.67.0 // indirect
 	gopkg.in/square/go-jose.v2 v2.6.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
-	nhooyr.io/websocket v1.8.10 // indirect
 )
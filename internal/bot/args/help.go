@@ -0,0 +1,90 @@
+package args
+
+import (
+	"fmt"
+	"html"
+	"strings"
+)
+
+// Usage returns one usage line for every form of the command, command is the prefixed command name
+func Usage(schema *Schema, command string) []string {
+	if len(schema.Subcommands) == 0 {
+		return []string{strings.TrimSpace(command + " " + usage(schema))}
+	}
+
+	var lines []string
+	for i := range schema.Subcommands {
+		sub := &schema.Subcommands[i]
+		lines = append(lines, Usage(&sub.Schema, command+" "+sub.Name)...)
+	}
+	return lines
+}
+
+// Help generates the HTML help text shown on the website, in the same form commands used to write by hand
+func Help(schema *Schema, command string) []string {
+	var lines []string
+	if schema.Description != "" {
+		lines = append(lines, html.EscapeString(schema.Description))
+	}
+
+	if len(schema.Subcommands) == 0 {
+		lines = append(lines, fmt.Sprintf("<code>%v</code>", html.EscapeString(Usage(schema, command)[0])))
+
+		for _, arg := range schema.Args {
+			if arg.Description != "" {
+				lines = append(lines, fmt.Sprintf("<code>%v</code> %v", html.EscapeString(arg.Name), html.EscapeString(arg.Description)))
+			}
+		}
+		for _, option := range schema.Options {
+			if option.Description != "" {
+				lines = append(lines, fmt.Sprintf("<code>--%v</code> %v", html.EscapeString(option.Name), html.EscapeString(option.Description)))
+			}
+		}
+
+		return lines
+	}
+
+	for i := range schema.Subcommands {
+		sub := &schema.Subcommands[i]
+		if len(lines) > 0 {
+			lines = append(lines, "<br/>")
+		}
+		lines = append(lines, Help(&sub.Schema, command+" "+sub.Name)...)
+	}
+
+	return lines
+}
+
+// usage describes the arguments of a single level of the schema, (required) [optional] (rest...)
+func usage(schema *Schema) string {
+	if len(schema.Subcommands) > 0 {
+		var names []string
+		for _, sub := range schema.Subcommands {
+			names = append(names, sub.Name)
+		}
+		return fmt.Sprintf("(%v)", strings.Join(names, "|"))
+	}
+
+	var parts []string
+	for _, arg := range schema.Args {
+		name := arg.Name
+		if len(arg.Choices) > 0 {
+			name = strings.Join(arg.Choices, "|")
+		}
+		if arg.Rest {
+			name += "..."
+		}
+
+		if arg.Optional {
+			parts = append(parts, fmt.Sprintf("[%v]", name))
+		} else {
+			parts = append(parts, fmt.Sprintf("(%v)", name))
+		}
+	}
+
+	for _, option := range schema.Options {
+		parts = append(parts, fmt.Sprintf("[--%v %v]", option.Name, option.Type))
+	}
+
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,157 @@
+package args
+
+import (
+	"fmt"
+	"strings"
+)
+
+// UsageError is returned when the arguments don't match the schema, its message includes the correct usage
+type UsageError struct {
+	Reason string
+	Usage  string
+}
+
+func (e *UsageError) Error() string {
+	return fmt.Sprintf("%v. Usage: %v", e.Reason, e.Usage)
+}
+
+// Parse parses the input after the command trigger, command is the prefixed trigger and is used in usage errors
+func Parse(schema *Schema, command, input string) (Values, error) {
+	values := Values{
+		values: make(map[string]any),
+	}
+
+	err := parse(schema, command, input, tokenize(input), &values)
+	return values, err
+}
+
+func parse(schema *Schema, command, input string, tokens []token, values *Values) error {
+	usageErr := func(format string, a ...any) error {
+		return &UsageError{
+			Reason: fmt.Sprintf(format, a...),
+			Usage:  strings.TrimSpace(command + " " + usage(schema)),
+		}
+	}
+
+	if len(schema.Subcommands) > 0 {
+		if len(tokens) == 0 {
+			return usageErr("Missing subcommand")
+		}
+
+		for i := range schema.Subcommands {
+			sub := &schema.Subcommands[i]
+			if strings.EqualFold(tokens[0].value, sub.Name) {
+				values.Subcommand = strings.TrimSpace(values.Subcommand + " " + sub.Name)
+				return parse(&sub.Schema, command+" "+sub.Name, input, tokens[1:], values)
+			}
+		}
+
+		return usageErr("Unknown subcommand %q", tokens[0].value)
+	}
+
+	position := 0
+	for i := 0; i < len(tokens); i++ {
+		t := tokens[i]
+
+		if name, value, ok := optionToken(schema, t); ok {
+			option, found := findOption(schema, name)
+			if !found {
+				return usageErr("Unknown option --%v", name)
+			}
+
+			// --name value takes the next token as the value
+			if value == nil {
+				if i+1 >= len(tokens) {
+					return usageErr("Missing value for --%v", option.Name)
+				}
+				i++
+				value = &tokens[i].value
+			}
+
+			converted, err := convert(option.Name, option.Type, *value)
+			if err != nil {
+				return usageErr("%v", err.Error())
+			}
+			values.values[option.Name] = converted
+			continue
+		}
+
+		if position >= len(schema.Args) {
+			return usageErr("Too many arguments")
+		}
+
+		arg := schema.Args[position]
+		position++
+
+		if arg.Rest {
+			values.values[arg.Name] = strings.TrimSpace(input[t.start:])
+			break
+		}
+
+		raw := t.value
+		if len(arg.Choices) > 0 {
+			choice, ok := findChoice(arg.Choices, raw)
+			if !ok {
+				return usageErr("%v must be one of %v", arg.Name, strings.Join(arg.Choices, ", "))
+			}
+			raw = choice
+		}
+
+		converted, err := convert(arg.Name, arg.Type, raw)
+		if err != nil {
+			return usageErr("%v", err.Error())
+		}
+		values.values[arg.Name] = converted
+	}
+
+	for _, arg := range schema.Args {
+		if !arg.Optional && !values.Has(arg.Name) {
+			return usageErr("Missing %v", arg.Name)
+		}
+	}
+
+	return nil
+}
+
+// optionToken reports whether a token is an option, value is nil when it has to be taken from the next token
+func optionToken(schema *Schema, t token) (string, *string, bool) {
+	if t.quoted {
+		return "", nil, false
+	}
+
+	if strings.HasPrefix(t.value, "--") && len(t.value) > 2 {
+		name, value, found := strings.Cut(t.value[2:], "=")
+		if found {
+			return strings.ToLower(name), &value, true
+		}
+		return strings.ToLower(name), nil, true
+	}
+
+	// key:value is only an option when the key is declared, so text like URLs stays a positional argument
+	name, value, found := strings.Cut(t.value, ":")
+	if found && value != "" {
+		if option, ok := findOption(schema, name); ok {
+			return option.Name, &value, true
+		}
+	}
+
+	return "", nil, false
+}
+
+func findOption(schema *Schema, name string) (Option, bool) {
+	for _, option := range schema.Options {
+		if strings.EqualFold(option.Name, name) {
+			return option, true
+		}
+	}
+	return Option{}, false
+}
+
+func findChoice(choices []string, value string) (string, bool) {
+	for _, choice := range choices {
+		if strings.EqualFold(choice, value) {
+			return choice, true
+		}
+	}
+	return "", false
+}
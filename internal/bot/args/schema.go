@@ -0,0 +1,159 @@
+package args
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Type int
+
+const (
+	String Type = iota
+	Int
+	Float
+	// Duration accepts Go durations like 1h30m, or a plain number of seconds
+	Duration
+	// User accepts a Twitch login with or without a leading @
+	User
+	// Channel accepts a Twitch channel name with or without a leading @ or #
+	Channel
+)
+
+func (t Type) String() string {
+	switch t {
+	case Int, Float:
+		return "number"
+	case Duration:
+		return "duration"
+	case User:
+		return "user"
+	case Channel:
+		return "channel"
+	default:
+		return "text"
+	}
+}
+
+// Schema declares the arguments a command accepts
+type Schema struct {
+	// Description is shown above the usage in the generated help text
+	Description string
+	Args        []Arg
+	// Options can be given anywhere before a rest argument as --name value, --name=value or name:value
+	Options []Option
+	// Subcommands are chosen by the first argument, each one has its own arguments
+	Subcommands []Subcommand
+}
+
+// Arg is a positional argument
+type Arg struct {
+	Name        string
+	Type        Type
+	Description string
+	Optional    bool
+	// Rest takes the raw remainder of the input, it must be the last argument
+	Rest bool
+	// Choices limits the argument to a set of values, they're matched case-insensitively
+	Choices []string
+}
+
+// Option is a named argument which can be given in any order
+type Option struct {
+	Name        string
+	Type        Type
+	Description string
+}
+
+type Subcommand struct {
+	Name string
+	Schema
+}
+
+// Provider is implemented by commands which declare their arguments, the arguments are parsed before the command
+// runs and the command only runs when they are valid
+type Provider interface {
+	Arguments() *Schema
+}
+
+// Values holds the parsed arguments of a command
+type Values struct {
+	// Subcommand is the name of the chosen subcommand, nested subcommands are separated by spaces
+	Subcommand string
+
+	values map[string]any
+}
+
+// Has reports whether an argument or option was given
+func (v Values) Has(name string) bool {
+	_, ok := v.values[name]
+	return ok
+}
+
+// String returns a text, user or channel argument, or an empty string if it wasn't given
+func (v Values) String(name string) string {
+	value, _ := v.values[name].(string)
+	return value
+}
+
+// Int returns a number argument, or 0 if it wasn't given
+func (v Values) Int(name string) int {
+	value, _ := v.values[name].(int)
+	return value
+}
+
+// Float returns a decimal number argument, or 0 if it wasn't given
+func (v Values) Float(name string) float64 {
+	value, _ := v.values[name].(float64)
+	return value
+}
+
+// Duration returns a duration argument, or 0 if it wasn't given
+func (v Values) Duration(name string) time.Duration {
+	value, _ := v.values[name].(time.Duration)
+	return value
+}
+
+var loginRegex = regexp.MustCompile(`^[a-z0-9_]{1,25}$`)
+
+func convert(name string, t Type, raw string) (any, error) {
+	switch t {
+	case Int:
+		value, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%v must be a whole number", name)
+		}
+		return value, nil
+	case Float:
+		value, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%v must be a number", name)
+		}
+		return value, nil
+	case Duration:
+		if seconds, err := strconv.Atoi(raw); err == nil {
+			return time.Duration(seconds) * time.Second, nil
+		}
+		value, err := time.ParseDuration(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%v must be a duration like 30s or 5m", name)
+		}
+		return value, nil
+	case User:
+		login := strings.ToLower(strings.TrimPrefix(raw, "@"))
+		if !loginRegex.MatchString(login) {
+			return nil, fmt.Errorf("%v must be a Twitch username", name)
+		}
+		return login, nil
+	case Channel:
+		login := strings.ToLower(strings.TrimLeft(raw, "@#"))
+		if !loginRegex.MatchString(login) {
+			return nil, fmt.Errorf("%v must be a Twitch channel", name)
+		}
+		return login, nil
+	default:
+		return raw, nil
+	}
+}
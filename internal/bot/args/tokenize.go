@@ -0,0 +1,76 @@
+package args
+
+import (
+	"strings"
+	"unicode"
+)
+
+type token struct {
+	value string
+	// start is the offset of the token in the input, used to take the raw remainder for rest arguments
+	start int
+	// quoted tokens are never treated as options
+	quoted bool
+}
+
+// Tokenize splits input on whitespace, text in double quotes is kept together and \" escapes a quote
+func Tokenize(input string) []string {
+	var values []string
+	for _, t := range tokenize(input) {
+		values = append(values, t.value)
+	}
+	return values
+}
+
+func tokenize(input string) []token {
+	var tokens []token
+	var current strings.Builder
+
+	inToken := false
+	inQuotes := false
+	escaped := false
+	t := token{}
+
+	for i, r := range input {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\' && i+1 < len(input) && (input[i+1] == '"' || input[i+1] == '\\'):
+			if !inToken {
+				inToken = true
+				t.start = i
+			}
+			escaped = true
+		case r == '"':
+			if !inToken {
+				inToken = true
+				t.start = i
+			}
+			inQuotes = !inQuotes
+			t.quoted = true
+		case !inQuotes && unicode.IsSpace(r):
+			if inToken {
+				t.value = current.String()
+				tokens = append(tokens, t)
+				current.Reset()
+				t = token{}
+				inToken = false
+			}
+		default:
+			if !inToken {
+				inToken = true
+				t.start = i
+			}
+			current.WriteRune(r)
+		}
+	}
+
+	// An unterminated quote runs until the end of the input
+	if inToken {
+		t.value = current.String()
+		tokens = append(tokens, t)
+	}
+
+	return tokens
+}
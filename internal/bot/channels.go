@@ -0,0 +1,35 @@
+package bot
+
+import (
+	"log/slog"
+	"slices"
+
+	"github.com/esfands/retpaladinbot/internal/global"
+	"github.com/esfands/retpaladinbot/internal/services/events"
+)
+
+// reloadChannels reloads the channel settings whenever they're changed through the API, it joins the channels which
+// were added or enabled and leaves the ones which were disabled
+func (conn *Connection) reloadChannels(gctx global.Context) {
+	events.Subscribe(gctx.Crate().Events, events.ChannelChanged, "channels", func(event events.ChannelChangedEvent) {
+		joined := conn.ChannelManager.Names()
+		if err := conn.ChannelManager.Load(); err != nil {
+			slog.Error("Failed to reload channels", "channel", event.ChannelID, "error", err.Error())
+			return
+		}
+		names := conn.ChannelManager.Names()
+
+		for _, name := range names {
+			if !slices.Contains(joined, name) {
+				slog.Info("Joining channel", "channel", name)
+				conn.client.Join(name)
+			}
+		}
+		for _, name := range joined {
+			if !slices.Contains(names, name) {
+				slog.Info("Leaving channel", "channel", name)
+				conn.client.Depart(name)
+			}
+		}
+	})
+}
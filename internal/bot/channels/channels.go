@@ -0,0 +1,121 @@
+package channels
+
+import (
+	"log/slog"
+	"strings"
+	"sync"
+
+	"github.com/esfands/retpaladinbot/internal/db"
+	"github.com/esfands/retpaladinbot/internal/global"
+	"github.com/esfands/retpaladinbot/pkg/domain"
+)
+
+// ChannelManager keeps the settings of every channel the bot joins
+type ChannelManager struct {
+	gctx global.Context
+
+	mu       sync.RWMutex
+	channels map[string]domain.Channel
+}
+
+func NewChannelManager(gctx global.Context) (*ChannelManager, error) {
+	cm := &ChannelManager{
+		gctx:     gctx,
+		channels: make(map[string]domain.Channel),
+	}
+
+	// Make sure the channel from the config is always stored in the database
+	err := gctx.Crate().Turso.Queries().InsertChannel(gctx, db.Channel{
+		ID:              gctx.Config().Twitch.Bot.ChannelID,
+		Name:            strings.ToLower(gctx.Config().Twitch.Bot.Channel),
+		Prefix:          gctx.Config().Twitch.Bot.Prefix,
+		Enabled:         1,
+		ThreadedReplies: 1,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cm.Load(); err != nil {
+		return nil, err
+	}
+
+	return cm, nil
+}
+
+// Load (re)loads every channel from the database
+func (cm *ChannelManager) Load() error {
+	storedChannels, err := cm.gctx.Crate().Turso.Queries().GetAllChannels(cm.gctx)
+	if err != nil {
+		return err
+	}
+
+	channels := make(map[string]domain.Channel, len(storedChannels))
+	for _, storedChannel := range storedChannels {
+		channel := domain.Channel{
+			ID:      storedChannel.ID,
+			Name:    strings.ToLower(storedChannel.Name),
+			Prefix:  storedChannel.Prefix,
+			Enabled: storedChannel.Enabled == 1,
+
+			ThreadedReplies: storedChannel.ThreadedReplies == 1,
+			CooldownNotice:  storedChannel.CooldownNotice == 1,
+			CooldownBuckets: storedChannel.CooldownBuckets == 1,
+		}
+
+		channels[channel.Name] = channel
+	}
+
+	cm.mu.Lock()
+	cm.channels = channels
+	cm.mu.Unlock()
+
+	slog.Info("Channels loaded", "count", len(channels))
+
+	return nil
+}
+
+// Get returns the settings of a channel by its login name
+func (cm *ChannelManager) Get(name string) (domain.Channel, bool) {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	channel, ok := cm.channels[strings.ToLower(name)]
+	return channel, ok
+}
+
+// GetByID returns the settings of a channel by its Twitch ID
+func (cm *ChannelManager) GetByID(id string) (domain.Channel, bool) {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	for _, channel := range cm.channels {
+		if channel.ID == id {
+			return channel, true
+		}
+	}
+	return domain.Channel{}, false
+}
+
+// All returns every enabled channel
+func (cm *ChannelManager) All() []domain.Channel {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	var channels []domain.Channel
+	for _, channel := range cm.channels {
+		if channel.Enabled {
+			channels = append(channels, channel)
+		}
+	}
+	return channels
+}
+
+// Names returns the login names of every enabled channel
+func (cm *ChannelManager) Names() []string {
+	var names []string
+	for _, channel := range cm.All() {
+		names = append(names, channel.Name)
+	}
+	return names
+}
@@ -63,7 +63,7 @@ func (c *Command) GlobalCooldown() int {
 	return 10
 }
 
-func (c *Command) Code(user twitch.User, context []string) (string, error) {
+func (c *Command) Code(channel domain.Channel, user twitch.User, context []string) (string, error) {
 	target := utils.GetTarget(user, context)
 
 	res, err := c.gctx.Crate().Helix.Client().GetUsers(&helix.UsersParams{
@@ -76,11 +76,11 @@ func (c *Command) Code(user twitch.User, context []string) (string, error) {
 	// Check if the response responded with an unauthorized error or some other error
 	if res.Error != "" {
 		slog.Error("Twitch API error while fetching account age", "error", res.ErrorMessage)
-		return fmt.Sprintf("@%v, sorry, the Twitch API threw an error... Susge", user.Name), nil
+		return "Sorry, the Twitch API threw an error... Susge", nil
 	}
 
 	if len(res.Data.Users) == 0 {
-		return fmt.Sprintf("@%v, sorry I couldn't find a user with that name!", user.Name), nil
+		return "Sorry, I couldn't find a user with that name!", nil
 	}
 
 	slog.Debug("Target user test", "target", target)
@@ -91,5 +91,5 @@ func (c *Command) Code(user twitch.User, context []string) (string, error) {
 		return fmt.Sprintf("@%v created their account %v ago", target, elapsed), nil
 	}
 
-	return fmt.Sprintf("@%v, you created your account %v ago", target, elapsed), nil
+	return fmt.Sprintf("You created your account %v ago", elapsed), nil
 }
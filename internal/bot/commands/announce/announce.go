@@ -0,0 +1,144 @@
+package announce
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/esfands/retpaladinbot/internal/bot/args"
+	"github.com/esfands/retpaladinbot/internal/bot/invocation"
+	"github.com/esfands/retpaladinbot/internal/bot/variables"
+	"github.com/esfands/retpaladinbot/internal/global"
+	"github.com/esfands/retpaladinbot/pkg/domain"
+)
+
+type Command struct {
+	gctx      global.Context
+	variables variables.ServiceI
+}
+
+func NewAnnounceCommand(gctx global.Context, variables variables.ServiceI) *Command {
+	return &Command{
+		gctx:      gctx,
+		variables: variables,
+	}
+}
+
+func (c *Command) Name() string {
+	return "announce"
+}
+
+func (c *Command) Aliases() []string {
+	return []string{"announcement", "announcements"}
+}
+
+func (c *Command) Permissions() []domain.Permission {
+	return []domain.Permission{
+		domain.PermissionBroadcaster,
+		domain.PermissionModerator,
+	}
+}
+
+func (c *Command) Description() string {
+	return "Schedule messages which are sent on a cron schedule."
+}
+
+func (c *Command) DynamicDescription() []string {
+	return args.Help(c.Arguments(), c.gctx.Config().Twitch.Bot.Prefix+c.Name())
+}
+
+func (c *Command) Arguments() *args.Schema {
+	return &args.Schema{
+		Subcommands: []args.Subcommand{
+			{Name: "add", Schema: args.Schema{
+				Description: "Schedule an announcement, e.g. add \"0 12 * * *\" --tz America/Chicago --when offline Go live!",
+				Args: []args.Arg{
+					{Name: "cron", Description: "Cron expression in quotes: minute hour day month weekday"},
+					{Name: "message", Rest: true, Description: "The message, it can use variables"},
+				},
+				Options: []args.Option{
+					{Name: "tz", Description: "Time zone of the schedule, UTC by default"},
+					{Name: "when", Description: "Send it always, only when live or only when offline, always by default"},
+				},
+			}},
+			{Name: "list", Schema: args.Schema{Description: "List the announcements of the channel"}},
+			{Name: "remove", Schema: args.Schema{
+				Description: "Remove an announcement",
+				Args:        []args.Arg{{Name: "id", Type: args.Int, Description: "ID of the announcement"}},
+			}},
+		},
+	}
+}
+
+func (c *Command) Conditions() domain.DefaultCommandConditions {
+	return domain.DefaultCommandConditions{
+		EnabledOnline:  true,
+		EnabledOffline: true,
+	}
+}
+
+func (c *Command) UserCooldown() int {
+	return 5
+}
+
+func (c *Command) GlobalCooldown() int {
+	return 0
+}
+
+func (c *Command) Execute(inv *invocation.Invocation) error {
+	service := inv.Services().Announcements
+
+	switch inv.Values.Subcommand {
+	case "add":
+		message := inv.Values.String("message")
+		if _, err := c.variables.Parse(message); err != nil {
+			inv.Respond(fmt.Sprintf("Invalid message: %v", err))
+			return nil
+		}
+
+		announcement, err := service.Add(inv.Ctx, domain.Announcement{
+			ChannelID: inv.Channel.ID,
+			Cron:      inv.Values.String("cron"),
+			Timezone:  inv.Values.String("tz"),
+			Message:   message,
+			Condition: domain.StreamCondition(strings.ToLower(inv.Values.String("when"))),
+		})
+		if err != nil {
+			inv.Respond(fmt.Sprintf("Couldn't add the announcement: %v", err))
+			return nil
+		}
+
+		inv.Respond(fmt.Sprintf("Added announcement #%d, it's sent at %v (%v)", announcement.ID, announcement.Cron, announcement.Timezone))
+
+	case "list":
+		announcements, err := service.List(inv.Ctx, inv.Channel.ID)
+		if err != nil {
+			return err
+		}
+
+		if len(announcements) == 0 {
+			inv.Respond("There are no announcements")
+			return nil
+		}
+
+		var list []string
+		for _, a := range announcements {
+			list = append(list, fmt.Sprintf("#%d %v (%v, %v)", a.ID, a.Cron, a.Timezone, a.Condition))
+		}
+		inv.Respond("Announcements: " + strings.Join(list, " | "))
+
+	case "remove":
+		id := inv.Values.Int("id")
+		removed, err := service.Remove(inv.Ctx, inv.Channel.ID, id)
+		if err != nil {
+			return err
+		}
+
+		if !removed {
+			inv.Respond(fmt.Sprintf("There's no announcement #%d", id))
+			return nil
+		}
+		inv.Respond(fmt.Sprintf("Removed announcement #%d", id))
+	}
+
+	return nil
+}
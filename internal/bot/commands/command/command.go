@@ -5,21 +5,25 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/esfands/retpaladinbot/internal/bot/args"
+	"github.com/esfands/retpaladinbot/internal/bot/invocation"
+	"github.com/esfands/retpaladinbot/internal/bot/variables"
 	"github.com/esfands/retpaladinbot/internal/cmdmanager"
 	"github.com/esfands/retpaladinbot/internal/global"
 	"github.com/esfands/retpaladinbot/pkg/domain"
-	"github.com/gempir/go-twitch-irc/v4"
 )
 
 type Command struct {
-	gctx    global.Context
-	manager cmdmanager.CommandManagerInterface
+	gctx      global.Context
+	manager   cmdmanager.CommandManagerInterface
+	variables variables.ServiceI
 }
 
-func NewCommandCommand(gctx global.Context, manager cmdmanager.CommandManagerInterface) *Command {
+func NewCommandCommand(gctx global.Context, manager cmdmanager.CommandManagerInterface, variables variables.ServiceI) *Command {
 	return &Command{
-		gctx:    gctx,
-		manager: manager,
+		gctx:      gctx,
+		manager:   manager,
+		variables: variables,
 	}
 }
 
@@ -41,22 +45,54 @@ func (c *Command) Permissions() []domain.Permission {
 }
 
 func (c *Command) Description() string {
-	return "Create/edit/delete custom commands."
+	return "Create/edit/delete custom commands and change the settings of default commands."
 }
 
 func (c *Command) DynamicDescription() []string {
-	prefix := c.gctx.Config().Twitch.Bot.Prefix
+	return args.Help(c.Arguments(), c.gctx.Config().Twitch.Bot.Prefix+c.Name())
+}
 
-	return []string{
-		"Create a command with a name and message",
-		"<br/>",
-		fmt.Sprintf("<code>%vcommand create (name) (response)</code>", prefix),
-		"<br/>",
-		"Edit a command with a name and message",
-		fmt.Sprintf("<code>%vcommand edit (name) (response)</code>", prefix),
-		"<br/>",
-		"Delete a command with a name",
-		fmt.Sprintf("<code>%vcommand delete (name)</code>", prefix),
+func (c *Command) Arguments() *args.Schema {
+	name := args.Arg{Name: "name", Description: "Name of the custom command"}
+	response := args.Arg{Name: "response", Rest: true, Description: "What the bot responds with"}
+	defaultName := args.Arg{Name: "name", Description: "Name of the default command"}
+	setting := args.Arg{Name: "setting", Choices: overrideSettings}
+
+	return &args.Schema{
+		Subcommands: []args.Subcommand{
+			{Name: "create", Schema: args.Schema{
+				Description: "Create a command with a name and message",
+				Args:        []args.Arg{name, response},
+				Options:     customCommandOptions,
+			}},
+			{Name: "edit", Schema: args.Schema{
+				Description: "Edit the message or settings of a command, a new message replaces all of its responses",
+				Args:        []args.Arg{name, {Name: "response", Rest: true, Optional: true, Description: "What the bot responds with"}},
+				Options:     customCommandOptions,
+			}},
+			{Name: "delete", Schema: args.Schema{Description: "Delete a command with a name", Args: []args.Arg{name}}},
+			{Name: "addresponse", Schema: args.Schema{
+				Description: "Add another response to a command, one of them is picked every time it's used",
+				Args:        []args.Arg{name, response},
+				Options:     []args.Option{{Name: "weight", Type: args.Int, Description: "How likely the response is picked in the weighted mode, 1 by default"}},
+			}},
+			{Name: "delresponse", Schema: args.Schema{
+				Description: "Remove a response from a command by its number, starting at 1",
+				Args:        []args.Arg{name, {Name: "number", Type: args.Int, Description: "Number of the response"}},
+			}},
+			{Name: "set", Schema: args.Schema{
+				Description: "Change a setting of a default command in this channel",
+				Args: []args.Arg{defaultName, setting, {
+					Name:        "value",
+					Rest:        true,
+					Description: "Seconds for cooldowns, on/off for enabled/online/offline, a list for permissions and aliases",
+				}},
+			}},
+			{Name: "reset", Schema: args.Schema{
+				Description: "Reset a setting of a default command, or all of them when no setting is given",
+				Args:        []args.Arg{defaultName, {Name: "setting", Optional: true, Choices: overrideSettings}},
+			}},
+		},
 	}
 }
 
@@ -75,68 +111,108 @@ func (c *Command) GlobalCooldown() int {
 	return 10
 }
 
-func (c *Command) Code(user twitch.User, context []string) (string, error) {
-	if len(context) < 2 {
-		return "", errors.New("invalid command format")
-	}
-
-	action := context[0]
-	name := context[1]
+func (c *Command) Execute(inv *invocation.Invocation) error {
+	// Remove the channel prefix if it exists in the command name and lowercase it
+	name := strings.ToLower(strings.TrimPrefix(inv.Values.String("name"), inv.Channel.Prefix))
+	response := inv.Values.String("response")
 
-	// Remove the "!" prefix if it exists in the command name
-	name = strings.TrimPrefix(name, "!")
+	var res string
+	var err error
 
-	// Lowercase the command name
-	name = strings.ToLower(name)
-
-	response := strings.Join(context[2:], " ")
-
-	switch action {
+	switch inv.Values.Subcommand {
 	case "create":
-		return c.createCommand(name, response)
+		res, err = c.createCommand(inv.Channel, name, response, inv.Values)
 	case "edit":
-		return c.editCommand(name, response)
+		res, err = c.editCommand(inv.Channel, name, response, inv.Values)
 	case "delete":
-		return c.deleteCommand(name)
-	default:
-		return "", errors.New("invalid action specified")
+		res, err = c.deleteCommand(inv.Channel, name)
+	case "addresponse":
+		res, err = c.addResponse(inv.Channel, name, response, inv.Values)
+	case "delresponse":
+		res, err = c.deleteResponse(inv.Channel, name, inv.Values.Int("number"))
+	case "set":
+		res, err = c.setOverride(inv, name, inv.Values.String("setting"), inv.Values.String("value"))
+	case "reset":
+		res, err = c.resetOverride(inv, name, inv.Values.String("setting"))
+	}
+	if err != nil {
+		return err
 	}
+
+	inv.Respond(res)
+	return nil
 }
 
-func (c *Command) createCommand(name, response string) (string, error) {
+func (c *Command) createCommand(channel domain.Channel, name, response string, values args.Values) (string, error) {
 	// Check if the command already exists
-	if c.manager.CustomCommandExists(name) {
+	if c.manager.CustomCommandExists(channel.ID, name) {
 		return "", errors.New("command already exists")
 	}
 
+	if _, err := c.variables.Parse(response); err != nil {
+		return fmt.Sprintf("Invalid response: %v", err), nil
+	}
+
+	cmd := domain.NewCustomCommand(channel.ID, name, response)
+	if problem := applyCustomCommandOptions(&cmd, channel, values); problem != "" {
+		return problem, nil
+	}
+	if problem, err := c.nameConflict(cmd); err != nil || problem != "" {
+		return problem, err
+	}
+
 	// Add the new command to the manager's CustomCommands slice
-	err := c.manager.AddCustomCommand(domain.CustomCommand{
-		Name:     name,
-		Response: response,
-	})
+	err := c.manager.AddCustomCommand(cmd)
 	if err != nil {
 		return "", err
 	}
 
+	if err := saveCounterOptions(c.gctx, c.gctx.Crate().Turso.Queries(), cmd, values); err != nil {
+		return "", err
+	}
+
 	return fmt.Sprintf("Command '%s' created with response: %s", name, response), nil
 }
 
-func (c *Command) editCommand(name, response string) (string, error) {
-	// Update the command's response
-	err := c.manager.UpdateCustomCommand(domain.CustomCommand{
-		Name:     name,
-		Response: response,
-	})
+func (c *Command) editCommand(channel domain.Channel, name, response string, values args.Values) (string, error) {
+	cmd, ok := c.manager.GetCustomCommand(channel.ID, name)
+	if !ok {
+		return "", errors.New("command does not exist")
+	}
+
+	if response != "" {
+		if _, err := c.variables.Parse(response); err != nil {
+			return fmt.Sprintf("Invalid response: %v", err), nil
+		}
+		cmd.Responses = []domain.CustomCommandResponse{{Text: response, Weight: 1}}
+	}
+	if problem := applyCustomCommandOptions(&cmd, channel, values); problem != "" {
+		return problem, nil
+	}
+	if problem, err := c.nameConflict(cmd); err != nil || problem != "" {
+		return problem, err
+	}
+
+	// Update the command's response and settings
+	err := c.manager.UpdateCustomCommand(cmd)
 	if err != nil {
 		return "", err
 	}
 
+	if err := saveCounterOptions(c.gctx, c.gctx.Crate().Turso.Queries(), cmd, values); err != nil {
+		return "", err
+	}
+
+	if response == "" {
+		return fmt.Sprintf("Command '%s' updated", name), nil
+	}
+
 	return fmt.Sprintf("Command '%s' updated with new response: %s", name, response), nil
 }
 
-func (c *Command) deleteCommand(name string) (string, error) {
+func (c *Command) deleteCommand(channel domain.Channel, name string) (string, error) {
 	// Delete the command from the manager's CustomCommands slice
-	err := c.manager.DeleteCustomCommand(name)
+	err := c.manager.DeleteCustomCommand(channel.ID, name)
 	if err != nil {
 		return "", err
 	}
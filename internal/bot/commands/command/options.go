@@ -0,0 +1,124 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/esfands/retpaladinbot/internal/bot/args"
+	"github.com/esfands/retpaladinbot/internal/cmdmanager"
+	"github.com/esfands/retpaladinbot/internal/db"
+	"github.com/esfands/retpaladinbot/pkg/domain"
+	"github.com/esfands/retpaladinbot/pkg/utils"
+)
+
+// customCommandOptions are the settings which can be given when creating or editing a custom command
+var customCommandOptions = []args.Option{
+	{Name: "aliases", Description: "Comma separated list of other names for the command, none removes them"},
+	{Name: "permission", Description: "Who can use the command: everyone, vip, moderator or broadcaster"},
+	{Name: "cooldown", Type: args.Int, Description: "Global cooldown in seconds"},
+	{Name: "usercooldown", Type: args.Int, Description: "Cooldown per user in seconds"},
+	{Name: "online", Description: "Whether the command works while the stream is live, on or off"},
+	{Name: "offline", Description: "Whether the command works while the stream is offline, on or off"},
+	{Name: "enabled", Description: "Whether the command can be used at all, on or off"},
+	{Name: "reply", Description: "Whether the response is sent as a reply to the message which used the command, on or off"},
+	{Name: "mode", Description: "How one of the responses is picked: uniform, weighted or roundrobin"},
+	{Name: "norepeat", Type: args.Int, Description: "How many of the last picked responses aren't picked again"},
+	{Name: "counterreset", Description: "Whether the counter of the command goes back to 0 when the stream goes online, on or off"},
+}
+
+// applyCustomCommandOptions applies the given options to a custom command, it returns what's wrong with them if they're invalid
+func applyCustomCommandOptions(cmd *domain.CustomCommand, channel domain.Channel, values args.Values) string {
+	if values.Has("aliases") {
+		cmd.Aliases = []string{}
+		if value := values.String("aliases"); !strings.EqualFold(value, "none") {
+			for _, alias := range strings.Split(strings.ToLower(value), ",") {
+				if alias = strings.TrimPrefix(strings.TrimSpace(alias), channel.Prefix); alias != "" && alias != cmd.Name {
+					cmd.Aliases = append(cmd.Aliases, alias)
+				}
+			}
+		}
+	}
+
+	if values.Has("permission") {
+		permissions, ok := domain.PermissionsForLevel(strings.ToLower(values.String("permission")))
+		if !ok {
+			return "The permission must be everyone, vip, moderator or broadcaster"
+		}
+		cmd.Permissions = permissions
+	}
+
+	for _, option := range []struct {
+		name  string
+		value *int
+	}{
+		{"cooldown", &cmd.GlobalCooldown},
+		{"usercooldown", &cmd.UserCooldown},
+	} {
+		if values.Has(option.name) {
+			if values.Int(option.name) < 0 {
+				return "Cooldowns can't be negative"
+			}
+			*option.value = values.Int(option.name)
+		}
+	}
+
+	for _, option := range []struct {
+		name  string
+		value *bool
+	}{
+		{"online", &cmd.EnabledOnline},
+		{"offline", &cmd.EnabledOffline},
+		{"enabled", &cmd.Enabled},
+		{"reply", &cmd.ThreadedReply},
+	} {
+		if values.Has(option.name) {
+			toggle, ok := parseToggle(values.String(option.name))
+			if !ok {
+				return fmt.Sprintf("The value for %s must be on or off", option.name)
+			}
+			*option.value = toggle
+		}
+	}
+
+	if values.Has("mode") {
+		mode := domain.ResponseMode(strings.ToLower(values.String("mode")))
+		if !mode.Valid() {
+			return "The mode must be uniform, weighted or roundrobin"
+		}
+		cmd.ResponseMode = mode
+	}
+
+	if values.Has("norepeat") {
+		if values.Int("norepeat") < 0 {
+			return "The number of responses not to repeat can't be negative"
+		}
+		cmd.AvoidRepeats = values.Int("norepeat")
+	}
+
+	if values.Has("counterreset") {
+		if _, ok := parseToggle(values.String("counterreset")); !ok {
+			return "The value for counterreset must be on or off"
+		}
+	}
+
+	return ""
+}
+
+// nameConflict returns which other command of the channel already uses the name or one of the aliases of a custom
+// command, it's empty when they're all free
+func (c *Command) nameConflict(cmd domain.CustomCommand) (string, error) {
+	names := append([]string{cmd.Name}, cmd.Aliases...)
+	return cmdmanager.NameConflict(c.gctx, c.gctx.Crate().Turso.Queries(), cmd.ChannelID, names, cmd.Name, true)
+}
+
+// saveCounterOptions stores the counter settings of a custom command, the options must have been validated by
+// applyCustomCommandOptions
+func saveCounterOptions(ctx context.Context, queries *db.Queries, cmd domain.CustomCommand, values args.Values) error {
+	if !values.Has("counterreset") {
+		return nil
+	}
+
+	reset, _ := parseToggle(values.String("counterreset"))
+	return queries.UpdateCounterResetOnStream(ctx, cmd.ChannelID, cmd.Name, utils.Ternary(reset, 1, 0))
+}
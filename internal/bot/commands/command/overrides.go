@@ -0,0 +1,166 @@
+package command
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/esfands/retpaladinbot/internal/bot/invocation"
+	"github.com/esfands/retpaladinbot/internal/cmdmanager"
+	"github.com/esfands/retpaladinbot/pkg/domain"
+)
+
+// overrideSettings are the settings of a default command which can be changed per channel
+var overrideSettings = []string{"cooldown", "usercooldown", "permissions", "enabled", "online", "offline", "reply", "aliases"}
+
+func (c *Command) setOverride(inv *invocation.Invocation, name, setting, value string) (string, error) {
+	dc, ok := c.manager.GetDefaultCommand(name)
+	if !ok {
+		return fmt.Sprintf("Default command '%s' doesn't exist", name), nil
+	}
+
+	queries := c.gctx.Crate().Turso.Queries()
+
+	override, err := cmdmanager.GetOverride(inv.Ctx, queries, inv.Channel.ID, dc.Name())
+	if err != nil {
+		return "", err
+	}
+
+	switch setting {
+	case "cooldown", "usercooldown":
+		seconds, err := strconv.Atoi(value)
+		if err != nil || seconds < 0 {
+			return "The cooldown must be a number of seconds", nil
+		}
+
+		if setting == "cooldown" {
+			override.GlobalCooldown = &seconds
+		} else {
+			override.UserCooldown = &seconds
+		}
+
+	case "permissions":
+		permissions, ok := parsePermissions(value)
+		if !ok {
+			return "Permissions must be everyone or a list of broadcaster, moderator and vip", nil
+		}
+		override.Permissions = &permissions
+
+	case "enabled", "online", "offline", "reply":
+		toggle, ok := parseToggle(value)
+		if !ok {
+			return fmt.Sprintf("The value for %s must be on or off", setting), nil
+		}
+
+		switch setting {
+		case "enabled":
+			// Disabling this command would make it impossible to turn it back on from chat
+			if !toggle && dc.Name() == c.Name() {
+				return fmt.Sprintf("The '%s' command can't be disabled", c.Name()), nil
+			}
+			override.Enabled = &toggle
+		case "online":
+			override.EnabledOnline = &toggle
+		case "offline":
+			override.EnabledOffline = &toggle
+		case "reply":
+			override.ThreadedReply = &toggle
+		}
+
+	case "aliases":
+		override.Aliases = nil
+		if !strings.EqualFold(value, "none") {
+			for _, alias := range strings.Fields(strings.ToLower(value)) {
+				override.Aliases = append(override.Aliases, strings.TrimPrefix(alias, inv.Channel.Prefix))
+			}
+		}
+
+		problem, err := cmdmanager.NameConflict(inv.Ctx, queries, inv.Channel.ID, override.Aliases, dc.Name(), false)
+		if err != nil || problem != "" {
+			return problem, err
+		}
+	}
+
+	if err := cmdmanager.SaveOverride(inv.Ctx, queries, override); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("Set %s of '%s' to %s", setting, dc.Name(), value), nil
+}
+
+func (c *Command) resetOverride(inv *invocation.Invocation, name, setting string) (string, error) {
+	dc, ok := c.manager.GetDefaultCommand(name)
+	if !ok {
+		return fmt.Sprintf("Default command '%s' doesn't exist", name), nil
+	}
+
+	queries := c.gctx.Crate().Turso.Queries()
+
+	if setting == "" {
+		if err := queries.DeleteChannelCommand(inv.Ctx, inv.Channel.ID, dc.Name()); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Reset every setting of '%s'", dc.Name()), nil
+	}
+
+	override, err := cmdmanager.GetOverride(inv.Ctx, queries, inv.Channel.ID, dc.Name())
+	if err != nil {
+		return "", err
+	}
+
+	switch setting {
+	case "cooldown":
+		override.GlobalCooldown = nil
+	case "usercooldown":
+		override.UserCooldown = nil
+	case "permissions":
+		override.Permissions = nil
+	case "enabled":
+		override.Enabled = nil
+	case "online":
+		override.EnabledOnline = nil
+	case "offline":
+		override.EnabledOffline = nil
+	case "reply":
+		override.ThreadedReply = nil
+	case "aliases":
+		override.Aliases = nil
+	}
+
+	if err := cmdmanager.SaveOverride(inv.Ctx, queries, override); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("Reset %s of '%s'", setting, dc.Name()), nil
+}
+
+// parsePermissions parses a comma or space separated list of permissions, everyone means no permissions are required
+func parsePermissions(value string) ([]domain.Permission, bool) {
+	permissions := []domain.Permission{}
+	if strings.EqualFold(value, "everyone") {
+		return permissions, true
+	}
+
+	for _, field := range strings.FieldsFunc(strings.ToLower(value), func(r rune) bool { return r == ',' || r == ' ' }) {
+		permission := domain.Permission(field)
+		if !slices.Contains(domain.AssignablePermissions, permission) {
+			return nil, false
+		}
+
+		permissions = append(permissions, permission)
+	}
+
+	return permissions, len(permissions) > 0
+}
+
+func parseToggle(value string) (bool, bool) {
+	switch strings.ToLower(value) {
+	case "on", "true", "yes", "enabled":
+		return true, true
+	case "off", "false", "no", "disabled":
+		return false, true
+	default:
+		return false, false
+	}
+}
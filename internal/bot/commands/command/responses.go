@@ -0,0 +1,60 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/esfands/retpaladinbot/internal/bot/args"
+	"github.com/esfands/retpaladinbot/pkg/domain"
+)
+
+func (c *Command) addResponse(channel domain.Channel, name, response string, values args.Values) (string, error) {
+	cmd, ok := c.manager.GetCustomCommand(channel.ID, name)
+	if !ok {
+		return "", errors.New("command does not exist")
+	}
+
+	if _, err := c.variables.Parse(response); err != nil {
+		return fmt.Sprintf("Invalid response: %v", err), nil
+	}
+
+	weight := 1
+	if values.Has("weight") {
+		if weight = values.Int("weight"); weight < 1 {
+			return "The weight must be at least 1", nil
+		}
+	}
+
+	cmd.Responses = append(cmd.Responses, domain.CustomCommandResponse{Text: response, Weight: weight})
+
+	err := c.manager.UpdateCustomCommand(cmd)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("Added response #%d to command '%s'", len(cmd.Responses), name), nil
+}
+
+func (c *Command) deleteResponse(channel domain.Channel, name string, number int) (string, error) {
+	cmd, ok := c.manager.GetCustomCommand(channel.ID, name)
+	if !ok {
+		return "", errors.New("command does not exist")
+	}
+
+	if number < 1 || number > len(cmd.Responses) {
+		return fmt.Sprintf("Command '%s' has %d responses", name, len(cmd.Responses)), nil
+	}
+
+	if len(cmd.Responses) == 1 {
+		return "A command needs at least one response, delete the command instead", nil
+	}
+
+	cmd.Responses = append(cmd.Responses[:number-1:number-1], cmd.Responses[number:]...)
+
+	err := c.manager.UpdateCustomCommand(cmd)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("Removed response #%d from command '%s', %d left", number, name, len(cmd.Responses)), nil
+}
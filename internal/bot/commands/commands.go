@@ -4,21 +4,28 @@ import (
 	"context"
 	"errors"
 	"log/slog"
+	"slices"
 
 	"github.com/esfands/retpaladinbot/internal/bot/commands/accountage"
+	"github.com/esfands/retpaladinbot/internal/bot/commands/announce"
 	"github.com/esfands/retpaladinbot/internal/bot/commands/command"
+	"github.com/esfands/retpaladinbot/internal/bot/commands/cooldown"
 	"github.com/esfands/retpaladinbot/internal/bot/commands/dadjoke"
 	"github.com/esfands/retpaladinbot/internal/bot/commands/game"
 	"github.com/esfands/retpaladinbot/internal/bot/commands/gdq"
 	"github.com/esfands/retpaladinbot/internal/bot/commands/help"
 	"github.com/esfands/retpaladinbot/internal/bot/commands/isbanned"
+	"github.com/esfands/retpaladinbot/internal/bot/commands/module"
+	"github.com/esfands/retpaladinbot/internal/bot/commands/notice"
 	"github.com/esfands/retpaladinbot/internal/bot/commands/ping"
 	"github.com/esfands/retpaladinbot/internal/bot/commands/song"
 	"github.com/esfands/retpaladinbot/internal/bot/commands/subage"
 	"github.com/esfands/retpaladinbot/internal/bot/commands/temperature"
 	"github.com/esfands/retpaladinbot/internal/bot/commands/time"
+	"github.com/esfands/retpaladinbot/internal/bot/commands/timer"
 	"github.com/esfands/retpaladinbot/internal/bot/commands/title"
 	"github.com/esfands/retpaladinbot/internal/bot/commands/uptime"
+	"github.com/esfands/retpaladinbot/internal/bot/variables"
 	"github.com/esfands/retpaladinbot/internal/cmdmanager"
 	"github.com/esfands/retpaladinbot/internal/db"
 	"github.com/esfands/retpaladinbot/internal/global"
@@ -27,17 +34,20 @@ import (
 )
 
 type CommandManager struct {
-	gctx    global.Context
-	version string
+	gctx      global.Context
+	version   string
+	variables variables.ServiceI
 
-	DefaultCommands []domain.DefaultCommand
+	// DefaultCommands holds both domain.DefaultCommand and invocation.Command implementations
+	DefaultCommands []domain.DefaultCommandInfo
 	CustomCommands  []domain.CustomCommand
 }
 
-func NewCommandManager(gctx global.Context, version string) *CommandManager {
+func NewCommandManager(gctx global.Context, version string, variables variables.ServiceI) *CommandManager {
 	cm := &CommandManager{
-		gctx:    gctx,
-		version: version,
+		gctx:      gctx,
+		version:   version,
+		variables: variables,
 	}
 
 	// Load the default commands locally and then save them to database
@@ -53,8 +63,8 @@ func NewCommandManager(gctx global.Context, version string) *CommandManager {
 	return cm
 }
 
-func (cm *CommandManager) loadDefaultCommands() []domain.DefaultCommand {
-	return []domain.DefaultCommand{
+func (cm *CommandManager) loadDefaultCommands() []domain.DefaultCommandInfo {
+	return []domain.DefaultCommandInfo{
 		ping.NewPingCommand(cm.gctx),
 		accountage.NewAccountAgeCommand(cm.gctx),
 		song.NewSongCommand(cm.gctx),
@@ -64,7 +74,12 @@ func (cm *CommandManager) loadDefaultCommands() []domain.DefaultCommand {
 		uptime.NewUptimeCommand(cm.gctx),
 		dadjoke.NewDadJokeCommand(cm.gctx),
 		help.NewHelpCommand(cm.gctx, cm.version),
-		command.NewCommandCommand(cm.gctx, cm),
+		command.NewCommandCommand(cm.gctx, cm, cm.variables),
+		cooldown.NewCooldownCommand(cm.gctx, cm),
+		module.NewModuleCommand(cm.gctx),
+		announce.NewAnnounceCommand(cm.gctx, cm.variables),
+		timer.NewTimerCommand(cm.gctx, cm.variables),
+		notice.NewNoticeCommand(cm.gctx, cm.variables),
 		gdq.NewGDQCommand(cm.gctx),
 		subage.NewSubageCommand(cm.gctx),
 		temperature.NewTemperatureCommand(cm.gctx),
@@ -125,74 +140,127 @@ func (cm *CommandManager) saveDefaultCommands() {
 	}
 }
 
+// GetDefaultCommand returns the default command with the given name or alias
+func (cm *CommandManager) GetDefaultCommand(name string) (domain.DefaultCommandInfo, bool) {
+	for _, dc := range cm.DefaultCommands {
+		if dc.Name() == name || slices.Contains(dc.Aliases(), name) {
+			return dc, true
+		}
+	}
+	return nil, false
+}
+
 func (cm *CommandManager) loadCustomCommands() error {
 	commands, err := cm.gctx.Crate().Turso.Queries().GetAllCustomCommands(cm.gctx)
 	if err != nil {
 		return err
 	}
 
-	for _, customCommand := range commands {
-		cm.CustomCommands = append(cm.CustomCommands, domain.CustomCommand{
-			Name:     customCommand.Name,
-			Response: customCommand.Response,
-		})
+	for _, storedCommand := range commands {
+		customCommand, err := cmdmanager.ToCustomCommand(storedCommand)
+		if err != nil {
+			slog.Error("Failed to convert custom command", "command", storedCommand.Name, "error", err)
+			continue
+		}
+		cm.CustomCommands = append(cm.CustomCommands, customCommand)
 	}
 
 	return nil
 }
 
 func (cm *CommandManager) AddCustomCommand(cmd domain.CustomCommand) error {
-	if cm.CustomCommandExists(cmd.Name) {
+	if cm.CustomCommandExists(cmd.ChannelID, cmd.Name) {
 		return errors.New("command already exists")
 	}
-	cm.CustomCommands = append(cm.CustomCommands, cmd)
+
+	storedCommand, err := cmdmanager.FromCustomCommand(cmd)
+	if err != nil {
+		return err
+	}
+
 	// Insert into database
-	err := cm.gctx.Crate().Turso.Queries().InsertCustomCommand(context.Background(), db.CustomCommand{
-		Name:       cmd.Name,
-		Response:   cmd.Response,
-		UsageCount: 0,
-	})
-	return err
+	err = cm.gctx.Crate().Turso.Queries().InsertCustomCommand(context.Background(), storedCommand)
+	if err != nil {
+		return err
+	}
+
+	cm.CustomCommands = append(cm.CustomCommands, cmd)
+	return nil
 }
 
+// UpdateCustomCommand replaces the response and settings of a custom command
 func (cm *CommandManager) UpdateCustomCommand(cmd domain.CustomCommand) error {
 	for i, existingCmd := range cm.CustomCommands {
-		if existingCmd.Name == cmd.Name {
-			cm.CustomCommands[i].Response = cmd.Response
+		if existingCmd.ChannelID == cmd.ChannelID && existingCmd.Name == cmd.Name {
+			storedCommand, err := cmdmanager.FromCustomCommand(cmd)
+			if err != nil {
+				return err
+			}
+
 			// Update in database
-			err := cm.gctx.Crate().Turso.Queries().UpdateCustomCommand(context.Background(), db.CustomCommand{
-				Name:     cmd.Name,
-				Response: cmd.Response,
-			})
-			return err
+			err = cm.gctx.Crate().Turso.Queries().UpdateCustomCommand(context.Background(), storedCommand)
+			if err != nil {
+				return err
+			}
+
+			cmd.UsageCount = existingCmd.UsageCount
+			cm.CustomCommands[i] = cmd
+			return nil
 		}
 	}
 	return errors.New("command does not exist")
 }
 
-func (cm *CommandManager) DeleteCustomCommand(name string) error {
+func (cm *CommandManager) DeleteCustomCommand(channelID, name string) error {
 	for i, cmd := range cm.CustomCommands {
-		if cmd.Name == name {
+		if cmd.ChannelID == channelID && cmd.Name == name {
 			cm.CustomCommands = append(cm.CustomCommands[:i], cm.CustomCommands[i+1:]...)
-			// Delete from database
-			err := cm.gctx.Crate().Turso.Queries().DeleteCustomCommand(context.Background(), name)
-			return err
+			// Delete from database along with the counter the command owns
+			err := cm.gctx.Crate().Turso.Queries().DeleteCustomCommand(context.Background(), channelID, name)
+			if err != nil {
+				return err
+			}
+			return cm.gctx.Crate().Turso.Queries().DeleteCounter(context.Background(), channelID, name)
 		}
 	}
 	return errors.New("command does not exist")
 }
 
-func (cm *CommandManager) CustomCommandExists(name string) bool {
+// CustomCommandExists checks if a name is used by a custom command of the channel, either as name or alias
+func (cm *CommandManager) CustomCommandExists(channelID, name string) bool {
+	_, ok := cm.FindCustomCommand(channelID, name)
+	return ok
+}
+
+// GetCustomCommand returns the custom command of a channel with exactly the given name
+func (cm *CommandManager) GetCustomCommand(channelID, name string) (domain.CustomCommand, bool) {
 	for _, cmd := range cm.CustomCommands {
-		if cmd.Name == name {
-			return true
+		if cmd.ChannelID == channelID && cmd.Name == name {
+			return cmd, true
 		}
 	}
-	return false
+	return domain.CustomCommand{}, false
 }
 
-func (cm *CommandManager) GetCustomCommands() []domain.CustomCommand {
-	return cm.CustomCommands
+// FindCustomCommand returns the custom command of a channel a trigger refers to, by its name or one of its aliases
+func (cm *CommandManager) FindCustomCommand(channelID, trigger string) (domain.CustomCommand, bool) {
+	for _, cmd := range cm.CustomCommands {
+		if cmd.ChannelID == channelID && (cmd.Name == trigger || slices.Contains(cmd.Aliases, trigger)) {
+			return cmd, true
+		}
+	}
+	return domain.CustomCommand{}, false
+}
+
+// GetCustomCommands returns the custom commands of a channel
+func (cm *CommandManager) GetCustomCommands(channelID string) []domain.CustomCommand {
+	var commands []domain.CustomCommand
+	for _, cmd := range cm.CustomCommands {
+		if cmd.ChannelID == channelID {
+			commands = append(commands, cmd)
+		}
+	}
+	return commands
 }
 
 // Ensure CommandManager implements CommandManagerInterface
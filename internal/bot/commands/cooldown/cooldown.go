@@ -0,0 +1,151 @@
+package cooldown
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/esfands/retpaladinbot/internal/bot/args"
+	"github.com/esfands/retpaladinbot/internal/bot/invocation"
+	"github.com/esfands/retpaladinbot/internal/cmdmanager"
+	"github.com/esfands/retpaladinbot/internal/global"
+	"github.com/esfands/retpaladinbot/internal/services/cooldowns"
+	"github.com/esfands/retpaladinbot/pkg/domain"
+)
+
+type Command struct {
+	gctx    global.Context
+	manager cmdmanager.CommandManagerInterface
+}
+
+func NewCooldownCommand(gctx global.Context, manager cmdmanager.CommandManagerInterface) *Command {
+	return &Command{
+		gctx:    gctx,
+		manager: manager,
+	}
+}
+
+func (c *Command) Name() string {
+	return "cooldown"
+}
+
+func (c *Command) Aliases() []string {
+	return []string{"cd"}
+}
+
+func (c *Command) Permissions() []domain.Permission {
+	return []domain.Permission{
+		domain.PermissionBroadcaster,
+		domain.PermissionModerator,
+	}
+}
+
+func (c *Command) Description() string {
+	return "Check and reset the cooldowns of commands."
+}
+
+func (c *Command) DynamicDescription() []string {
+	return args.Help(c.Arguments(), c.gctx.Config().Twitch.Bot.Prefix+c.Name())
+}
+
+func (c *Command) Arguments() *args.Schema {
+	command := args.Arg{Name: "command", Description: "Name of the command"}
+
+	return &args.Schema{
+		Subcommands: []args.Subcommand{
+			{Name: "check", Schema: args.Schema{
+				Description: "Check how long a command is still on cooldown, for everyone or for a user",
+				Args:        []args.Arg{command, {Name: "user", Type: args.User, Optional: true}},
+			}},
+			{Name: "reset", Schema: args.Schema{
+				Subcommands: []args.Subcommand{
+					{Name: "user", Schema: args.Schema{
+						Description: "Reset the cooldowns of a user, for every command or a single one",
+						Args:        []args.Arg{{Name: "user", Type: args.User}, {Name: "command", Optional: true}},
+					}},
+					{Name: "command", Schema: args.Schema{
+						Description: "Reset every cooldown of a command",
+						Args:        []args.Arg{command},
+					}},
+				},
+			}},
+		},
+	}
+}
+
+func (c *Command) Conditions() domain.DefaultCommandConditions {
+	return domain.DefaultCommandConditions{
+		EnabledOnline:  true,
+		EnabledOffline: true,
+	}
+}
+
+func (c *Command) UserCooldown() int {
+	return 5
+}
+
+func (c *Command) GlobalCooldown() int {
+	return 0
+}
+
+func (c *Command) Execute(inv *invocation.Invocation) error {
+	store := inv.Services().Cooldowns
+	command := c.commandName(inv.Values.String("command"))
+	user := inv.Values.String("user")
+
+	switch inv.Values.Subcommand {
+	case "check":
+		globalRemaining, err := store.Remaining(inv.Ctx, cooldowns.Key{ChannelID: inv.Channel.ID, Command: command})
+		if err != nil {
+			return err
+		}
+
+		res := fmt.Sprintf("Global cooldown of %v: %v", command, formatRemaining(globalRemaining))
+
+		if user != "" {
+			remaining, err := store.Remaining(inv.Ctx, cooldowns.Key{ChannelID: inv.Channel.ID, Command: command, User: user})
+			if err != nil {
+				return err
+			}
+			res += fmt.Sprintf(", cooldown of %v: %v", user, formatRemaining(remaining))
+		}
+
+		inv.Respond(res)
+
+	case "reset user":
+		count, err := store.Reset(inv.Ctx, cooldowns.Filter{ChannelID: inv.Channel.ID, Command: command, User: user})
+		if err != nil {
+			return err
+		}
+
+		if command != "" {
+			inv.Respond(fmt.Sprintf("Reset %d cooldowns of %v for %v", count, user, command))
+		} else {
+			inv.Respond(fmt.Sprintf("Reset %d cooldowns of %v", count, user))
+		}
+
+	case "reset command":
+		count, err := store.Reset(inv.Ctx, cooldowns.Filter{ChannelID: inv.Channel.ID, Command: command})
+		if err != nil {
+			return err
+		}
+
+		inv.Respond(fmt.Sprintf("Reset %d cooldowns of %v", count, command))
+	}
+
+	return nil
+}
+
+// commandName resolves aliases of default commands, cooldowns are stored by the name of the command
+func (c *Command) commandName(name string) string {
+	if dc, ok := c.manager.GetDefaultCommand(name); ok {
+		return dc.Name()
+	}
+	return name
+}
+
+func formatRemaining(remaining time.Duration) string {
+	if remaining <= 0 {
+		return "not running"
+	}
+	return fmt.Sprintf("%v left", remaining.Round(time.Second))
+}
@@ -8,10 +8,10 @@ import (
 	"net/http"
 
 	"github.com/dghubble/sling"
+	"github.com/esfands/retpaladinbot/internal/bot/invocation"
 	"github.com/esfands/retpaladinbot/internal/global"
 	"github.com/esfands/retpaladinbot/pkg/domain"
 	"github.com/esfands/retpaladinbot/pkg/utils"
-	"github.com/gempir/go-twitch-irc/v4"
 )
 
 type Command struct {
@@ -65,8 +65,8 @@ func (c *Command) GlobalCooldown() int {
 	return 10
 }
 
-func (c *Command) Code(user twitch.User, context []string) (string, error) {
-	target := utils.GetTarget(user, context)
+func (c *Command) Execute(inv *invocation.Invocation) error {
+	target := utils.GetTarget(inv.User, inv.Args)
 
 	url := "https://icanhazdadjoke.com/"
 
@@ -74,27 +74,28 @@ func (c *Command) Code(user twitch.User, context []string) (string, error) {
 	req, err := s.New().Get("/").Request()
 	if err != nil {
 		slog.Error("Failed to create dad joke request", "error", err)
-		return fmt.Sprintf("@%v failed to get a dad joke. FeelsBadMan", user.Name), err
+		return err
 	}
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := http.DefaultClient.Do(req.WithContext(inv.Ctx))
 	if err != nil {
 		slog.Error("Failed to get dad joke", "error", err)
-		return fmt.Sprintf("@%v failed to get a dad joke. FeelsBadMan", user.Name), err
+		return err
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		slog.Error("Failed to read dad joke response body", "error", err)
-		return fmt.Sprintf("@%v failed to get a dad joke. FeelsBadMan", user.Name), err
+		return err
 	}
 
 	var joke Response
 	if err := json.Unmarshal(body, &joke); err != nil {
 		slog.Error("Failed to unmarshal dad joke response", "error", err)
-		return fmt.Sprintf("@%v failed to get a dad joke. FeelsBadMan", user.Name), err
+		return err
 	}
 
-	return fmt.Sprintf("@%v %v", target, joke.Joke), nil
+	inv.Respond(utils.MentionTarget(inv.User, target, joke.Joke))
+	return nil
 }
@@ -1,6 +1,7 @@
 package game
 
 import (
+	"database/sql"
 	"errors"
 	"fmt"
 	"strings"
@@ -64,21 +65,22 @@ func (c *Command) GlobalCooldown() int {
 	return 10
 }
 
-func (c *Command) Code(user twitch.User, context []string) (string, error) {
+func (c *Command) Code(channel domain.Channel, user twitch.User, context []string) (string, error) {
 	target := utils.GetTarget(user, context)
 
-	stream, err := c.gctx.Crate().Turso.Queries().GetMostRecentStreamStatus(c.gctx)
-	if err != nil {
+	stream, err := c.gctx.Crate().Turso.Queries().GetMostRecentStreamStatus(c.gctx, channel.ID)
+	// Channels which haven't streamed since they were added have no stream status yet
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
 		return "", errors.New("error getting the stream status")
 	}
 
 	if stream.GameName.String == "" || !stream.GameID.Valid {
-		return fmt.Sprintf("@%v, Esfand isn't under a specific category", target), nil
+		return utils.MentionTarget(user, target, fmt.Sprintf("%v isn't under a specific category", channel.Name)), nil
 	}
 
 	if strings.ToLower(stream.GameName.String) == "just chatting" {
-		return fmt.Sprintf("@%v, Esfand is under the category: %v", target, stream.GameName.String), nil
+		return utils.MentionTarget(user, target, fmt.Sprintf("%v is under the category: %v", channel.Name, stream.GameName.String)), nil
 	}
 
-	return fmt.Sprintf("@%v, Esfand is playing %v", target, stream.GameName.String), nil
+	return utils.MentionTarget(user, target, fmt.Sprintf("%v is playing %v", channel.Name, stream.GameName.String)), nil
 }
@@ -8,10 +8,10 @@ import (
 	"net/http"
 
 	"github.com/dghubble/sling"
+	"github.com/esfands/retpaladinbot/internal/bot/invocation"
 	"github.com/esfands/retpaladinbot/internal/global"
 	"github.com/esfands/retpaladinbot/pkg/domain"
 	"github.com/esfands/retpaladinbot/pkg/utils"
-	"github.com/gempir/go-twitch-irc/v4"
 )
 
 type Command struct {
@@ -67,36 +67,41 @@ func (c *Command) GlobalCooldown() int {
 	return 10
 }
 
-func (c *Command) Code(user twitch.User, context []string) (string, error) {
-	target := utils.GetTarget(user, context)
+func (c *Command) Execute(inv *invocation.Invocation) error {
+	target := utils.GetTarget(inv.User, inv.Args)
 
 	url := "https://api.ivr.fi/"
 	s := sling.New().Base(url).Set("Accept", "application/json")
 	req, err := s.New().Get("v2/misc/gdq/random").Request()
 	if err != nil {
 		slog.Error("[gdq-cmd] error getting gdq donation", "error", err.Error())
-		return "Error getting GDQ donation FeelsBadMan", nil
+		inv.Respond("Error getting GDQ donation FeelsBadMan")
+		return nil
 	}
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := http.DefaultClient.Do(req.WithContext(inv.Ctx))
 	if err != nil {
 		slog.Error("[gdq-cmd] error getting gdq donation", "error", err.Error())
-		return "Error getting GDQ donation FeelsBadMan", nil
+		inv.Respond("Error getting GDQ donation FeelsBadMan")
+		return nil
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		slog.Error("[gdq-cmd] error reading gdq donation body", "error", err.Error())
-		return "Error reading GDQ donation FeelsBadMan", nil
+		inv.Respond("Error reading GDQ donation FeelsBadMan")
+		return nil
 	}
 
 	var gdqResp Response
 	err = json.Unmarshal(body, &gdqResp)
 	if err != nil {
 		slog.Error("[gdq-cmd] error unmarshalling gdq donation", "error", err.Error())
-		return "Error unmarshalling GDQ donation FeelsBadMan", nil
+		inv.Respond("Error unmarshalling GDQ donation FeelsBadMan")
+		return nil
 	}
 
-	return fmt.Sprintf("@%v [%v] %v", target, gdqResp.EventName, gdqResp.Comment), nil
+	inv.Respond(utils.MentionTarget(inv.User, target, fmt.Sprintf("[%v] %v", gdqResp.EventName, gdqResp.Comment)))
+	return nil
 }
@@ -66,11 +66,11 @@ func (c *Command) GlobalCooldown() int {
 	return 10
 }
 
-func (c *Command) Code(user twitch.User, context []string) (string, error) {
+func (c *Command) Code(channel domain.Channel, user twitch.User, context []string) (string, error) {
 	if len(context) >= 1 {
 		url := fmt.Sprintf("https://www.retpaladinbot.com/commands/%v", context[0])
-		return fmt.Sprintf(`@%v help for the command "%v": %v`, user.Name, strings.ToLower(context[0]), url), nil
+		return fmt.Sprintf(`Help for the command "%v": %v`, strings.ToLower(context[0]), url), nil
 	}
 
-	return fmt.Sprintf("@%v, created for EsfandTV and developed by Mahcksimus. Current version: %v, commands: https://www.retpaladinbot.com/", user.Name, c.version), nil
+	return fmt.Sprintf("Created for EsfandTV and developed by Mahcksimus. Current version: %v, commands: https://www.retpaladinbot.com/", c.version), nil
 }
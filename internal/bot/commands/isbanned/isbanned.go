@@ -8,10 +8,10 @@ import (
 	"net/http"
 
 	"github.com/dghubble/sling"
+	"github.com/esfands/retpaladinbot/internal/bot/invocation"
 	"github.com/esfands/retpaladinbot/internal/global"
 	"github.com/esfands/retpaladinbot/pkg/domain"
 	"github.com/esfands/retpaladinbot/pkg/utils"
-	"github.com/gempir/go-twitch-irc/v4"
 )
 
 type Command struct {
@@ -65,41 +65,44 @@ func (c *Command) GlobalCooldown() int {
 	return 10
 }
 
-func (c *Command) Code(user twitch.User, context []string) (string, error) {
-	target := utils.GetTarget(user, context)
+func (c *Command) Execute(inv *invocation.Invocation) error {
+	user := inv.User
+	target := utils.GetTarget(user, inv.Args)
 
 	url := "https://api.ivr.fi"
 	s := sling.New().Base(url).Set("Accept", "application/json")
 	req, err := s.New().Get(fmt.Sprintf("v2/twitch/user?login=%v", target)).Request()
 	if err != nil {
 		slog.Error("[isbanned-cmd] failed to create sling request", "error", err)
-		return fmt.Sprintf("@%v failed to check if a user is banned. FeelsBadMan", user.Name), err
+		return err
 	}
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := http.DefaultClient.Do(req.WithContext(inv.Ctx))
 	if err != nil {
 		slog.Error("[isbanned-cmd] Failed to create response with http client", "error", err)
-		return fmt.Sprintf("@%v failed to check if a user is banned. FeelsBadMan", user.Name), err
+		return err
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		slog.Error("[isbanned-cmd] Failed to read is banned response body", "error", err)
-		return fmt.Sprintf("@%v failed to check if a user is banned. FeelsBadMan", user.Name), err
+		return err
 	}
 
 	var users []User
 	if err := json.Unmarshal(body, &users); err != nil {
 		slog.Error("[isbanned-cmd] Failed to unmarshal ban check response", "error", err)
-		return fmt.Sprintf("@%v failed to check if a user is banned. FeelsBadMan", user.Name), err
+		return err
 	}
 
 	if resp.StatusCode != http.StatusOK || len(users) == 0 {
 		slog.Error("[isbanned-cmd] The api.ivr.fi failed to check a specific user", "status", resp.StatusCode)
-		return fmt.Sprintf("@%v failed to check if a user is banned. FeelsBadMan", user.Name), nil
+		inv.Respond("Failed to check if a user is banned. FeelsBadMan")
+		return nil
 	} else if len(users) == 0 {
-		return fmt.Sprintf("@%v that user doesn't exist... Susage", user.Name), nil
+		inv.Respond("That user doesn't exist... Susage")
+		return nil
 	}
 
 	banCheckUser := users[0]
@@ -107,15 +110,20 @@ func (c *Command) Code(user twitch.User, context []string) (string, error) {
 	if banCheckUser.Banned {
 		switch banCheckUser.BanReason {
 		case "TOS_INDEFINITE":
-			return fmt.Sprintf("@%v, %v is indefinitly banned on Twitch. FeelsBadMan", user.Name, target), nil
+			inv.Respond(fmt.Sprintf("%v is indefinitly banned on Twitch. FeelsBadMan", target))
+			return nil
 		case "DMCA":
-			return fmt.Sprintf("@%v, %v is banned on Twitch for violating DMCA. FeelsBadMan GuitarTime", user.Name, target), nil
+			inv.Respond(fmt.Sprintf("%v is banned on Twitch for violating DMCA. FeelsBadMan GuitarTime", target))
+			return nil
 		case "TOS_TEMPORARY":
-			return fmt.Sprintf("@%v, %v is temporarily banned on Twitch. FeelsBadMan", user.Name, target), nil
+			inv.Respond(fmt.Sprintf("%v is temporarily banned on Twitch. FeelsBadMan", target))
+			return nil
 		default:
-			return fmt.Sprintf("@%v, unexpected ban reason: %v", user.Name, banCheckUser.BanReason), nil
+			inv.Respond(fmt.Sprintf("Unexpected ban reason: %v", banCheckUser.BanReason))
+			return nil
 		}
 	} else {
-		return fmt.Sprintf("@%v, %v is not banned on Twitch! PogChamp", user.Name, target), nil
+		inv.Respond(fmt.Sprintf("%v is not banned on Twitch! PogChamp", target))
+		return nil
 	}
 }
@@ -0,0 +1,163 @@
+package module
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/esfands/retpaladinbot/internal/bot/args"
+	"github.com/esfands/retpaladinbot/internal/bot/invocation"
+	"github.com/esfands/retpaladinbot/internal/global"
+	"github.com/esfands/retpaladinbot/internal/services/modulestore"
+	"github.com/esfands/retpaladinbot/pkg/domain"
+)
+
+type Command struct {
+	gctx global.Context
+}
+
+func NewModuleCommand(gctx global.Context) *Command {
+	return &Command{
+		gctx: gctx,
+	}
+}
+
+func (c *Command) Name() string {
+	return "module"
+}
+
+func (c *Command) Aliases() []string {
+	return []string{"modules"}
+}
+
+func (c *Command) Permissions() []domain.Permission {
+	return []domain.Permission{
+		domain.PermissionBroadcaster,
+		domain.PermissionModerator,
+	}
+}
+
+func (c *Command) Description() string {
+	return "Turn bot modules on and off and change their settings."
+}
+
+func (c *Command) DynamicDescription() []string {
+	return args.Help(c.Arguments(), c.gctx.Config().Twitch.Bot.Prefix+c.Name())
+}
+
+func (c *Command) Arguments() *args.Schema {
+	module := args.Arg{Name: "module", Description: "Name of the module"}
+
+	return &args.Schema{
+		Subcommands: []args.Subcommand{
+			{Name: "list", Schema: args.Schema{Description: "List the modules and whether they're on"}},
+			{Name: "enable", Schema: args.Schema{Description: "Turn a module on", Args: []args.Arg{module}}},
+			{Name: "disable", Schema: args.Schema{Description: "Turn a module off", Args: []args.Arg{module}}},
+			{Name: "set", Schema: args.Schema{
+				Description: "Change a setting of a module",
+				Args:        []args.Arg{module, {Name: "setting"}, {Name: "value", Rest: true, Description: "The new value"}},
+			}},
+			{Name: "reset", Schema: args.Schema{
+				Description: "Reset a setting of a module to its default",
+				Args:        []args.Arg{module, {Name: "setting"}},
+			}},
+		},
+	}
+}
+
+func (c *Command) Conditions() domain.DefaultCommandConditions {
+	return domain.DefaultCommandConditions{
+		EnabledOnline:  true,
+		EnabledOffline: true,
+	}
+}
+
+func (c *Command) UserCooldown() int {
+	return 5
+}
+
+func (c *Command) GlobalCooldown() int {
+	return 0
+}
+
+func (c *Command) Execute(inv *invocation.Invocation) error {
+	store := inv.Services().Modules
+
+	if inv.Values.Subcommand == "list" {
+		var modules []string
+		for _, module := range store.Modules() {
+			state := "off"
+			if store.State(inv.Channel.ID, module.Name).Enabled {
+				state = "on"
+			}
+			modules = append(modules, fmt.Sprintf("%v (%v)", module.Name, state))
+		}
+
+		if len(modules) == 0 {
+			inv.Respond("There are no modules")
+			return nil
+		}
+
+		inv.Respond("Modules: " + strings.Join(modules, ", "))
+		return nil
+	}
+
+	module, ok := store.Get(strings.ToLower(inv.Values.String("module")))
+	if !ok {
+		inv.Respond(fmt.Sprintf("There's no module called %v", inv.Values.String("module")))
+		return nil
+	}
+
+	setting := strings.ToLower(inv.Values.String("setting"))
+	if setting != "" && !hasSetting(module, setting) {
+		inv.Respond(fmt.Sprintf("%v has no setting %v, it has: %v", module.Name, setting, settingNames(module)))
+		return nil
+	}
+
+	switch inv.Values.Subcommand {
+	case "enable", "disable":
+		enabled := inv.Values.Subcommand == "enable"
+		if err := store.SetEnabled(inv.Ctx, inv.Channel.ID, module.Name, enabled); err != nil {
+			return err
+		}
+
+		inv.Respond(fmt.Sprintf("Module %v is now %vd", module.Name, inv.Values.Subcommand))
+
+	case "set":
+		value := inv.Values.String("value")
+		if err := store.SetSetting(inv.Ctx, inv.Channel.ID, module.Name, setting, value); err != nil {
+			return err
+		}
+
+		inv.Respond(fmt.Sprintf("Changed %v of module %v to: %v", setting, module.Name, value))
+
+	case "reset":
+		if err := store.SetSetting(inv.Ctx, inv.Channel.ID, module.Name, setting, ""); err != nil {
+			return err
+		}
+
+		inv.Respond(fmt.Sprintf("Reset %v of module %v to: %v", setting, module.Name, store.State(inv.Channel.ID, module.Name).Settings[setting]))
+	}
+
+	return nil
+}
+
+func hasSetting(module modulestore.Module, name string) bool {
+	for _, setting := range module.Settings {
+		if setting.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func settingNames(module modulestore.Module) string {
+	if len(module.Settings) == 0 {
+		return "none"
+	}
+
+	var names []string
+	for _, setting := range module.Settings {
+		names = append(names, setting.Name)
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,171 @@
+package notice
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/esfands/retpaladinbot/internal/bot/args"
+	"github.com/esfands/retpaladinbot/internal/bot/invocation"
+	"github.com/esfands/retpaladinbot/internal/bot/variables"
+	"github.com/esfands/retpaladinbot/internal/global"
+	"github.com/esfands/retpaladinbot/internal/services/usernotices"
+	"github.com/esfands/retpaladinbot/pkg/domain"
+)
+
+type Command struct {
+	gctx      global.Context
+	variables variables.ServiceI
+}
+
+func NewNoticeCommand(gctx global.Context, variables variables.ServiceI) *Command {
+	return &Command{
+		gctx:      gctx,
+		variables: variables,
+	}
+}
+
+func (c *Command) Name() string {
+	return "notice"
+}
+
+func (c *Command) Aliases() []string {
+	return []string{"notices"}
+}
+
+func (c *Command) Permissions() []domain.Permission {
+	return []domain.Permission{
+		domain.PermissionBroadcaster,
+		domain.PermissionModerator,
+	}
+}
+
+func (c *Command) Description() string {
+	return "Change how the bot responds to subs, gifts, raids and other chat events."
+}
+
+func (c *Command) DynamicDescription() []string {
+	return args.Help(c.Arguments(), c.gctx.Config().Twitch.Bot.Prefix+c.Name())
+}
+
+func (c *Command) Arguments() *args.Schema {
+	var types []string
+	for _, t := range usernotices.Types {
+		types = append(types, t.Name)
+	}
+	event := args.Arg{Name: "event", Description: "The chat event", Choices: types}
+
+	return &args.Schema{
+		Subcommands: []args.Subcommand{
+			{Name: "list", Schema: args.Schema{Description: "List the chat events and whether they're responded to"}},
+			{Name: "show", Schema: args.Schema{Description: "Show the response to a chat event", Args: []args.Arg{event}}},
+			{Name: "set", Schema: args.Schema{
+				Description: "Change the response to a chat event",
+				Args:        []args.Arg{event, {Name: "template", Rest: true, Description: "The response, it can use ${months}, ${gifter}, ${count}, ${viewers} and the other variables"}},
+			}},
+			{Name: "enable", Schema: args.Schema{Description: "Respond to a chat event", Args: []args.Arg{event}}},
+			{Name: "disable", Schema: args.Schema{Description: "Stop responding to a chat event", Args: []args.Arg{event}}},
+			{Name: "threshold", Schema: args.Schema{
+				Description: "Skip chat events below an amount, e.g. raids with fewer viewers, 0 responds to all of them",
+				Args:        []args.Arg{event, {Name: "amount", Type: args.Int}},
+			}},
+			{Name: "reset", Schema: args.Schema{Description: "Go back to the default response to a chat event", Args: []args.Arg{event}}},
+		},
+	}
+}
+
+func (c *Command) Conditions() domain.DefaultCommandConditions {
+	return domain.DefaultCommandConditions{
+		EnabledOnline:  true,
+		EnabledOffline: true,
+	}
+}
+
+func (c *Command) UserCooldown() int {
+	return 5
+}
+
+func (c *Command) GlobalCooldown() int {
+	return 0
+}
+
+func (c *Command) Execute(inv *invocation.Invocation) error {
+	store := inv.Services().UserNotices
+
+	if inv.Values.Subcommand == "list" {
+		responses, err := store.List(inv.Ctx, inv.Channel.ID)
+		if err != nil {
+			return err
+		}
+
+		var list []string
+		for _, response := range responses {
+			state := "off"
+			if response.Enabled {
+				state = "on"
+			}
+			list = append(list, fmt.Sprintf("%v (%v)", response.Type, state))
+		}
+		inv.Respond("Chat events: " + strings.Join(list, ", "))
+		return nil
+	}
+
+	t, ok := usernotices.GetType(strings.ToLower(inv.Values.String("event")))
+	if !ok {
+		inv.Respond(fmt.Sprintf("There's no chat event called %v", inv.Values.String("event")))
+		return nil
+	}
+
+	if inv.Values.Subcommand == "reset" {
+		if err := store.Reset(inv.Ctx, inv.Channel.ID, t.Name); err != nil {
+			return err
+		}
+
+		inv.Respond(fmt.Sprintf("Reset the response to %v to: %v", t.Name, t.Template))
+		return nil
+	}
+
+	response, err := store.Get(inv.Ctx, inv.Channel.ID, t.Name)
+	if err != nil {
+		return err
+	}
+
+	switch inv.Values.Subcommand {
+	case "show":
+		threshold := ""
+		if t.Amount != "" {
+			threshold = fmt.Sprintf(", threshold %d %v", response.Threshold, t.Amount)
+		}
+		state := "off"
+		if response.Enabled {
+			state = "on"
+		}
+		inv.Respond(fmt.Sprintf("%v (%v%v): %v", t.Name, state, threshold, response.Template))
+		return nil
+
+	case "set":
+		template := inv.Values.String("template")
+		if _, err := c.variables.Parse(template); err != nil {
+			inv.Respond(fmt.Sprintf("Invalid response: %v", err))
+			return nil
+		}
+		response.Template = template
+
+	case "enable", "disable":
+		response.Enabled = inv.Values.Subcommand == "enable"
+
+	case "threshold":
+		if t.Amount == "" {
+			inv.Respond(fmt.Sprintf("%v has no amount to compare with a threshold", t.Name))
+			return nil
+		}
+		response.Threshold = inv.Values.Int("amount")
+	}
+
+	if err := store.Save(inv.Ctx, response); err != nil {
+		inv.Respond(fmt.Sprintf("Couldn't change the response to %v: %v", t.Name, err))
+		return nil
+	}
+
+	inv.Respond(fmt.Sprintf("Changed the response to %v", t.Name))
+	return nil
+}
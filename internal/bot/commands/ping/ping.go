@@ -61,8 +61,8 @@ func (c *Command) GlobalCooldown() int {
 	return 10
 }
 
-func (c *Command) Code(user twitch.User, context []string) (string, error) {
+func (c *Command) Code(channel domain.Channel, user twitch.User, context []string) (string, error) {
 	uptime := utils.TimeDifference(c.gctx.Config().Timestamp, time.Now(), true)
 
-	return fmt.Sprintf("@%v, FeelsOkayMan 🏓 Uptime: %v", user.Name, uptime), nil
+	return fmt.Sprintf("FeelsOkayMan 🏓 Uptime: %v", uptime), nil
 }
@@ -7,10 +7,10 @@ import (
 	"net/http"
 
 	"github.com/dghubble/sling"
+	"github.com/esfands/retpaladinbot/internal/bot/invocation"
 	"github.com/esfands/retpaladinbot/internal/global"
 	"github.com/esfands/retpaladinbot/pkg/domain"
 	"github.com/esfands/retpaladinbot/pkg/utils"
-	"github.com/gempir/go-twitch-irc/v4"
 )
 
 type Command struct {
@@ -66,39 +66,40 @@ func (c *Command) GlobalCooldown() int {
 	return 10
 }
 
-func (c *Command) Code(user twitch.User, context []string) (string, error) {
-	target := utils.GetTarget(user, context)
+func (c *Command) Execute(inv *invocation.Invocation) error {
+	target := utils.GetTarget(inv.User, inv.Args)
 
 	req, err := sling.New().Get(fmt.Sprintf("http://ws.audioscrobbler.com/2.0/?method=user.getrecenttracks&user=esfandtv&api_key=%v&format=json", c.gctx.Config().APIKeys.LastFM)).Request()
 	if err != nil {
-		return "", err
+		return err
 	}
 
-	request, err := http.DefaultClient.Do(req)
+	request, err := http.DefaultClient.Do(req.WithContext(inv.Ctx))
 	if err != nil {
-		return "", err
+		return err
 	}
 
 	defer request.Body.Close()
 	body, err := io.ReadAll(request.Body)
 	if err != nil {
-		return "", err
+		return err
 	}
 
 	var history Response
 	err = json.Unmarshal(body, &history)
 	if err != nil {
-		return "", err
+		return err
 	}
 
 	if len(history.RecentTracks.Track) == 0 {
-		return fmt.Sprintf("@%v, nothing has been listened to yet.", user.Name), nil
+		inv.Respond("Nothing has been listened to yet.")
+		return nil
 	}
 
-	return fmt.Sprintf(
-		"@%v, current song: %v - %v | Full history -> https://www.last.fm/user/esfandtv/library",
-		target,
+	inv.Respond(utils.MentionTarget(inv.User, target, fmt.Sprintf(
+		"current song: %v - %v | Full history -> https://www.last.fm/user/esfandtv/library",
 		history.RecentTracks.Track[0].Name,
 		history.RecentTracks.Track[0].Artist.Text,
-	), nil
+	)))
+	return nil
 }
@@ -6,14 +6,14 @@ import (
 	"io"
 	"log/slog"
 	"net/http"
-	"strings"
 	"time"
 
 	"github.com/dghubble/sling"
+	"github.com/esfands/retpaladinbot/internal/bot/args"
+	"github.com/esfands/retpaladinbot/internal/bot/invocation"
 	"github.com/esfands/retpaladinbot/internal/global"
 	"github.com/esfands/retpaladinbot/pkg/domain"
 	"github.com/esfands/retpaladinbot/pkg/utils"
-	"github.com/gempir/go-twitch-irc/v4"
 )
 
 type Command struct {
@@ -39,23 +39,20 @@ func (c *Command) Permissions() []domain.Permission {
 }
 
 func (c *Command) Description() string {
-	return "Get subage of a user for a specific channel. Defaults to Esfands."
+	return "Get subage of a user for a specific channel. Defaults to the current channel."
 }
 
 func (c *Command) DynamicDescription() []string {
-	prefix := c.gctx.Config().Twitch.Bot.Prefix
-
-	return []string{
-		"If no channel is given it will default to EsfandTV",
-		"<br/>",
-		"Check your subage to EsfandTV.",
-		fmt.Sprintf("<code>%vsubage</code>", prefix),
-		"<br/>",
-		"Check subage for another user in Esfand's channel.",
-		fmt.Sprintf("<code>%vsubage (username)</code>", prefix),
-		"<br/>",
-		"Check subage for another user in a specific channel.",
-		fmt.Sprintf("<code>%vsubage (username) (channel)</code>", prefix),
+	return args.Help(c.Arguments(), c.gctx.Config().Twitch.Bot.Prefix+c.Name())
+}
+
+func (c *Command) Arguments() *args.Schema {
+	return &args.Schema{
+		Description: "Check the subage of a user in a channel, if no channel is given it will default to the current channel",
+		Args: []args.Arg{
+			{Name: "username", Type: args.User, Optional: true, Description: "Defaults to yourself"},
+			{Name: "channel", Type: args.Channel, Optional: true, Description: "Defaults to the current channel"},
+		},
 	}
 }
 
@@ -74,49 +71,48 @@ func (c *Command) GlobalCooldown() int {
 	return 10
 }
 
-func (c *Command) Code(user twitch.User, context []string) (string, error) {
-	// Parse targetUser and targetChannel from context
-	targetUser := user.Name
-	if len(context) > 0 {
-		targetUser = context[0]
+func (c *Command) Execute(inv *invocation.Invocation) error {
+	targetUser := inv.User.Name
+	if inv.Values.Has("username") {
+		targetUser = inv.Values.String("username")
 	}
 
-	targetChannel := "esfandtv"
-	if len(context) > 1 {
-		targetChannel = context[1]
+	targetChannel := inv.Channel.Name
+	if inv.Values.Has("channel") {
+		targetChannel = inv.Values.String("channel")
 	}
 
-	// Remove "@" if it exists in targetUser or targetChannel
-	targetUser = strings.TrimPrefix(targetUser, "@")
-	targetChannel = strings.TrimPrefix(targetChannel, "@")
-
 	// Begin logic to make the API request
 	url := "https://api.ivr.fi/"
 	s := sling.New().Base(url).Set("Accept", "application/json")
 	req, err := s.New().Get(fmt.Sprintf("v2/twitch/subage/%s/%s", targetUser, targetChannel)).Request()
 	if err != nil {
 		slog.Error("[subage-cmd] error getting subage", "error", err.Error())
-		return "Error getting the subage FeelsBadMan", nil
+		inv.Respond("Error getting the subage FeelsBadMan")
+		return nil
 	}
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := http.DefaultClient.Do(req.WithContext(inv.Ctx))
 	if err != nil {
 		slog.Error("[subage-cmd] error getting subage", "error", err.Error())
-		return "Error getting the subage FeelsBadMan", nil
+		inv.Respond("Error getting the subage FeelsBadMan")
+		return nil
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		slog.Error("[subage-cmd] error reading subage body", "error", err.Error())
-		return "Error getting the subage FeelsBadMan", nil
+		inv.Respond("Error getting the subage FeelsBadMan")
+		return nil
 	}
 
 	var subageRes SubageResponse
 	err = json.Unmarshal(body, &subageRes)
 	if err != nil {
 		slog.Error("[subage-cmd] error unmarshalling subage", "error", err.Error())
-		return "Error getting the subage FeelsBadMan", nil
+		inv.Respond("Error getting the subage FeelsBadMan")
+		return nil
 	}
 
 	oldSub := subageRes.Cumulative
@@ -124,18 +120,21 @@ func (c *Command) Code(user twitch.User, context []string) (string, error) {
 	// If they're not subbed...
 	if subageRes.Meta == nil {
 		if oldSub == nil || oldSub.Months == 0 {
-			return fmt.Sprintf("%s is not subbed to %s and never has been.", targetUser, targetChannel), nil
+			inv.Respond(fmt.Sprintf("%s is not subbed to %s and never has been.", targetUser, targetChannel))
+			return nil
 		} else {
 			parsedOldTime, err := time.Parse(time.RFC3339, oldSub.End)
 			if err != nil {
 				slog.Error("[subage-cmd] error parsing sub end time", "error", err.Error())
-				return "Error parsing the sub end time FeelsBadMan", nil
+				inv.Respond("Error parsing the sub end time FeelsBadMan")
+				return nil
 			}
 
-			return fmt.Sprintf(
+			inv.Respond(fmt.Sprintf(
 				"%s is not subbed to %s but has been previously for a total of %d months. Sub ended %s ago.",
 				targetUser, targetChannel, oldSub.Months, utils.TimeDifference(time.Now(), parsedOldTime, true),
-			), nil
+			))
+			return nil
 		}
 	} else {
 		subData := subageRes.Meta
@@ -143,54 +142,62 @@ func (c *Command) Code(user twitch.User, context []string) (string, error) {
 		subStreak := subageRes.Streak
 
 		if subData.Tier == "Custom" {
-			return fmt.Sprintf(
+			inv.Respond(fmt.Sprintf(
 				"%s is subbed to %s with a permanent sub and has been subbed for a total of %d months! They are currently on a %d months streak.",
 				targetUser, targetChannel, subLength.Months, subStreak.Months,
-			), nil
+			))
+			return nil
 		}
 		if subData.EndsAt == "" {
-			return fmt.Sprintf(
+			inv.Respond(fmt.Sprintf(
 				"%s is currently subbed to %s with a Tier %s sub and has been subbed for a total of %d months! They are currently on a %d months streak. This is a permanent sub.",
 				targetUser, targetChannel, subData.Tier, subLength.Months, subStreak.Months,
-			), nil
+			))
+			return nil
 		}
 		if subData.Type == "prime" {
 			parsedEndTime, err := time.Parse(time.RFC3339, subData.EndsAt)
 			if err != nil {
 				slog.Error("[subage-cmd] error parsing sub end time", "error", err.Error())
-				return "Error parsing the sub end time FeelsBadMan", nil
+				inv.Respond("Error parsing the sub end time FeelsBadMan")
+				return nil
 			}
 
-			return fmt.Sprintf(
+			inv.Respond(fmt.Sprintf(
 				"%s is currently subbed to %s with a prime sub and has been subbed for a total of %d months! They are currently on a %d months streak. The sub ends/renews in %s",
 				targetUser, targetChannel, subLength.Months, subStreak.Months, utils.TimeDifference(parsedEndTime, time.Now(), true),
-			), nil
+			))
+			return nil
 		}
 		if subData.Type == "paid" {
 			parsedEndTime, err := time.Parse(time.RFC3339, subData.EndsAt)
 			if err != nil {
 				slog.Error("[subage-cmd] error parsing sub end time", "error", err.Error())
-				return "Error parsing the sub end time FeelsBadMan", nil
+				inv.Respond("Error parsing the sub end time FeelsBadMan")
+				return nil
 			}
 
-			return fmt.Sprintf(
+			inv.Respond(fmt.Sprintf(
 				"%s is currently subbed to %s with a paid sub and has been subbed for a total of %d months! They are currently on a %d months streak. The sub ends/renews in %s",
 				targetUser, targetChannel, subLength.Months, subStreak.Months, utils.TimeDifference(parsedEndTime, time.Now(), true),
-			), nil
+			))
+			return nil
 		}
 		if subData.Type == "gift" {
 			parsedEndTime, err := time.Parse(time.RFC3339, subData.EndsAt)
 			if err != nil {
 				slog.Error("[subage-cmd] error parsing sub end time", "error", err.Error())
-				return "Error parsing the sub end time FeelsBadMan", nil
+				inv.Respond("Error parsing the sub end time FeelsBadMan")
+				return nil
 			}
 
-			return fmt.Sprintf(
+			inv.Respond(fmt.Sprintf(
 				"%s is currently subbed to %s with a gifted sub from %s and has been subbed for a total of %d months! They are currently on a %d months streak. The sub ends/renews in %s",
 				targetUser, targetChannel, subData.GiftMeta.Gifter.DisplayName, subLength.Months, subStreak.Months, utils.TimeDifference(parsedEndTime, time.Now(), true),
-			), nil
+			))
+			return nil
 		}
 	}
 
-	return "", nil
+	return nil
 }
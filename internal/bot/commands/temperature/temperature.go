@@ -2,11 +2,11 @@ package temperature
 
 import (
 	"fmt"
-	"strconv"
 
+	"github.com/esfands/retpaladinbot/internal/bot/args"
+	"github.com/esfands/retpaladinbot/internal/bot/invocation"
 	"github.com/esfands/retpaladinbot/internal/global"
 	"github.com/esfands/retpaladinbot/pkg/domain"
-	"github.com/gempir/go-twitch-irc/v4"
 )
 
 type Command struct {
@@ -36,14 +36,21 @@ func (c *Command) Description() string {
 }
 
 func (c *Command) DynamicDescription() []string {
-	prefix := c.gctx.Config().Twitch.Bot.Prefix
-
-	return []string{
-		"Translate Celsius to Fahrenheit",
-		fmt.Sprintf("<code>%vtemperature toF (celsius)</code>", prefix),
-		"<br/>",
-		"Translate Fahrenheit to Celsius.",
-		fmt.Sprintf("<code>%vtemperature toC (fahrenheit)</code>", prefix),
+	return args.Help(c.Arguments(), c.gctx.Config().Twitch.Bot.Prefix+c.Name())
+}
+
+func (c *Command) Arguments() *args.Schema {
+	return &args.Schema{
+		Subcommands: []args.Subcommand{
+			{Name: "toF", Schema: args.Schema{
+				Description: "Translate Celsius to Fahrenheit",
+				Args:        []args.Arg{{Name: "celsius", Type: args.Float}},
+			}},
+			{Name: "toC", Schema: args.Schema{
+				Description: "Translate Fahrenheit to Celsius",
+				Args:        []args.Arg{{Name: "fahrenheit", Type: args.Float}},
+			}},
+		},
 	}
 }
 
@@ -62,35 +69,20 @@ func (c *Command) GlobalCooldown() int {
 	return 10
 }
 
-func (c *Command) Code(user twitch.User, context []string) (string, error) {
-	prefix := c.gctx.Config().Twitch.Bot.Prefix
-
-	if len(context) < 2 {
-		return fmt.Sprintf(`Invalid number of arguments. Example: "%vtemperature toF/toC (number)"`, prefix), nil
-	}
-
-	switch context[0] {
+func (c *Command) Execute(inv *invocation.Invocation) error {
+	switch inv.Values.Subcommand {
 	case "toF":
 		// Convert Celsius to Fahrenheit
-		celsius, err := strconv.ParseFloat(context[1], 64)
-		if err != nil {
-			return "Invalid temperature value. Please provide a valid number.", nil
-		}
-
+		celsius := inv.Values.Float("celsius")
 		fahrenheit := (celsius * 9 / 5) + 32
-		return fmt.Sprintf("%v, %.2f°C is %.2f°F", user.Name, celsius, fahrenheit), nil
+		inv.Respond(fmt.Sprintf("%.2f°C is %.2f°F", celsius, fahrenheit))
 
 	case "toC":
 		// Convert Fahrenheit to Celsius
-		fahrenheit, err := strconv.ParseFloat(context[1], 64)
-		if err != nil {
-			return "Invalid temperature value. Please provide a valid number.", nil
-		}
-
+		fahrenheit := inv.Values.Float("fahrenheit")
 		celsius := (fahrenheit - 32) * 5 / 9
-		return fmt.Sprintf("%v, %.2f°F is %.2f°C", user.Name, fahrenheit, celsius), nil
-
-	default:
-		return fmt.Sprintf(`Invalid conversion type. Example: "%vtemperature toF/toC (number)"`, prefix), nil
+		inv.Respond(fmt.Sprintf("%.2f°F is %.2f°C", fahrenheit, celsius))
 	}
+
+	return nil
 }
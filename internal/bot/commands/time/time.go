@@ -63,7 +63,7 @@ func (c *Command) GlobalCooldown() int {
 	return 10
 }
 
-func (c *Command) Code(user twitch.User, context []string) (string, error) {
+func (c *Command) Code(channel domain.Channel, user twitch.User, context []string) (string, error) {
 	target := utils.GetTarget(user, context)
 
 	location, err := time.LoadLocation("America/Chicago")
@@ -74,9 +74,9 @@ func (c *Command) Code(user twitch.User, context []string) (string, error) {
 
 	currentTime := time.Now().In(location)
 
-	return fmt.Sprintf(
-		"@%v Esfand's local time is %v CST KKona (%v)",
-		target, currentTime.Format("03:04 PM"),
+	return utils.MentionTarget(user, target, fmt.Sprintf(
+		"Esfand's local time is %v CST KKona (%v)",
+		currentTime.Format("03:04 PM"),
 		currentTime.Format("15:04"),
-	), nil
+	)), nil
 }
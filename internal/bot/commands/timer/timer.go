@@ -0,0 +1,239 @@
+package timer
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/esfands/retpaladinbot/internal/bot/args"
+	"github.com/esfands/retpaladinbot/internal/bot/invocation"
+	"github.com/esfands/retpaladinbot/internal/bot/variables"
+	"github.com/esfands/retpaladinbot/internal/global"
+	"github.com/esfands/retpaladinbot/internal/services/timers"
+	"github.com/esfands/retpaladinbot/pkg/domain"
+)
+
+type Command struct {
+	gctx      global.Context
+	variables variables.ServiceI
+}
+
+func NewTimerCommand(gctx global.Context, variables variables.ServiceI) *Command {
+	return &Command{
+		gctx:      gctx,
+		variables: variables,
+	}
+}
+
+func (c *Command) Name() string {
+	return "timer"
+}
+
+func (c *Command) Aliases() []string {
+	return []string{"timers"}
+}
+
+func (c *Command) Permissions() []domain.Permission {
+	return []domain.Permission{
+		domain.PermissionBroadcaster,
+		domain.PermissionModerator,
+	}
+}
+
+func (c *Command) Description() string {
+	return "Manage timers, messages which are posted in rotation while the chat is active."
+}
+
+func (c *Command) DynamicDescription() []string {
+	return args.Help(c.Arguments(), c.gctx.Config().Twitch.Bot.Prefix+c.Name())
+}
+
+// timerOptions are the settings which can be given when adding to or changing a timer
+var timerOptions = []args.Option{
+	{Name: "interval", Type: args.Int, Description: fmt.Sprintf("Minutes between messages, at least %d, %d by default", timers.MinInterval, timers.DefaultInterval)},
+	{Name: "lines", Type: args.Int, Description: fmt.Sprintf("Chat lines needed since the last message, %d by default", timers.DefaultMinLines)},
+	{Name: "when", Description: "Post always, only when live or only when offline, always by default"},
+}
+
+func (c *Command) Arguments() *args.Schema {
+	name := args.Arg{Name: "name", Description: "Name of the timer"}
+
+	return &args.Schema{
+		Subcommands: []args.Subcommand{
+			{Name: "list", Schema: args.Schema{Description: "List the timers of the channel"}},
+			{Name: "add", Schema: args.Schema{
+				Description: "Add a message to a timer, the timer is created if it doesn't exist",
+				Args:        []args.Arg{name, {Name: "message", Rest: true, Description: "The message, it can use variables"}},
+				Options:     timerOptions,
+			}},
+			{Name: "delmessage", Schema: args.Schema{
+				Description: "Remove a message from a timer",
+				Args:        []args.Arg{name, {Name: "number", Type: args.Int, Description: "Number of the message, starting at 1"}},
+			}},
+			{Name: "set", Schema: args.Schema{
+				Description: "Change the settings of a timer",
+				Args:        []args.Arg{name},
+				Options:     timerOptions,
+			}},
+			{Name: "enable", Schema: args.Schema{Description: "Turn a timer on", Args: []args.Arg{name}}},
+			{Name: "disable", Schema: args.Schema{Description: "Turn a timer off", Args: []args.Arg{name}}},
+			{Name: "remove", Schema: args.Schema{Description: "Remove a timer and its messages", Args: []args.Arg{name}}},
+		},
+	}
+}
+
+func (c *Command) Conditions() domain.DefaultCommandConditions {
+	return domain.DefaultCommandConditions{
+		EnabledOnline:  true,
+		EnabledOffline: true,
+	}
+}
+
+func (c *Command) UserCooldown() int {
+	return 5
+}
+
+func (c *Command) GlobalCooldown() int {
+	return 0
+}
+
+func (c *Command) Execute(inv *invocation.Invocation) error {
+	store := inv.Services().Timers
+
+	if inv.Values.Subcommand == "list" {
+		list, err := store.List(inv.Ctx, inv.Channel.ID)
+		if err != nil {
+			return err
+		}
+
+		if len(list) == 0 {
+			inv.Respond("There are no timers")
+			return nil
+		}
+
+		var described []string
+		for _, t := range list {
+			described = append(described, describe(t))
+		}
+		inv.Respond("Timers: " + strings.Join(described, " | "))
+		return nil
+	}
+
+	name := strings.ToLower(inv.Values.String("name"))
+
+	if inv.Values.Subcommand == "add" {
+		return c.add(inv, store, name)
+	}
+
+	timer, err := store.Get(inv.Ctx, inv.Channel.ID, name)
+	if errors.Is(err, timers.ErrNotFound) {
+		inv.Respond(fmt.Sprintf("There's no timer called %v", name))
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	switch inv.Values.Subcommand {
+	case "delmessage":
+		number := inv.Values.Int("number")
+		if number < 1 || number > len(timer.Messages) {
+			inv.Respond(fmt.Sprintf("Timer %v has %d messages", name, len(timer.Messages)))
+			return nil
+		}
+		timer.Messages = append(timer.Messages[:number-1], timer.Messages[number:]...)
+
+	case "set":
+		if problem := applyOptions(&timer, inv.Values); problem != "" {
+			inv.Respond(problem)
+			return nil
+		}
+
+	case "enable", "disable":
+		timer.Enabled = inv.Values.Subcommand == "enable"
+
+	case "remove":
+		if _, err := store.Delete(inv.Ctx, inv.Channel.ID, name); err != nil {
+			return err
+		}
+
+		inv.Respond(fmt.Sprintf("Removed timer %v", name))
+		return nil
+	}
+
+	if err := store.Update(inv.Ctx, timer); err != nil {
+		inv.Respond(fmt.Sprintf("Couldn't change timer %v: %v", name, err))
+		return nil
+	}
+
+	inv.Respond(fmt.Sprintf("Changed timer %s", describe(timer)))
+	return nil
+}
+
+// add adds a message to a timer, creating the timer with the default settings if it doesn't exist yet
+func (c *Command) add(inv *invocation.Invocation, store timers.Store, name string) error {
+	message := inv.Values.String("message")
+	if _, err := c.variables.Parse(message); err != nil {
+		inv.Respond(fmt.Sprintf("Invalid message: %v", err))
+		return nil
+	}
+
+	timer, err := store.Get(inv.Ctx, inv.Channel.ID, name)
+	exists := err == nil
+	if errors.Is(err, timers.ErrNotFound) {
+		timer = domain.Timer{
+			ChannelID: inv.Channel.ID,
+			Name:      name,
+			Interval:  timers.DefaultInterval,
+			MinLines:  timers.DefaultMinLines,
+			Condition: domain.StreamAlways,
+			Enabled:   true,
+		}
+	} else if err != nil {
+		return err
+	}
+
+	if problem := applyOptions(&timer, inv.Values); problem != "" {
+		inv.Respond(problem)
+		return nil
+	}
+	timer.Messages = append(timer.Messages, message)
+
+	if exists {
+		err = store.Update(inv.Ctx, timer)
+	} else {
+		timer, err = store.Create(inv.Ctx, timer)
+	}
+	if err != nil {
+		inv.Respond(fmt.Sprintf("Couldn't add the message: %v", err))
+		return nil
+	}
+
+	inv.Respond(fmt.Sprintf("Added message #%d to timer %s", len(timer.Messages), describe(timer)))
+	return nil
+}
+
+// applyOptions applies the given options to a timer, it returns what's wrong with them if they're invalid
+func applyOptions(timer *domain.Timer, values args.Values) string {
+	if values.Has("interval") {
+		timer.Interval = values.Int("interval")
+	}
+	if values.Has("lines") {
+		timer.MinLines = values.Int("lines")
+	}
+	if values.Has("when") {
+		timer.Condition = domain.StreamCondition(strings.ToLower(values.String("when")))
+	}
+
+	if err := timers.Validate(timer); err != nil {
+		return fmt.Sprintf("Invalid timer: %v", err)
+	}
+	return ""
+}
+
+func describe(timer domain.Timer) string {
+	state := ""
+	if !timer.Enabled {
+		state = ", off"
+	}
+	return fmt.Sprintf("%v (every %dm after %d lines, %v, %d messages%v)", timer.Name, timer.Interval, timer.MinLines, timer.Condition, len(timer.Messages), state)
+}
@@ -1,6 +1,7 @@
 package title
 
 import (
+	"database/sql"
 	"errors"
 	"fmt"
 
@@ -61,17 +62,18 @@ func (c *Command) GlobalCooldown() int {
 	return 10
 }
 
-func (c *Command) Code(user twitch.User, context []string) (string, error) {
+func (c *Command) Code(channel domain.Channel, user twitch.User, context []string) (string, error) {
 	target := utils.GetTarget(user, context)
 
-	stream, err := c.gctx.Crate().Turso.Queries().GetMostRecentStreamStatus(c.gctx)
-	if err != nil {
+	stream, err := c.gctx.Crate().Turso.Queries().GetMostRecentStreamStatus(c.gctx, channel.ID)
+	// Channels which haven't streamed since they were added have no stream status yet
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
 		return "", errors.New("error getting the stream status")
 	}
 
 	if stream.Title.String == "" || !stream.Title.Valid {
-		return fmt.Sprintf("@%v the title is not set to anything", target), nil
+		return utils.MentionTarget(user, target, "the title is not set to anything"), nil
 	}
 
-	return fmt.Sprintf("@%v current title: %v", target, stream.Title.String), nil
+	return utils.MentionTarget(user, target, fmt.Sprintf("current title: %v", stream.Title.String)), nil
 }
@@ -1,6 +1,7 @@
 package uptime
 
 import (
+	"database/sql"
 	"errors"
 	"fmt"
 	"log/slog"
@@ -63,15 +64,20 @@ func (c *Command) GlobalCooldown() int {
 	return 10
 }
 
-func (c *Command) Code(user twitch.User, context []string) (string, error) {
+func (c *Command) Code(channel domain.Channel, user twitch.User, context []string) (string, error) {
 	target := utils.GetTarget(user, context)
 
-	stream, err := c.gctx.Crate().Turso.Queries().GetMostRecentStreamStatus(c.gctx)
-	if err != nil {
+	stream, err := c.gctx.Crate().Turso.Queries().GetMostRecentStreamStatus(c.gctx, channel.ID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
 		slog.Error("[uptime-cmd] error getting most recent stream status", "error", err.Error())
 		return "", err
 	}
 
+	// Channels which haven't streamed since they were added have no stream, or only the title and category of one
+	if errors.Is(err, sql.ErrNoRows) || (!stream.Live && !stream.EndedAt.Valid) {
+		return utils.MentionTarget(user, target, "the stream is offline"), nil
+	}
+
 	// Get the uptime since there's no end time
 	if !stream.EndedAt.Valid {
 		// Parse the start time
@@ -82,7 +88,7 @@ func (c *Command) Code(user twitch.User, context []string) (string, error) {
 		}
 
 		uptime := utils.TimeDifference(parsedStartTime, time.Now(), true)
-		return fmt.Sprintf("@%v, the stream has been live for %v", target, uptime), nil
+		return utils.MentionTarget(user, target, fmt.Sprintf("the stream has been live for %v", uptime)), nil
 	} else {
 		parsedEndTime, err := time.Parse(time.RFC3339, stream.EndedAt.String)
 		if err != nil {
@@ -91,6 +97,6 @@ func (c *Command) Code(user twitch.User, context []string) (string, error) {
 		}
 
 		downtime := utils.TimeDifference(time.Now(), parsedEndTime, true)
-		return fmt.Sprintf("@%v, the stream has been offline for %v", target, downtime), nil
+		return utils.MentionTarget(user, target, fmt.Sprintf("the stream has been offline for %v", downtime)), nil
 	}
 }
@@ -1,21 +1,37 @@
 package bot
 
 import (
+	"context"
 	"log/slog"
+	"time"
 
 	"github.com/esfands/retpaladinbot/config"
+	"github.com/esfands/retpaladinbot/internal/bot/channels"
 	"github.com/esfands/retpaladinbot/internal/bot/commands"
 	"github.com/esfands/retpaladinbot/internal/bot/modules"
+	"github.com/esfands/retpaladinbot/internal/bot/modules/announcements"
+	"github.com/esfands/retpaladinbot/internal/bot/modules/raids"
+	"github.com/esfands/retpaladinbot/internal/bot/modules/redemptions"
+	"github.com/esfands/retpaladinbot/internal/bot/modules/timers"
+	"github.com/esfands/retpaladinbot/internal/bot/modules/usernotices"
+	"github.com/esfands/retpaladinbot/internal/bot/pipeline"
+	"github.com/esfands/retpaladinbot/internal/bot/responses"
+	"github.com/esfands/retpaladinbot/internal/bot/sender"
 	"github.com/esfands/retpaladinbot/internal/bot/variables"
 	"github.com/esfands/retpaladinbot/internal/global"
+	"github.com/esfands/retpaladinbot/internal/services/events"
 	"github.com/gempir/go-twitch-irc/v4"
 )
 
 type Connection struct {
 	client         *twitch.Client
+	Sender         sender.Service
+	ChannelManager *channels.ChannelManager
 	CommandManager *commands.CommandManager
 	ModuleManager  *modules.ModuleManager
+	Pipeline       *pipeline.Pipeline
 	Variables      variables.ServiceI
+	Responses      *responses.Picker
 }
 
 func StartBot(gctx global.Context, cfg *config.Config, version string) {
@@ -26,6 +42,9 @@ func StartBot(gctx global.Context, cfg *config.Config, version string) {
 	conn.client = twitch.NewClient(cfg.Twitch.Bot.Username, cfg.Twitch.Bot.OAuth)
 	slog.Info("Twitch client initialized", "username", cfg.Twitch.Bot.Username)
 
+	// Every outgoing message goes through the sender so it's rate limited and split
+	conn.Sender = sender.New(conn.client)
+
 	// Register variables service
 	conn.Variables = variables.NewService(gctx)
 	if conn.Variables == nil {
@@ -33,39 +52,80 @@ func StartBot(gctx global.Context, cfg *config.Config, version string) {
 		return
 	}
 
+	// Custom commands with more than one response remember their last picks here
+	conn.Responses = responses.NewPicker()
+
+	// Load the channels the bot should join
+	conn.ChannelManager, err = channels.NewChannelManager(gctx)
+	if err != nil {
+		slog.Error("Error setting up channels", "error", err.Error())
+		return
+	}
+	slog.Info("ChannelManager setup complete")
+
 	// Setup ModuleManager with error logging
-	conn.ModuleManager, err = modules.NewModuleManager(gctx, conn.client)
+	conn.ModuleManager, err = modules.NewModuleManager(gctx, conn.Sender, conn.ChannelManager)
 	if err != nil {
 		slog.Error("Error setting up bot modules", "error", err.Error())
 		return
 	}
+	conn.ModuleManager.Register(announcements.NewAnnouncementsModule(conn.Variables))
+	conn.ModuleManager.Register(timers.NewTimersModule(conn.Variables))
+	conn.ModuleManager.Register(usernotices.NewUserNoticesModule(conn.Variables))
+	conn.ModuleManager.Register(raids.NewRaidsModule(conn.Variables))
+	conn.ModuleManager.Register(redemptions.NewRedemptionsModule(conn.Variables, conn.Responses))
+	conn.ModuleManager.Start()
 	slog.Info("ModuleManager setup complete")
 
 	// Setup CommandManager
-	commandManager := commands.NewCommandManager(gctx, version)
+	commandManager := commands.NewCommandManager(gctx, version, conn.Variables)
 	if commandManager == nil {
 		slog.Error("Failed to initialize CommandManager")
 		return
 	}
 	slog.Info("CommandManager setup complete")
 
+	// Every command runs through the same middleware chain
+	conn.Pipeline = pipeline.Default()
+	countUsage(gctx)
+	conn.reloadChannels(gctx)
+
 	// Register message handlers with additional logging
 	conn.client.OnPrivateMessage(func(message twitch.PrivateMessage) {
 		conn.OnPrivateMessage(gctx, message, commandManager, conn.Variables)
 	})
 	conn.client.OnUserNoticeMessage(func(message twitch.UserNoticeMessage) {
-		OnUserNoticeMessage(conn.client, message)
+		events.Publish(gctx.Crate().Events, events.UserNotice, events.UserNoticeEvent{
+			ChannelID: message.RoomID,
+			Message:   message,
+		})
+	})
+	conn.client.OnUserStateMessage(func(message twitch.UserStateMessage) {
+		conn.OnUserStateMessage(message)
 	})
 
-	// Attempt to join the specified channel with error handling
-	slog.Info("Attempting to join channel", "channel", cfg.Twitch.Bot.Channel)
-	conn.client.Join(cfg.Twitch.Bot.Channel)
-	slog.Info("Successfully joined channel", "channel", cfg.Twitch.Bot.Channel)
+	// Attempt to join the channels with error handling
+	channelNames := conn.ChannelManager.Names()
+	slog.Info("Attempting to join channels", "channels", channelNames)
+	conn.client.Join(channelNames...)
+	slog.Info("Successfully joined channels", "channels", channelNames)
 
 	// Graceful shutdown handling
 	go func() {
 		<-gctx.Done()
 		slog.Info("Twitch bot shutting down...")
+
+		if err := conn.ModuleManager.Stop(); err != nil {
+			slog.Error("Error stopping modules", "error", err.Error())
+		}
+
+		// Send the messages that are still queued before disconnecting
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := conn.Sender.Close(ctx); err != nil {
+			slog.Error("Error draining the message queue", "error", err.Error())
+		}
+
 		if err := conn.client.Disconnect(); err != nil {
 			slog.Error("Error disconnecting Twitch client", "error", err.Error())
 		} else {
@@ -0,0 +1,87 @@
+package invocation
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/esfands/retpaladinbot/internal/bot/args"
+	"github.com/esfands/retpaladinbot/internal/bot/sender"
+	"github.com/esfands/retpaladinbot/internal/global"
+	"github.com/esfands/retpaladinbot/internal/services"
+	"github.com/esfands/retpaladinbot/pkg/domain"
+	"github.com/gempir/go-twitch-irc/v4"
+)
+
+// Command is a default command which receives the whole invocation instead of only the user and arguments
+type Command interface {
+	domain.DefaultCommandInfo
+	Execute(inv *Invocation) error
+}
+
+// Invocation holds everything about a single command call
+type Invocation struct {
+	// Ctx is cancelled once the command runs past its deadline or the bot shuts down
+	Ctx     global.Context
+	Channel domain.Channel
+	User    twitch.User
+	// Trigger is the name or alias the command was called with
+	Trigger string
+	// Input is the raw text given after the trigger
+	Input string
+	// Args is the tokenized input
+	Args []string
+	// Values are the parsed arguments of commands which declare an args.Schema
+	Values args.Values
+	// Message is the chat message which triggered the command
+	Message twitch.PrivateMessage
+	// ThreadedReply is whether the command wants its responses sent as replies, see Respond
+	ThreadedReply bool
+
+	sender sender.Service
+}
+
+func New(ctx global.Context, channel domain.Channel, message twitch.PrivateMessage, trigger, input string, sender sender.Service) *Invocation {
+	return &Invocation{
+		Ctx:     ctx,
+		Channel: channel,
+		User:    message.User,
+		Trigger: trigger,
+		Input:   input,
+		Args:    args.Tokenize(input),
+		Message: message,
+		sender:  sender,
+	}
+}
+
+// Say sends a message to the channel the command was called in
+func (inv *Invocation) Say(message string) {
+	inv.sender.Say(inv.Channel.Name, message)
+}
+
+// Reply sends a message as a threaded reply to the message which triggered the command
+func (inv *Invocation) Reply(message string) {
+	inv.sender.Reply(inv.Channel.Name, inv.Message.ID, message)
+}
+
+// Respond sends the response of a command. It's sent as a threaded reply when both the command and the channel
+// allow it, if only the channel has it turned off the user is mentioned instead. Responses which already start
+// with a mention are addressed to someone else and are always sent as regular messages.
+func (inv *Invocation) Respond(message string) {
+	if strings.TrimSpace(message) == "" {
+		return
+	}
+
+	switch {
+	case !inv.ThreadedReply || strings.HasPrefix(message, "@"):
+		inv.Say(message)
+	case inv.Channel.ThreadedReplies:
+		inv.Reply(message)
+	default:
+		inv.Say(fmt.Sprintf("@%v, %v", inv.User.Name, message))
+	}
+}
+
+// Services returns the services in the crate
+func (inv *Invocation) Services() *services.Crate {
+	return inv.Ctx.Crate()
+}
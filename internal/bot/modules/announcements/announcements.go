@@ -0,0 +1,74 @@
+package announcements
+
+import (
+	"log/slog"
+
+	"github.com/esfands/retpaladinbot/internal/bot/modules"
+	"github.com/esfands/retpaladinbot/internal/bot/variables"
+	"github.com/esfands/retpaladinbot/pkg/domain"
+)
+
+// Module sends the scheduled announcements of the channels it's enabled in
+type Module struct {
+	modules.Base
+
+	variables variables.ServiceI
+	env       modules.Env
+}
+
+func NewAnnouncementsModule(variables variables.ServiceI) *Module {
+	return &Module{
+		variables: variables,
+	}
+}
+
+func (m *Module) Name() string {
+	return "announcements"
+}
+
+func (m *Module) Description() string {
+	return "Sends the scheduled announcements of the channel, they're managed with the announce command."
+}
+
+func (m *Module) Start(env modules.Env) error {
+	m.env = env
+	env.Ctx.Crate().Announcements.SetRunner(m.announce)
+	return nil
+}
+
+func (m *Module) Stop() error {
+	m.env.Ctx.Crate().Announcements.SetRunner(nil)
+	return nil
+}
+
+func (m *Module) announce(announcement domain.Announcement) {
+	var channel *modules.Channel
+	for _, c := range m.env.Channels() {
+		if c.ID == announcement.ChannelID {
+			channel = &c
+			break
+		}
+	}
+	if channel == nil {
+		return
+	}
+
+	if announcement.Condition != domain.StreamAlways {
+		live, err := m.env.IsLive(channel.ID)
+		if err != nil {
+			slog.Error("[announcements] Error getting most recent stream status", "channel", channel.Name, "error", err)
+			return
+		}
+
+		if !announcement.Condition.Allows(live) {
+			return
+		}
+	}
+
+	message := m.variables.ParseVariables(m.env.Ctx, variables.Scope{Channel: channel.Channel}, announcement.Message)
+	if message == "" {
+		return
+	}
+
+	m.env.Sender.Say(channel.Name, message)
+}
@@ -0,0 +1,155 @@
+package modules
+
+import (
+	"errors"
+	"log/slog"
+	"runtime/debug"
+
+	"github.com/esfands/retpaladinbot/internal/bot/channels"
+	"github.com/esfands/retpaladinbot/internal/bot/sender"
+	"github.com/esfands/retpaladinbot/internal/global"
+	"github.com/esfands/retpaladinbot/internal/services/events"
+	"github.com/esfands/retpaladinbot/internal/services/modulestore"
+)
+
+// ModuleManager runs the modules and hands them the messages and events of the channels they're enabled in,
+// whether a module is enabled and its settings are kept in the module store of the crate
+type ModuleManager struct {
+	gctx           global.Context
+	sender         sender.Service
+	channelManager *channels.ChannelManager
+	streams        *streams
+
+	modules []Module
+}
+
+func NewModuleManager(gctx global.Context, sender sender.Service, channelManager *channels.ChannelManager) (*ModuleManager, error) {
+	return &ModuleManager{
+		gctx:           gctx,
+		sender:         sender,
+		channelManager: channelManager,
+		streams:        newStreams(gctx),
+	}, nil
+}
+
+// Register adds a module, modules have to be registered before Start is called
+func (mm *ModuleManager) Register(module Module) {
+	mm.modules = append(mm.modules, module)
+
+	defaultOff, _ := module.(DefaultOff)
+	mm.gctx.Crate().Modules.Register(modulestore.Module{
+		Name:              module.Name(),
+		Description:       module.Description(),
+		Settings:          module.Settings(),
+		DisabledByDefault: defaultOff != nil && defaultOff.DisabledByDefault(),
+	})
+}
+
+// Start starts every module and subscribes them to the chat messages, USERNOTICEs and EventSub events of the event
+// bus, modules which fail to start are skipped
+func (mm *ModuleManager) Start() {
+	bus := mm.gctx.Crate().Events
+	events.Subscribe(bus, events.StreamOnline, "modules", func(event events.StreamOnlineEvent) {
+		mm.streams.set(event.ChannelID, true)
+	})
+	events.Subscribe(bus, events.StreamOffline, "modules", func(event events.StreamOfflineEvent) {
+		mm.streams.set(event.ChannelID, false)
+	})
+
+	started := mm.modules[:0]
+	for _, module := range mm.modules {
+		env := Env{
+			Ctx:    mm.gctx,
+			Sender: mm.sender,
+			Channels: func() []Channel {
+				return mm.enabledChannels(module)
+			},
+			streams: mm.streams,
+		}
+
+		if err := module.Start(env); err != nil {
+			slog.Error("Failed to start module", "module", module.Name(), "error", err.Error())
+			continue
+		}
+		started = append(started, module)
+	}
+	mm.modules = started
+
+	for _, module := range mm.modules {
+		mm.subscribe(module)
+	}
+}
+
+// subscribe hands a module the events of the channels it's enabled in, every module has queues of its own so a
+// slow module doesn't hold up the others
+func (mm *ModuleManager) subscribe(module Module) {
+	bus := mm.gctx.Crate().Events
+	events.Subscribe(bus, events.ChatMessage, module.Name(), func(event events.ChatMessageEvent) {
+		mm.dispatch(module, event.ChannelID, func(channel Channel) {
+			module.OnMessage(channel, event.Message)
+		})
+	})
+	events.Subscribe(bus, events.UserNotice, module.Name(), func(event events.UserNoticeEvent) {
+		mm.dispatch(module, event.ChannelID, func(channel Channel) {
+			module.OnUserNotice(channel, event.Message)
+		})
+	})
+	events.Subscribe(bus, events.EventSub, module.Name(), func(event events.Event) {
+		mm.dispatch(module, event.ChannelID, func(channel Channel) {
+			module.OnEvent(channel, event)
+		})
+	})
+}
+
+// Stop stops every module
+func (mm *ModuleManager) Stop() error {
+	var errs []error
+	for _, module := range mm.modules {
+		if err := module.Stop(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// dispatch calls a hook of a module if it's enabled in the channel
+func (mm *ModuleManager) dispatch(module Module, channelID string, hook func(channel Channel)) {
+	var c Channel
+	var ok bool
+	c.Channel, ok = mm.channelManager.GetByID(channelID)
+	if !ok || !c.Enabled {
+		return
+	}
+
+	state := mm.gctx.Crate().Modules.State(c.ID, module.Name())
+	if !state.Enabled {
+		return
+	}
+
+	c.Settings = state.Settings
+	call(module, func() {
+		hook(c)
+	})
+}
+
+func (mm *ModuleManager) enabledChannels(module Module) []Channel {
+	var enabled []Channel
+	for _, channel := range mm.channelManager.All() {
+		state := mm.gctx.Crate().Modules.State(channel.ID, module.Name())
+		if state.Enabled {
+			enabled = append(enabled, Channel{Channel: channel, Settings: state.Settings})
+		}
+	}
+	return enabled
+}
+
+// call runs a hook, a panicking module is logged instead of taking the bot down
+func call(module Module, hook func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			slog.Error("Module panicked", "module", module.Name(), "panic", r, "stack", string(debug.Stack()))
+		}
+	}()
+
+	hook()
+}
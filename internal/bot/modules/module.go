@@ -1,17 +1,100 @@
 package modules
 
 import (
-	goliverightnow "github.com/esfands/retpaladinbot/internal/bot/modules/go-live-right-now"
+	"strconv"
+
+	"github.com/esfands/retpaladinbot/internal/bot/sender"
 	"github.com/esfands/retpaladinbot/internal/global"
+	"github.com/esfands/retpaladinbot/internal/services/events"
+	"github.com/esfands/retpaladinbot/internal/services/modulestore"
+	"github.com/esfands/retpaladinbot/pkg/domain"
 	"github.com/gempir/go-twitch-irc/v4"
 )
 
-type ModuleManager struct {
-	GoLiveRightNow *goliverightnow.Module
+// Module is a feature of the bot which can be turned on and off per channel. The hooks are only called for
+// channels the module is enabled in, embed Base to only implement the ones that are needed.
+type Module interface {
+	Name() string
+	Description() string
+	// Settings are the values the module can be configured with in each channel
+	Settings() []modulestore.Setting
+	// Start is called once when the bot starts, before any of the hooks
+	Start(env Env) error
+	// Stop is called once when the bot shuts down
+	Stop() error
+	OnMessage(channel Channel, message twitch.PrivateMessage)
+	OnUserNotice(channel Channel, message twitch.UserNoticeMessage)
+	OnEvent(channel Channel, event events.Event)
+}
+
+// DefaultOff is implemented by modules which are off in a channel until they're turned on
+type DefaultOff interface {
+	DisabledByDefault() bool
+}
+
+// Env is what a module gets to work with when it starts
+type Env struct {
+	Ctx    global.Context
+	Sender sender.Service
+	// Channels returns the channels the module is enabled in
+	Channels func() []Channel
+
+	streams *streams
+}
+
+// IsLive reports whether the stream of a channel is live, channels that haven't streamed since they were added
+// have no stream status yet and count as offline
+func (e Env) IsLive(channelID string) (bool, error) {
+	return e.streams.isLive(channelID)
+}
+
+// Channel is a channel the module is enabled in along with the settings of the module in it
+type Channel struct {
+	domain.Channel
+	Settings Settings
+}
+
+// Settings are the settings of a module in a channel, settings which weren't changed have their default
+type Settings map[string]string
+
+// String returns a setting
+func (s Settings) String(name string) string {
+	return s[name]
 }
 
-func NewModuleManager(gctx global.Context, client *twitch.Client) (*ModuleManager, error) {
-	return &ModuleManager{
-		GoLiveRightNow: goliverightnow.NewGoLiveRightNowModule(gctx, client),
-	}, nil
+// Int returns a number setting, or 0 if it isn't a number
+func (s Settings) Int(name string) int {
+	value, _ := strconv.Atoi(s[name])
+	return value
 }
+
+// Bool returns whether a setting is on
+func (s Settings) Bool(name string) bool {
+	switch s[name] {
+	case "on", "true", "1", "yes":
+		return true
+	default:
+		return false
+	}
+}
+
+// Base implements every method of Module except Name and Description as no-ops
+type Base struct{}
+
+func (Base) Settings() []modulestore.Setting {
+	return nil
+}
+
+func (Base) Start(Env) error {
+	return nil
+}
+
+func (Base) Stop() error {
+	return nil
+}
+
+func (Base) OnMessage(Channel, twitch.PrivateMessage) {}
+
+func (Base) OnUserNotice(Channel, twitch.UserNoticeMessage) {}
+
+func (Base) OnEvent(Channel, events.Event) {}
@@ -0,0 +1,175 @@
+package raids
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/esfands/retpaladinbot/internal/bot/modules"
+	"github.com/esfands/retpaladinbot/internal/bot/variables"
+	"github.com/esfands/retpaladinbot/internal/services/cooldowns"
+	"github.com/esfands/retpaladinbot/internal/services/events"
+	"github.com/esfands/retpaladinbot/internal/services/modulestore"
+	"github.com/gempir/go-twitch-irc/v4"
+	"github.com/nicklaw5/helix/v2"
+)
+
+// raid is a raid on a channel, it's received both from chat and from EventSub
+type raid struct {
+	raiderID    string
+	raiderLogin string
+	raiderName  string
+	viewers     int
+}
+
+// Module welcomes raiders and gives them a Twitch shoutout
+type Module struct {
+	modules.Base
+
+	variables variables.ServiceI
+	env       modules.Env
+}
+
+func NewRaidsModule(variables variables.ServiceI) *Module {
+	return &Module{
+		variables: variables,
+	}
+}
+
+func (m *Module) Name() string {
+	return "raids"
+}
+
+func (m *Module) Description() string {
+	return "Welcomes raiders with what they last played and gives them a Twitch shoutout when the broadcaster is logged in."
+}
+
+func (m *Module) DisabledByDefault() bool {
+	return true
+}
+
+func (m *Module) Settings() []modulestore.Setting {
+	return []modulestore.Setting{
+		{
+			Name:        "message",
+			Description: "The welcome message, ${raider}, ${raider.login}, ${raider.game} and ${viewers} are the raid",
+			Default:     "Welcome raiders from ${raider}! They were last playing ${raider.game}, check them out at twitch.tv/${raider.login}",
+		},
+		{Name: "minviewers", Description: "Raids with fewer viewers aren't welcomed", Default: "1"},
+		{Name: "cooldown", Description: "Minutes before the same raider is welcomed again", Default: "60"},
+		{Name: "shoutout", Description: "Whether raiders get a Twitch shoutout, it needs the broadcaster to be logged in", Default: "on"},
+	}
+}
+
+func (m *Module) Start(env modules.Env) error {
+	m.env = env
+	return nil
+}
+
+func (m *Module) OnUserNotice(channel modules.Channel, message twitch.UserNoticeMessage) {
+	if message.MsgID != "raid" {
+		return
+	}
+
+	viewers, _ := strconv.Atoi(message.MsgParams["msg-param-viewerCount"])
+
+	// Looking up the raider shouldn't hold up the chat
+	go m.welcome(channel, raid{
+		raiderID:    message.User.ID,
+		raiderLogin: message.MsgParams["msg-param-login"],
+		raiderName:  message.MsgParams["msg-param-displayName"],
+		viewers:     viewers,
+	})
+}
+
+func (m *Module) OnEvent(channel modules.Channel, event events.Event) {
+	if event.Type != "channel.raid" {
+		return
+	}
+
+	var payload helix.EventSubChannelRaidEvent
+	if err := json.Unmarshal(event.Payload, &payload); err != nil {
+		slog.Error("[raids] Error unmarshalling the raid", "error", err)
+		return
+	}
+
+	m.welcome(channel, raid{
+		raiderID:    payload.FromBroadcasterUserID,
+		raiderLogin: payload.FromBroadcasterUserLogin,
+		raiderName:  payload.FromBroadcasterUserName,
+		viewers:     payload.Viewers,
+	})
+}
+
+// welcome posts the welcome message and sends the shoutout, a raid arrives both from chat and EventSub so the
+// cooldown of the raider also keeps it from being welcomed twice
+func (m *Module) welcome(channel modules.Channel, r raid) {
+	if r.raiderID == "" || r.viewers < channel.Settings.Int("minviewers") {
+		return
+	}
+
+	cooldown := time.Duration(channel.Settings.Int("cooldown")) * time.Minute
+	remaining, err := m.env.Ctx.Crate().Cooldowns.Acquire(m.env.Ctx, cooldowns.Key{
+		ChannelID: channel.ID,
+		Command:   "raids",
+		User:      r.raiderID,
+	}, max(cooldown, time.Minute))
+	if err != nil {
+		slog.Error("[raids] Error acquiring the raider cooldown", "channel", channel.Name, "error", err)
+		return
+	}
+	if remaining > 0 {
+		return
+	}
+
+	game := "something"
+	info, err := m.env.Ctx.Crate().Helix.Client().GetChannelInformation(&helix.GetChannelInformationParams{
+		BroadcasterIDs: []string{r.raiderID},
+	})
+	if err != nil {
+		slog.Error("[raids] Error getting the channel information of the raider", "raider", r.raiderLogin, "error", err)
+	} else if len(info.Data.Channels) > 0 && info.Data.Channels[0].GameName != "" {
+		game = info.Data.Channels[0].GameName
+	}
+
+	message := m.variables.ParseVariables(m.env.Ctx, variables.Scope{
+		Channel: channel.Channel,
+		Event: map[string]string{
+			"raider":       r.raiderName,
+			"raider.login": r.raiderLogin,
+			"raider.game":  game,
+			"viewers":      strconv.Itoa(r.viewers),
+		},
+	}, channel.Settings.String("message"))
+	if message != "" {
+		m.env.Sender.Say(channel.Name, message)
+	}
+
+	if channel.Settings.Bool("shoutout") {
+		m.shoutout(channel, r)
+	}
+}
+
+// shoutout sends a Twitch shoutout to the raider, it's only possible with the token the broadcaster connected
+func (m *Module) shoutout(channel modules.Channel, r raid) {
+	client, err := m.env.Ctx.Crate().Helix.BroadcasterClient(m.env.Ctx, channel.ID, "moderator:manage:shoutouts")
+	if err != nil {
+		slog.Debug("[raids] Skipping the shoutout", "channel", channel.Name, "reason", err)
+		return
+	}
+
+	res, err := client.SendShoutout(&helix.SendShoutoutParams{
+		FromBroadcasterID: channel.ID,
+		ToBroadcasterID:   r.raiderID,
+		ModeratorID:       channel.ID,
+	})
+	if err != nil {
+		slog.Error("[raids] Error sending the shoutout", "channel", channel.Name, "raider", r.raiderLogin, "error", err)
+		return
+	}
+	if res.StatusCode != http.StatusNoContent {
+		slog.Warn("[raids] Twitch didn't accept the shoutout", "channel", channel.Name, "raider", r.raiderLogin, "status", res.StatusCode, "error", res.ErrorMessage)
+	}
+}
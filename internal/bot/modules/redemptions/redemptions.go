@@ -0,0 +1,206 @@
+package redemptions
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/esfands/retpaladinbot/internal/bot/modules"
+	"github.com/esfands/retpaladinbot/internal/bot/responses"
+	"github.com/esfands/retpaladinbot/internal/bot/variables"
+	"github.com/esfands/retpaladinbot/internal/cmdmanager"
+	"github.com/esfands/retpaladinbot/internal/services/events"
+	"github.com/esfands/retpaladinbot/internal/services/rewards"
+	"github.com/esfands/retpaladinbot/pkg/domain"
+	"github.com/gempir/go-twitch-irc/v4"
+	"github.com/nicklaw5/helix/v2"
+)
+
+// Module runs the actions attached to the channel point rewards of a channel when they're redeemed
+type Module struct {
+	modules.Base
+
+	variables variables.ServiceI
+	responses *responses.Picker
+	env       modules.Env
+}
+
+func NewRedemptionsModule(variables variables.ServiceI, responses *responses.Picker) *Module {
+	return &Module{
+		variables: variables,
+		responses: responses,
+	}
+}
+
+func (m *Module) Name() string {
+	return "redemptions"
+}
+
+func (m *Module) Description() string {
+	return "Runs the actions of channel point rewards: posting a message, incrementing a counter, running a custom command, queueing the redeemer or timing them out."
+}
+
+func (m *Module) Start(env modules.Env) error {
+	m.env = env
+	return nil
+}
+
+func (m *Module) OnEvent(channel modules.Channel, event events.Event) {
+	if event.Type != helix.EventSubTypeChannelPointsCustomRewardRedemptionAdd {
+		return
+	}
+
+	var redemption helix.EventSubChannelPointsCustomRewardRedemptionEvent
+	if err := json.Unmarshal(event.Payload, &redemption); err != nil {
+		slog.Error("[redemptions] Error unmarshalling the redemption", "error", err)
+		return
+	}
+
+	action, err := m.env.Ctx.Crate().Rewards.Action(m.env.Ctx, channel.ID, redemption.Reward.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return
+	} else if err != nil {
+		slog.Error("[redemptions] Error getting the action of the reward", "channel", channel.Name, "reward", redemption.Reward.Title, "error", err)
+		return
+	}
+	if !action.Enabled {
+		return
+	}
+
+	user := twitch.User{ID: redemption.UserID, Name: redemption.UserLogin, DisplayName: redemption.UserName}
+	values := map[string]string{
+		"reward": redemption.Reward.Title,
+		"count":  strconv.Itoa(redemption.Reward.Cost),
+		"text":   redemption.UserInput,
+	}
+
+	err = m.run(channel, action, user, redemption, values)
+	if err != nil {
+		slog.Warn("[redemptions] Error running the action of the reward", "channel", channel.Name, "reward", redemption.Reward.Title, "action", action.Action, "user", user.Name, "error", err)
+	} else if action.Template != "" {
+		m.say(channel, user, values, action.Template)
+	}
+
+	m.complete(channel, action, redemption, err == nil)
+}
+
+// run does what the action of a reward says, the values of the redemption the template sees are added to
+func (m *Module) run(channel modules.Channel, action domain.RewardAction, user twitch.User, redemption helix.EventSubChannelPointsCustomRewardRedemptionEvent, values map[string]string) error {
+	ctx := m.env.Ctx
+	crate := ctx.Crate()
+
+	switch action.Action {
+	case domain.RewardActionMessage:
+		// The template is the message, it's posted once the action succeeded like the template of every action
+		return nil
+
+	case domain.RewardActionCounter:
+		_, err := crate.Turso.Queries().AddToCounter(ctx, channel.ID, action.Target, action.Amount)
+		return err
+
+	case domain.RewardActionCommand:
+		stored, err := crate.Turso.Queries().GetCustomCommand(ctx, channel.ID, action.Target)
+		if err != nil {
+			return fmt.Errorf("getting the custom command %v: %w", action.Target, err)
+		}
+		command, err := cmdmanager.ToCustomCommand(stored)
+		if err != nil {
+			return err
+		}
+		if !command.Enabled {
+			return fmt.Errorf("the custom command %v is disabled", command.Name)
+		}
+
+		response := m.variables.ParseVariables(ctx, variables.Scope{
+			Channel: channel.Channel,
+			User:    user,
+			Command: command.Name,
+			Args:    strings.Fields(redemption.UserInput),
+			Event:   values,
+		}, m.responses.Pick(command))
+		if response != "" {
+			m.env.Sender.Say(channel.Name, response)
+		}
+
+		if err := crate.Turso.Queries().IncrementCustomCommandUsageCount(ctx, channel.ID, command.Name); err != nil {
+			slog.Error("[redemptions] Error incrementing the usage count of the custom command", "command", command.Name, "error", err)
+		}
+		return nil
+
+	case domain.RewardActionQueue:
+		position, err := crate.Rewards.Enqueue(ctx, channel.ID, action.Target, domain.QueueEntry{
+			UserID:    user.ID,
+			UserLogin: user.Name,
+			UserName:  user.DisplayName,
+			Input:     redemption.UserInput,
+		})
+		if err != nil {
+			return err
+		}
+		values["position"] = strconv.Itoa(position)
+		return nil
+
+	case domain.RewardActionTimeout:
+		// Only the token of the broadcaster is a moderator of their channel
+		client, err := crate.Helix.BroadcasterClient(ctx, channel.ID, "moderator:manage:banned_users")
+		if err != nil {
+			return err
+		}
+
+		res, err := client.BanUser(&helix.BanUserParams{
+			BroadcasterID: channel.ID,
+			ModeratorId:   channel.ID,
+			Body: helix.BanUserRequestBody{
+				Duration: action.Amount,
+				Reason:   "Redeemed " + redemption.Reward.Title,
+				UserId:   user.ID,
+			},
+		})
+		if err != nil {
+			return err
+		}
+		if res.StatusCode != http.StatusOK {
+			return fmt.Errorf("twitch responded with %d: %v", res.StatusCode, res.ErrorMessage)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("unknown action %q", action.Action)
+}
+
+// complete marks the redemption as fulfilled when the action succeeded and cancels it otherwise, so the redeemer
+// gets their points back
+func (m *Module) complete(channel modules.Channel, action domain.RewardAction, redemption helix.EventSubChannelPointsCustomRewardRedemptionEvent, succeeded bool) {
+	// Rewards which skip the request queue are fulfilled right away
+	if !action.Complete || redemption.Status != "unfulfilled" {
+		return
+	}
+
+	status := rewards.StatusFulfilled
+	if !succeeded {
+		status = rewards.StatusCanceled
+	}
+
+	err := m.env.Ctx.Crate().Rewards.Complete(channel.ID, redemption.Reward.ID, redemption.ID, status)
+	if err != nil {
+		slog.Error("[redemptions] Error completing the redemption", "channel", channel.Name, "reward", redemption.Reward.Title, "status", status, "error", err)
+	}
+}
+
+func (m *Module) say(channel modules.Channel, user twitch.User, values map[string]string, template string) {
+	text := m.variables.ParseVariables(m.env.Ctx, variables.Scope{
+		Channel: channel.Channel,
+		User:    user,
+		Event:   values,
+	}, template)
+	if text == "" {
+		return
+	}
+
+	m.env.Sender.Say(channel.Name, text)
+}
@@ -0,0 +1,58 @@
+package modules
+
+import (
+	"database/sql"
+	"errors"
+	"sync"
+
+	"github.com/esfands/retpaladinbot/internal/global"
+)
+
+// streams keeps whether the streams of the channels are live, it's kept up to date by the stream events of the
+// event bus and only asks the database about channels it hasn't heard of yet
+type streams struct {
+	gctx global.Context
+
+	mu   sync.RWMutex
+	live map[string]bool
+}
+
+func newStreams(gctx global.Context) *streams {
+	return &streams{
+		gctx: gctx,
+		live: make(map[string]bool),
+	}
+}
+
+func (s *streams) set(channelID string, live bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.live[channelID] = live
+}
+
+func (s *streams) isLive(channelID string) (bool, error) {
+	s.mu.RLock()
+	live, ok := s.live[channelID]
+	s.mu.RUnlock()
+	if ok {
+		return live, nil
+	}
+
+	streamStatus, err := s.gctx.Crate().Turso.Queries().GetMostRecentStreamStatus(s.gctx, channelID)
+	if errors.Is(err, sql.ErrNoRows) {
+		streamStatus.Live = false
+	} else if err != nil {
+		return false, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// A stream event which arrived in the meantime is newer than what was read
+	if live, ok := s.live[channelID]; ok {
+		return live, nil
+	}
+	s.live[channelID] = streamStatus.Live
+	return streamStatus.Live, nil
+}
@@ -0,0 +1,165 @@
+package timers
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/esfands/retpaladinbot/internal/bot/modules"
+	"github.com/esfands/retpaladinbot/internal/bot/variables"
+	"github.com/esfands/retpaladinbot/pkg/domain"
+	"github.com/gempir/go-twitch-irc/v4"
+)
+
+// tickTag identifies the job which checks the timers on the shared scheduler
+const tickTag = "timers"
+
+// state is what a timer remembers between its messages, it's kept in memory so it starts over on restart
+type state struct {
+	lastPosted time.Time
+	// linesAt is the line count of the channel when the last message was posted
+	linesAt int
+	// next is the index of the next message in the rotation
+	next int
+}
+
+// Module posts the timers of a channel, it counts the chat lines to know whether the chat is active
+type Module struct {
+	modules.Base
+
+	variables variables.ServiceI
+	env       modules.Env
+
+	mu     sync.Mutex
+	lines  map[string]int
+	states map[int]*state
+}
+
+func NewTimersModule(variables variables.ServiceI) *Module {
+	return &Module{
+		variables: variables,
+		lines:     make(map[string]int),
+		states:    make(map[int]*state),
+	}
+}
+
+func (m *Module) Name() string {
+	return "timers"
+}
+
+func (m *Module) Description() string {
+	return "Posts the timers of the channel in rotation while the chat is active, they're managed with the timer command."
+}
+
+func (m *Module) Start(env modules.Env) error {
+	m.env = env
+	return env.Ctx.Crate().Scheduler.Every(tickTag, time.Minute, m.tick)
+}
+
+func (m *Module) Stop() error {
+	m.env.Ctx.Crate().Scheduler.Remove(tickTag)
+	return nil
+}
+
+func (m *Module) OnMessage(channel modules.Channel, _ twitch.PrivateMessage) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.lines[channel.ID]++
+}
+
+// tick posts the next message of every timer which is due
+func (m *Module) tick() {
+	channels := make(map[string]modules.Channel)
+	for _, channel := range m.env.Channels() {
+		channels[channel.ID] = channel
+	}
+
+	timers, err := m.env.Ctx.Crate().Timers.All(m.env.Ctx)
+	if err != nil {
+		slog.Error("[timers] Error getting the timers", "error", err)
+		return
+	}
+
+	// The stream status is only looked up once per channel and tick
+	live := make(map[string]bool)
+	now := time.Now()
+	seen := make(map[int]bool, len(timers))
+
+	for _, timer := range timers {
+		seen[timer.ID] = true
+
+		channel, ok := channels[timer.ChannelID]
+		if !ok || !timer.Enabled || len(timer.Messages) == 0 || !m.due(timer, now) {
+			continue
+		}
+
+		if timer.Condition != domain.StreamAlways {
+			isLive, ok := live[channel.ID]
+			if !ok {
+				isLive, err = m.env.IsLive(channel.ID)
+				if err != nil {
+					slog.Error("[timers] Error getting most recent stream status", "channel", channel.Name, "error", err)
+					continue
+				}
+				live[channel.ID] = isLive
+			}
+
+			if !timer.Condition.Allows(isLive) {
+				continue
+			}
+		}
+
+		message := m.variables.ParseVariables(m.env.Ctx, variables.Scope{Channel: channel.Channel}, m.advance(timer, now))
+		if message == "" {
+			continue
+		}
+
+		m.env.Sender.Say(channel.Name, message)
+	}
+
+	m.forget(seen)
+}
+
+// due reports whether both the interval and the chat lines of a timer have passed since its last message, timers
+// which weren't seen before wait for a full interval first
+func (m *Module) due(timer domain.Timer, now time.Time) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	st, ok := m.states[timer.ID]
+	if !ok {
+		m.states[timer.ID] = &state{lastPosted: now, linesAt: m.lines[timer.ChannelID]}
+		return false
+	}
+
+	interval := time.Duration(timer.Interval) * time.Minute
+	return now.Sub(st.lastPosted) >= interval && m.lines[timer.ChannelID]-st.linesAt >= timer.MinLines
+}
+
+// advance marks a timer as posted and returns the message which is next in the rotation
+func (m *Module) advance(timer domain.Timer, now time.Time) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	st := m.states[timer.ID]
+	message := timer.Messages[st.next%len(timer.Messages)]
+
+	st.next = (st.next + 1) % len(timer.Messages)
+	st.lastPosted = now
+	st.linesAt = m.lines[timer.ChannelID]
+
+	return message
+}
+
+// forget drops the state of timers which were deleted
+func (m *Module) forget(seen map[int]bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for id := range m.states {
+		if !seen[id] {
+			delete(m.states, id)
+		}
+	}
+}
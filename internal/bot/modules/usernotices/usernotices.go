@@ -0,0 +1,320 @@
+package usernotices
+
+import (
+	"encoding/json"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/esfands/retpaladinbot/internal/bot/modules"
+	"github.com/esfands/retpaladinbot/internal/bot/variables"
+	"github.com/esfands/retpaladinbot/internal/services/events"
+	"github.com/esfands/retpaladinbot/internal/services/eventsub"
+	"github.com/esfands/retpaladinbot/internal/services/usernotices"
+	"github.com/gempir/go-twitch-irc/v4"
+	"github.com/nicklaw5/helix/v2"
+)
+
+// massGiftTTL is how long the gifts of a mass gift are waited for, Twitch sends them right after the mass gift
+const massGiftTTL = time.Minute
+
+// massGift is a mass gift whose single gifts are still coming in
+type massGift struct {
+	remaining int
+	expires   time.Time
+}
+
+// Module responds to chat events like subs and raids with the responses of the channel
+type Module struct {
+	modules.Base
+
+	variables variables.ServiceI
+	env       modules.Env
+
+	mu        sync.Mutex
+	massGifts map[string]*massGift
+	// hypeTrainLevels are the levels the hype trains of the channels were last responded to at
+	hypeTrainLevels map[string]string
+}
+
+func NewUserNoticesModule(variables variables.ServiceI) *Module {
+	return &Module{
+		variables:       variables,
+		massGifts:       make(map[string]*massGift),
+		hypeTrainLevels: make(map[string]string),
+	}
+}
+
+func (m *Module) Name() string {
+	return "usernotices"
+}
+
+func (m *Module) Description() string {
+	return "Responds to subs, gifts, raids, follows, cheers and other chat events, the responses are managed with the notice command."
+}
+
+func (m *Module) Start(env modules.Env) error {
+	m.env = env
+	return nil
+}
+
+func (m *Module) OnUserNotice(channel modules.Channel, message twitch.UserNoticeMessage) {
+	t, ok := usernotices.GetType(message.MsgID)
+	if !ok || t.EventSub != "" {
+		slog.Debug("[usernotices] Ignoring unknown chat event", "type", message.MsgID)
+		return
+	}
+
+	// A mass gift is responded to once, the single gifts which follow it are part of that response
+	switch message.MsgID {
+	case "submysterygift":
+		m.startMassGift(message)
+	case "subgift":
+		if m.partOfMassGift(message) {
+			return
+		}
+	}
+
+	m.respond(channel, t, message.User, eventValues(message))
+}
+
+func (m *Module) OnEvent(channel modules.Channel, event events.Event) {
+	t, ok := usernotices.GetEventSubType(event.Type)
+	if !ok {
+		return
+	}
+
+	user, values, err := eventSubValues(event)
+	if err != nil {
+		slog.Error("[usernotices] Error unmarshalling the event", "type", event.Type, "error", err)
+		return
+	}
+
+	if !m.trackHypeTrain(channel.ID, event.Type, values["count"]) {
+		return
+	}
+
+	m.respond(channel, t, user, values)
+}
+
+// respond sends the response of the channel to an event of a type
+func (m *Module) respond(channel modules.Channel, t usernotices.Type, user twitch.User, event map[string]string) {
+	response, err := m.env.Ctx.Crate().UserNotices.Get(m.env.Ctx, channel.ID, t.Name)
+	if err != nil {
+		slog.Error("[usernotices] Error getting the response", "channel", channel.Name, "type", t.Name, "error", err)
+		return
+	}
+	if !response.Enabled || response.Template == "" {
+		return
+	}
+
+	if t.Amount != "" && response.Threshold > 0 {
+		amount, _ := strconv.Atoi(event[t.Amount])
+		if amount < response.Threshold {
+			return
+		}
+	}
+
+	text := m.variables.ParseVariables(m.env.Ctx, variables.Scope{
+		Channel: channel.Channel,
+		User:    user,
+		Event:   event,
+	}, response.Template)
+	if text == "" {
+		return
+	}
+
+	m.env.Sender.Say(channel.Name, text)
+}
+
+// trackHypeTrain keeps the level of the hype train of a channel and reports whether an event should be responded to,
+// progress is sent for every contribution so a level is only responded to once
+func (m *Module) trackHypeTrain(channelID, eventType, level string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	switch eventType {
+	case helix.EventSubTypeHypeTrainBegin:
+		m.hypeTrainLevels[channelID] = level
+	case helix.EventSubTypeHypeTrainProgress:
+		if m.hypeTrainLevels[channelID] == level {
+			return false
+		}
+		m.hypeTrainLevels[channelID] = level
+	case helix.EventSubTypeHypeTrainEnd:
+		delete(m.hypeTrainLevels, channelID)
+	}
+	return true
+}
+
+func (m *Module) startMassGift(message twitch.UserNoticeMessage) {
+	id := giftID(message)
+	count, _ := strconv.Atoi(message.MsgParams["msg-param-mass-gift-count"])
+	if id == "" || count == 0 {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	for key, gift := range m.massGifts {
+		if now.After(gift.expires) {
+			delete(m.massGifts, key)
+		}
+	}
+
+	m.massGifts[id] = &massGift{remaining: count, expires: now.Add(massGiftTTL)}
+}
+
+// partOfMassGift reports whether a single gift belongs to a mass gift which was already responded to
+func (m *Module) partOfMassGift(message twitch.UserNoticeMessage) bool {
+	id := giftID(message)
+	if id == "" {
+		return false
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	gift, ok := m.massGifts[id]
+	if !ok || time.Now().After(gift.expires) {
+		return false
+	}
+
+	gift.remaining--
+	if gift.remaining <= 0 {
+		delete(m.massGifts, id)
+	}
+	return true
+}
+
+// giftID returns the ID the gifts of a mass gift share with it
+func giftID(message twitch.UserNoticeMessage) string {
+	if id := message.MsgParams["msg-param-community-gift-id"]; id != "" {
+		return message.RoomID + ":" + id
+	}
+	if id := message.MsgParams["msg-param-origin-id"]; id != "" {
+		return message.RoomID + ":" + id
+	}
+	return ""
+}
+
+// eventValues returns the values of a chat event which the event variables are filled in with
+func eventValues(message twitch.UserNoticeMessage) map[string]string {
+	params := message.MsgParams
+	event := map[string]string{
+		"notice": message.SystemMsg,
+		"text":   message.Message,
+		"tier":   tier(params["msg-param-sub-plan"]),
+	}
+
+	switch message.MsgID {
+	case "sub", "resub":
+		event["months"] = params["msg-param-cumulative-months"]
+	case "subgift":
+		event["gifter"] = message.User.DisplayName
+		event["recipient"] = params["msg-param-recipient-display-name"]
+		event["months"] = params["msg-param-gift-months"]
+		event["count"] = "1"
+	case "submysterygift":
+		event["gifter"] = message.User.DisplayName
+		event["count"] = params["msg-param-mass-gift-count"]
+	case "raid":
+		event["viewers"] = params["msg-param-viewerCount"]
+	case "bitsbadgetier":
+		event["count"] = params["msg-param-threshold"]
+	case "giftpaidupgrade":
+		event["gifter"] = params["msg-param-sender-name"]
+		if event["gifter"] == "" {
+			event["gifter"] = "an anonymous gifter"
+		}
+	case "viewermilestone":
+		event["count"] = params["msg-param-value"]
+	}
+
+	return event
+}
+
+func tier(plan string) string {
+	switch plan {
+	case "Prime":
+		return "Prime"
+	case "2000":
+		return "2"
+	case "3000":
+		return "3"
+	case "":
+		return ""
+	default:
+		return "1"
+	}
+}
+
+// eventSubValues returns who caused an EventSub event and the values the event variables are filled in with
+func eventSubValues(event events.Event) (twitch.User, map[string]string, error) {
+	switch event.Type {
+	case helix.EventSubTypeChannelFollow:
+		var payload helix.EventSubChannelFollowEvent
+		err := json.Unmarshal(event.Payload, &payload)
+		return eventUser(payload.UserID, payload.UserLogin, payload.UserName), map[string]string{}, err
+
+	case helix.EventSubTypeChannelCheer:
+		var payload helix.EventSubChannelCheerEvent
+		err := json.Unmarshal(event.Payload, &payload)
+		user := eventUser(payload.UserID, payload.UserLogin, payload.UserName)
+		if payload.IsAnonymous {
+			// Twitch shows anonymous cheers as coming from this account
+			user = eventUser("", "ananonymouscheerer", "AnAnonymousCheerer")
+		}
+		return user, map[string]string{
+			"count": strconv.Itoa(payload.Bits),
+			"text":  payload.Message,
+		}, err
+
+	case helix.EventSubTypeChannelBan:
+		var payload helix.EventSubChannelBanEvent
+		err := json.Unmarshal(event.Payload, &payload)
+		return eventUser(payload.UserID, payload.UserLogin, payload.UserName), map[string]string{
+			"text": payload.Reason,
+		}, err
+
+	case helix.EventSubTypeChannelPointsCustomRewardRedemptionAdd:
+		var payload helix.EventSubChannelPointsCustomRewardRedemptionEvent
+		err := json.Unmarshal(event.Payload, &payload)
+		return eventUser(payload.UserID, payload.UserLogin, payload.UserName), map[string]string{
+			"reward": payload.Reward.Title,
+			"count":  strconv.Itoa(payload.Reward.Cost),
+			"text":   payload.UserInput,
+		}, err
+
+	case helix.EventSubTypeHypeTrainBegin:
+		var payload helix.EventSubHypeTrainBeginEvent
+		err := json.Unmarshal(event.Payload, &payload)
+		return twitch.User{}, map[string]string{"count": "1"}, err
+
+	case helix.EventSubTypeHypeTrainProgress:
+		var payload helix.EventSubHypeTrainProgressEvent
+		err := json.Unmarshal(event.Payload, &payload)
+		return twitch.User{}, map[string]string{"count": strconv.Itoa(payload.Level)}, err
+
+	case helix.EventSubTypeHypeTrainEnd:
+		var payload helix.EventSubHypeTrainEndEvent
+		err := json.Unmarshal(event.Payload, &payload)
+		return twitch.User{}, map[string]string{"count": strconv.Itoa(payload.Level)}, err
+
+	case eventsub.TypeAdBreakBegin:
+		var payload eventsub.AdBreakBeginEvent
+		err := json.Unmarshal(event.Payload, &payload)
+		return eventUser(payload.RequesterUserID, payload.RequesterUserLogin, payload.RequesterUserName), map[string]string{
+			"count": strconv.Itoa(payload.DurationSeconds),
+		}, err
+	}
+
+	return twitch.User{}, map[string]string{}, nil
+}
+
+func eventUser(id, login, name string) twitch.User {
+	return twitch.User{ID: id, Name: login, DisplayName: name}
+}
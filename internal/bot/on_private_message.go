@@ -3,21 +3,36 @@ package bot
 import (
 	"fmt"
 	"log/slog"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/esfands/retpaladinbot/internal/bot/commands"
+	"github.com/esfands/retpaladinbot/internal/bot/invocation"
+	"github.com/esfands/retpaladinbot/internal/bot/pipeline"
+	"github.com/esfands/retpaladinbot/internal/bot/responses"
 	"github.com/esfands/retpaladinbot/internal/bot/variables"
+	"github.com/esfands/retpaladinbot/internal/cmdmanager"
 	"github.com/esfands/retpaladinbot/internal/db"
 	"github.com/esfands/retpaladinbot/internal/global"
+	"github.com/esfands/retpaladinbot/internal/services/events"
 	"github.com/esfands/retpaladinbot/pkg/domain"
-	"github.com/esfands/retpaladinbot/pkg/utils"
 	"github.com/gempir/go-twitch-irc/v4"
 )
 
+// commandTimeout is how long a command may run before its context is cancelled
+const commandTimeout = 15 * time.Second
+
 func (conn *Connection) OnPrivateMessage(gctx global.Context, message twitch.PrivateMessage, commandManager *commands.CommandManager, variables variables.ServiceI) {
 	slog.Debug(fmt.Sprintf("[%v] %v: %v", message.Channel, message.User.DisplayName, message.Message))
 
+	channel, ok := conn.ChannelManager.Get(message.Channel)
+	if !ok {
+		slog.Warn("Received a message from an unknown channel", "channel", message.Channel)
+		return
+	}
+
 	stringID, err := strconv.Atoi(message.User.ID)
 	if err != nil {
 		slog.Error(err.Error())
@@ -31,38 +46,16 @@ func (conn *Connection) OnPrivateMessage(gctx global.Context, message twitch.Pri
 		DisplayName: message.User.DisplayName,
 	})
 
-	response, err := handleCommand(gctx, variables, commandManager, message.User, message.Message)
-	if err != nil {
-		slog.Error(err.Error())
-		conn.client.Say(message.Channel, fmt.Sprintf("Something went wrong... error: %v", err.Error()))
-		return
-	}
-
-	conn.client.Say(message.Channel, response)
-}
+	events.Publish(gctx.Crate().Events, events.ChatMessage, events.ChatMessageEvent{
+		ChannelID: channel.ID,
+		Message:   message,
+	})
 
-// Map Twitch badges to domain permissions
-var badgeToPermission = map[string]domain.Permission{
-	"broadcaster": domain.PermissionBroadcaster,
-	"moderator":   domain.PermissionModerator,
-	"vip":         domain.PermissionVIP,
-}
-
-func isUserPermitted(user twitch.User, requiredPermissions []domain.Permission) bool {
-	for badge := range user.Badges {
-		if permission, exists := badgeToPermission[badge]; exists {
-			for _, requiredPermission := range requiredPermissions {
-				if permission == requiredPermission {
-					return true
-				}
-			}
-		}
-	}
-	return false
+	conn.handleCommand(gctx, variables, commandManager, channel, message)
 }
 
 // Check if the command name or alias matches the input
-func isCommandMatch(input string, command domain.DefaultCommand) bool {
+func isCommandMatch(input string, command domain.DefaultCommandInfo) bool {
 	if input == command.Name() {
 		return true
 	}
@@ -74,95 +67,62 @@ func isCommandMatch(input string, command domain.DefaultCommand) bool {
 	return false
 }
 
-// Execute command with permission and cooldown checks
-func executeCommand(gctx global.Context, user twitch.User, context []string, command domain.DefaultCommand) (string, error) {
-	// Allow execution if the command has no required permissions
-	if len(command.Permissions()) > 0 && !isUserPermitted(user, command.Permissions()) {
-		return "", nil
-	}
-
-	response, err := command.Code(user, context[1:])
+// findCommand looks up the default or custom command a trigger refers to, default commands get the overrides of the channel
+func findCommand(ctx global.Context, commandManager *commands.CommandManager, variables variables.ServiceI, picker *responses.Picker, channel domain.Channel, trigger string) (pipeline.Command, error) {
+	overrides, err := cmdmanager.GetOverrides(ctx, ctx.Crate().Turso.Queries(), channel.ID)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	if !utils.CooldownCanContinue(user, strings.ToLower(context[0]), command.UserCooldown(), command.GlobalCooldown()) {
-		return "", nil
+	for _, dc := range commandManager.DefaultCommands {
+		override := overrides[dc.Name()]
+		if isCommandMatch(trigger, dc) || slices.Contains(override.Aliases, trigger) {
+			return pipeline.DefaultCommand{DefaultCommandInfo: dc, Override: override}, nil
+		}
 	}
 
-	// Update usage
-	err = gctx.Crate().Turso.Queries().IncrementDefaultCommandUsageCount(gctx, command.Name())
-	if err != nil {
-		slog.Error("Failed to update default command usage", "error", err.Error())
+	if cc, ok := commandManager.FindCustomCommand(channel.ID, trigger); ok {
+		return pipeline.CustomCommand{Command: cc, Variables: variables, Responses: picker}, nil
 	}
 
-	slog.Info("Command executed", "command", command.Name(), "user", user.DisplayName, "channel", gctx.Config().Twitch.Bot.Channel)
-
-	return response, nil
+	return nil, nil
 }
 
-func handleCommand(gctx global.Context, variables variables.ServiceI, commandManager *commands.CommandManager, user twitch.User, msg string) (string, error) {
-	if !strings.HasPrefix(msg, gctx.Config().Twitch.Bot.Prefix) {
-		return "", nil
+func (conn *Connection) handleCommand(gctx global.Context, variables variables.ServiceI, commandManager *commands.CommandManager, channel domain.Channel, message twitch.PrivateMessage) {
+	msg := message.Message
+	if !strings.HasPrefix(msg, channel.Prefix) {
+		return
 	}
 
-	msg = strings.TrimPrefix(msg, gctx.Config().Twitch.Bot.Prefix)
-	context := strings.Split(msg, " ")
+	msg = strings.TrimSpace(strings.TrimPrefix(msg, channel.Prefix))
+	trigger, input, _ := strings.Cut(msg, " ")
+	trigger = strings.ToLower(trigger)
 
-	for _, dc := range commandManager.DefaultCommands {
-		if isCommandMatch(strings.ToLower(context[0]), dc) {
-			slog.Info("Command match found", "command", dc.Name())
-
-			streamStatus, err := gctx.Crate().Turso.Queries().GetMostRecentStreamStatus(gctx)
-			if err != nil {
-				slog.Error("Failed to get most recent stream status", "error", err.Error())
-				return "", err
-			}
-			slog.Info("Stream status", "live", streamStatus.Live)
-
-			if dc.Conditions().EnabledOffline && !streamStatus.Live {
-				// Command can run offline, and stream is offline
-				return executeCommand(gctx, user, context, dc)
-			} else if dc.Conditions().EnabledOnline && streamStatus.Live {
-				// Command can run online, and stream is live
-				return executeCommand(gctx, user, context, dc)
-			} else {
-				// Command does not meet the conditions to run
-				slog.Info("Command cannot run in the current stream status", "command", dc.Name(), "live", streamStatus.Live)
-				return "", nil
-			}
-		}
+	command, err := findCommand(gctx, commandManager, variables, conn.Responses, channel, trigger)
+	if err != nil {
+		slog.Error("Failed to find command", "trigger", trigger, "error", err.Error())
+		return
+	} else if command == nil {
+		return
 	}
 
-	// Check for custom commands
-	for _, cc := range commandManager.CustomCommands {
-		if strings.ToLower(context[0]) == cc.Name {
-			// Bypass the cooldown for broadcaster and moderator
-			if user.Badges["broadcaster"] == 1 || user.Badges["moderator"] == 1 {
-				err := gctx.Crate().Turso.Queries().IncrementCustomCommandUsageCount(gctx, strings.ToLower(cc.Name))
-				if err != nil {
-					slog.Error("Failed to update custom command usage", "error", err.Error())
-				}
-
-				parsedResponse := variables.ParseVariables(gctx, user, strings.Split(msg, " "), strings.Split(cc.Response, " "))
-
-				return parsedResponse, nil
-			}
+	slog.Info("Command match found", "command", command.Name(), "channel", channel.Name)
 
-			if !utils.CooldownCanContinue(user, strings.ToLower(cc.Name), 30, 10) {
-				return "", nil
-			}
+	ctx, cancel := global.WithTimeout(gctx, commandTimeout)
+	defer cancel()
 
-			err := gctx.Crate().Turso.Queries().IncrementCustomCommandUsageCount(gctx, strings.ToLower(cc.Name))
-			if err != nil {
-				slog.Error("Failed to update custom command usage", "error", err.Error())
-			}
+	inv := invocation.New(ctx, channel, message, trigger, strings.TrimSpace(input), conn.Sender)
+	inv.ThreadedReply = command.ThreadedReply()
 
-			parsedResponse := variables.ParseVariables(gctx, user, strings.Split(msg, " "), strings.Split(cc.Response, " "))
-
-			return parsedResponse, nil
-		}
+	response, err := conn.Pipeline.Execute(&pipeline.Request{
+		Invocation: inv,
+		Command:    command,
+	})
+	if err != nil {
+		slog.Error(err.Error())
+		inv.Respond(fmt.Sprintf("Something went wrong... error: %v", err.Error()))
+		return
 	}
 
-	return "", nil
+	inv.Respond(response)
 }
@@ -0,0 +1,18 @@
+package bot
+
+import (
+	"github.com/gempir/go-twitch-irc/v4"
+)
+
+// OnUserStateMessage keeps track of whether the bot is a moderator or VIP in a channel, Twitch sends the
+// bot's badges in a USERSTATE message after joining a channel and after every message it sends
+func (conn *Connection) OnUserStateMessage(message twitch.UserStateMessage) {
+	elevated := false
+	for _, badge := range []string{"broadcaster", "moderator", "vip"} {
+		if _, ok := message.User.Badges[badge]; ok {
+			elevated = true
+		}
+	}
+
+	conn.Sender.SetElevated(message.Channel, elevated)
+}
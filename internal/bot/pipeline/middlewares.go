@@ -0,0 +1,333 @@
+package pipeline
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/esfands/retpaladinbot/internal/bot/args"
+	"github.com/esfands/retpaladinbot/internal/services/cooldowns"
+	"github.com/esfands/retpaladinbot/internal/services/events"
+	"github.com/esfands/retpaladinbot/pkg/domain"
+	"github.com/gempir/go-twitch-irc/v4"
+)
+
+// UserError is an error whose message is safe to show in chat as is
+type UserError struct {
+	Message string
+}
+
+func (e *UserError) Error() string {
+	return e.Message
+}
+
+// Logging logs every command that was executed and how long it took, or why it was blocked or failed. It's the only
+// place commands are logged
+func Logging() Middleware {
+	return func(next Handler) Handler {
+		return func(req *Request) (string, error) {
+			start := time.Now()
+
+			response, err := next(req)
+
+			if req.Blocked != "" {
+				slog.Info(
+					"Command blocked",
+					"command", req.Command.Name(),
+					"user", req.User.DisplayName,
+					"channel", req.Channel.Name,
+					"reason", req.Blocked,
+				)
+				return response, err
+			}
+
+			var userErr *UserError
+			if err != nil && !errors.As(err, &userErr) {
+				slog.Error(
+					"Command failed",
+					"command", req.Command.Name(),
+					"user", req.User.DisplayName,
+					"channel", req.Channel.Name,
+					"duration", time.Since(start),
+					"error", err.Error(),
+				)
+				return response, err
+			}
+
+			slog.Info(
+				"Command executed",
+				"command", req.Command.Name(),
+				"user", req.User.DisplayName,
+				"channel", req.Channel.Name,
+				"duration", time.Since(start),
+				"error", err,
+			)
+
+			return response, err
+		}
+	}
+}
+
+// Publish tells the event bus about every command which was used, whether it ran, failed or was blocked
+func Publish() Middleware {
+	return func(next Handler) Handler {
+		return func(req *Request) (string, error) {
+			response, err := next(req)
+
+			executed := events.CommandExecutedEvent{
+				ChannelID: req.Channel.ID,
+				Command:   req.Command.Name(),
+				Trigger:   req.Trigger,
+				User:      req.User,
+				Blocked:   req.Blocked,
+			}
+			_, executed.Custom = req.Command.(CustomCommand)
+			// Blocked commands didn't fail, even when the user is told why they were blocked
+			if err != nil && req.Blocked == "" {
+				executed.Error = err.Error()
+			}
+			events.Publish(req.Ctx.Crate().Events, events.CommandExecuted, executed)
+
+			return response, err
+		}
+	}
+}
+
+// ErrorMapping turns errors returned further down the chain into a response for chat, they're logged by Logging
+func ErrorMapping() Middleware {
+	return func(next Handler) Handler {
+		return func(req *Request) (string, error) {
+			response, err := next(req)
+			if err == nil {
+				return response, nil
+			}
+
+			var userErr *UserError
+			if errors.As(err, &userErr) {
+				return userErr.Message, nil
+			}
+
+			return fmt.Sprintf("Something went wrong... error: %v", err.Error()), nil
+		}
+	}
+}
+
+// ChannelEnabled stops commands which are disabled in the channel
+func ChannelEnabled() Middleware {
+	return func(next Handler) Handler {
+		return func(req *Request) (string, error) {
+			enabled := true
+			switch cmd := req.Command.(type) {
+			case DefaultCommand:
+				enabled = cmd.Enabled()
+			case CustomCommand:
+				enabled = cmd.Command.Enabled
+			}
+
+			if !enabled {
+				req.Blocked = BlockedDisabled
+				return "", nil
+			}
+
+			return next(req)
+		}
+	}
+}
+
+// Map Twitch badges to domain permissions
+var badgeToPermission = map[string]domain.Permission{
+	"broadcaster": domain.PermissionBroadcaster,
+	"moderator":   domain.PermissionModerator,
+	"vip":         domain.PermissionVIP,
+}
+
+func isUserPermitted(user twitch.User, requiredPermissions []domain.Permission) bool {
+	for badge := range user.Badges {
+		if permission, exists := badgeToPermission[badge]; exists {
+			for _, requiredPermission := range requiredPermissions {
+				if permission == requiredPermission {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// Permission stops commands the user doesn't have the required badges for
+func Permission() Middleware {
+	return func(next Handler) Handler {
+		return func(req *Request) (string, error) {
+			// Allow execution if the command has no required permissions
+			if len(req.Command.Permissions()) > 0 && !isUserPermitted(req.User, req.Command.Permissions()) {
+				req.Blocked = BlockedPermission
+				return "", nil
+			}
+
+			return next(req)
+		}
+	}
+}
+
+// StreamCondition stops commands which can't run while the stream is in its current state
+func StreamCondition() Middleware {
+	return func(next Handler) Handler {
+		return func(req *Request) (string, error) {
+			streamStatus, err := req.Ctx.Crate().Turso.Queries().GetMostRecentStreamStatus(req.Ctx, req.Channel.ID)
+			// Channels that haven't streamed since they were added have no stream status yet and count as offline
+			if err != nil && !errors.Is(err, sql.ErrNoRows) {
+				slog.Error("Failed to get most recent stream status", "error", err.Error())
+				return "", err
+			}
+
+			conditions := req.Command.Conditions()
+			if (streamStatus.Live && !conditions.EnabledOnline) || (!streamStatus.Live && !conditions.EnabledOffline) {
+				req.Blocked = BlockedCondition
+				return "", nil
+			}
+
+			return next(req)
+		}
+	}
+}
+
+// Cooldown stops commands which are still on cooldown for the user or the channel, the broadcaster and moderators
+// aren't affected by cooldowns
+func Cooldown() Middleware {
+	return func(next Handler) Handler {
+		return func(req *Request) (string, error) {
+			if isUserPermitted(req.User, []domain.Permission{domain.PermissionBroadcaster, domain.PermissionModerator}) {
+				return next(req)
+			}
+
+			store := req.Ctx.Crate().Cooldowns
+
+			globalKey := cooldowns.Key{ChannelID: req.Channel.ID, Command: req.Command.Name()}
+			if req.Channel.CooldownBuckets {
+				globalKey.Bucket = cooldowns.BucketFor(req.User)
+			}
+			userKey := cooldowns.Key{ChannelID: req.Channel.ID, Command: req.Command.Name(), User: req.User.Name}
+
+			remaining, err := acquireCooldowns(req, store, map[cooldowns.Key]time.Duration{
+				globalKey: time.Duration(req.Command.GlobalCooldown()) * time.Second,
+				userKey:   time.Duration(req.Command.UserCooldown()) * time.Second,
+			})
+			if err != nil {
+				// A broken store shouldn't stop every command from working
+				slog.Error("Failed to check cooldowns", "command", req.Command.Name(), "error", err.Error())
+				return next(req)
+			}
+
+			if remaining > 0 {
+				req.Blocked = BlockedCooldown
+				if req.Channel.CooldownNotice && noticeCooldown(req, store, remaining) {
+					return "", &UserError{Message: fmt.Sprintf("%v is on cooldown, try again in %v", req.Command.Name(), remaining.Round(time.Second))}
+				}
+				return "", nil
+			}
+
+			return next(req)
+		}
+	}
+}
+
+// noticeCooldown reports whether the user may be told that the command is on cooldown, they're told once until the
+// cooldown is over so spamming the command doesn't make the bot talk every time
+func noticeCooldown(req *Request, store cooldowns.Store, remaining time.Duration) bool {
+	key := cooldowns.Key{ChannelID: req.Channel.ID, Command: req.Command.Name(), User: req.User.Name, Bucket: cooldowns.BucketNotice}
+
+	held, err := store.Acquire(req.Ctx, key, remaining)
+	if err != nil {
+		slog.Error("Failed to check the cooldown notice", "command", req.Command.Name(), "error", err.Error())
+		return false
+	}
+	return held == 0
+}
+
+// acquireCooldowns starts the cooldowns if none of them are running, otherwise it returns the longest time left
+func acquireCooldowns(req *Request, store cooldowns.Store, durations map[cooldowns.Key]time.Duration) (time.Duration, error) {
+	var longest time.Duration
+	for key := range durations {
+		remaining, err := store.Remaining(req.Ctx, key)
+		if err != nil {
+			return 0, err
+		}
+		longest = max(longest, remaining)
+	}
+
+	if longest > 0 {
+		return longest, nil
+	}
+
+	for key, duration := range durations {
+		remaining, err := store.Acquire(req.Ctx, key, duration)
+		if err != nil {
+			return 0, err
+		}
+		longest = max(longest, remaining)
+	}
+
+	return longest, nil
+}
+
+// Arguments parses the arguments of commands which declare a schema, invalid arguments are answered with the usage
+func Arguments() Middleware {
+	return func(next Handler) Handler {
+		return func(req *Request) (string, error) {
+			schema := req.Command.Arguments()
+			if schema == nil {
+				return next(req)
+			}
+
+			values, err := args.Parse(schema, req.Channel.Prefix+req.Trigger, req.Input)
+			if err != nil {
+				return "", &UserError{Message: err.Error()}
+			}
+			req.Values = values
+
+			return next(req)
+		}
+	}
+}
+
+// counterRegex matches the counter changes of custom commands, e.g. +1, -1 or set 10
+var counterRegex = regexp.MustCompile(`^(?:([+-]\d+)|set\s+(-?\d+))$`)
+
+// Counter changes the counter of a custom command when a moderator uses it with +N, -N or set N, the command
+// responds as usual afterwards so the response can show the new value
+func Counter() Middleware {
+	return func(next Handler) Handler {
+		return func(req *Request) (string, error) {
+			cmd, ok := req.Command.(CustomCommand)
+			if !ok {
+				return next(req)
+			}
+
+			match := counterRegex.FindStringSubmatch(strings.ToLower(strings.TrimSpace(req.Input)))
+			if match == nil || !isUserPermitted(req.User, []domain.Permission{domain.PermissionBroadcaster, domain.PermissionModerator}) {
+				return next(req)
+			}
+
+			queries := req.Ctx.Crate().Turso.Queries()
+
+			var err error
+			if match[1] != "" {
+				amount, _ := strconv.Atoi(match[1])
+				_, err = queries.AddToCounter(req.Ctx, req.Channel.ID, cmd.Name(), amount)
+			} else {
+				value, _ := strconv.Atoi(match[2])
+				err = queries.SetCounter(req.Ctx, req.Channel.ID, cmd.Name(), value)
+			}
+			if err != nil {
+				return "", err
+			}
+
+			return next(req)
+		}
+	}
+}
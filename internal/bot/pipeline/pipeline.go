@@ -0,0 +1,214 @@
+package pipeline
+
+import (
+	"fmt"
+
+	"github.com/esfands/retpaladinbot/internal/bot/args"
+	"github.com/esfands/retpaladinbot/internal/bot/invocation"
+	"github.com/esfands/retpaladinbot/internal/bot/responses"
+	"github.com/esfands/retpaladinbot/internal/bot/variables"
+	"github.com/esfands/retpaladinbot/pkg/domain"
+)
+
+// Command is anything the pipeline can execute, default and custom commands are adapted to it
+type Command interface {
+	Name() string
+	Permissions() []domain.Permission
+	Conditions() domain.DefaultCommandConditions
+	GlobalCooldown() int
+	UserCooldown() int
+	// ThreadedReply is whether the response is sent as a reply to the message which triggered the command
+	ThreadedReply() bool
+	// Arguments is the schema the arguments are parsed with, nil if the command parses them itself
+	Arguments() *args.Schema
+	Run(req *Request) (string, error)
+}
+
+// Request holds everything known about a single command execution
+type Request struct {
+	*invocation.Invocation
+	Command Command
+	// Blocked is why a middleware stopped the command before it ran, it's empty when nothing stopped it
+	Blocked string
+}
+
+// Reasons a middleware blocks a command for
+const (
+	BlockedDisabled   = "disabled"
+	BlockedPermission = "permission"
+	BlockedCondition  = "stream_condition"
+	BlockedCooldown   = "cooldown"
+)
+
+// Handler runs a request and returns the response to send to chat
+type Handler func(req *Request) (string, error)
+
+// Middleware wraps a handler, it can stop the execution by returning without calling next
+type Middleware func(next Handler) Handler
+
+type Pipeline struct {
+	middlewares []Middleware
+}
+
+// New creates a pipeline which runs the middlewares in the given order before the command
+func New(middlewares ...Middleware) *Pipeline {
+	return &Pipeline{
+		middlewares: middlewares,
+	}
+}
+
+// Default creates a pipeline with the built-in checks every command goes through, ErrorMapping comes first so the
+// middlewares after it see the errors as they were returned
+func Default() *Pipeline {
+	return New(
+		ErrorMapping(),
+		Logging(),
+		Publish(),
+		ChannelEnabled(),
+		Permission(),
+		StreamCondition(),
+		Cooldown(),
+		Arguments(),
+		Counter(),
+	)
+}
+
+// Use appends middlewares to the end of the chain, they run right before the command
+func (p *Pipeline) Use(middlewares ...Middleware) {
+	p.middlewares = append(p.middlewares, middlewares...)
+}
+
+// Execute runs the request through the middleware chain and then the command
+func (p *Pipeline) Execute(req *Request) (string, error) {
+	handler := func(req *Request) (string, error) {
+		return req.Command.Run(req)
+	}
+
+	for i := len(p.middlewares) - 1; i >= 0; i-- {
+		handler = p.middlewares[i](handler)
+	}
+
+	return handler(req)
+}
+
+// DefaultCommand adapts both default command shapes to the pipeline
+type DefaultCommand struct {
+	domain.DefaultCommandInfo
+	// Override holds the settings changed in the channel the command runs in
+	Override domain.CommandOverride
+}
+
+// Enabled reports whether the command is enabled in the channel
+func (c DefaultCommand) Enabled() bool {
+	return c.Override.Enabled == nil || *c.Override.Enabled
+}
+
+func (c DefaultCommand) Permissions() []domain.Permission {
+	if c.Override.Permissions != nil {
+		return *c.Override.Permissions
+	}
+	return c.DefaultCommandInfo.Permissions()
+}
+
+func (c DefaultCommand) Conditions() domain.DefaultCommandConditions {
+	conditions := c.DefaultCommandInfo.Conditions()
+	if c.Override.EnabledOnline != nil {
+		conditions.EnabledOnline = *c.Override.EnabledOnline
+	}
+	if c.Override.EnabledOffline != nil {
+		conditions.EnabledOffline = *c.Override.EnabledOffline
+	}
+	return conditions
+}
+
+func (c DefaultCommand) GlobalCooldown() int {
+	if c.Override.GlobalCooldown != nil {
+		return *c.Override.GlobalCooldown
+	}
+	return c.DefaultCommandInfo.GlobalCooldown()
+}
+
+func (c DefaultCommand) UserCooldown() int {
+	if c.Override.UserCooldown != nil {
+		return *c.Override.UserCooldown
+	}
+	return c.DefaultCommandInfo.UserCooldown()
+}
+
+func (c DefaultCommand) ThreadedReply() bool {
+	if c.Override.ThreadedReply != nil {
+		return *c.Override.ThreadedReply
+	}
+	if replier, ok := c.DefaultCommandInfo.(domain.ThreadedReplier); ok {
+		return replier.ThreadedReply()
+	}
+	return true
+}
+
+func (c DefaultCommand) Arguments() *args.Schema {
+	if provider, ok := c.DefaultCommandInfo.(args.Provider); ok {
+		return provider.Arguments()
+	}
+	return nil
+}
+
+func (c DefaultCommand) Run(req *Request) (string, error) {
+	switch cmd := c.DefaultCommandInfo.(type) {
+	case invocation.Command:
+		// Invocation based commands send their responses themselves
+		return "", cmd.Execute(req.Invocation)
+	case domain.DefaultCommand:
+		return cmd.Code(req.Channel, req.User, req.Args)
+	default:
+		return "", fmt.Errorf("command %v has no code", c.Name())
+	}
+}
+
+// CustomCommand adapts a domain.CustomCommand to the pipeline
+type CustomCommand struct {
+	Command   domain.CustomCommand
+	Variables variables.ServiceI
+	Responses *responses.Picker
+}
+
+func (c CustomCommand) Name() string {
+	return c.Command.Name
+}
+
+func (c CustomCommand) Permissions() []domain.Permission {
+	return c.Command.Permissions
+}
+
+func (c CustomCommand) Conditions() domain.DefaultCommandConditions {
+	return domain.DefaultCommandConditions{
+		EnabledOnline:  c.Command.EnabledOnline,
+		EnabledOffline: c.Command.EnabledOffline,
+	}
+}
+
+func (c CustomCommand) GlobalCooldown() int {
+	return c.Command.GlobalCooldown
+}
+
+func (c CustomCommand) UserCooldown() int {
+	return c.Command.UserCooldown
+}
+
+// ThreadedReply is off for custom commands unless it's turned on for one, their responses mention the user with
+// ${user} where needed
+func (c CustomCommand) ThreadedReply() bool {
+	return c.Command.ThreadedReply
+}
+
+func (c CustomCommand) Arguments() *args.Schema {
+	return nil
+}
+
+func (c CustomCommand) Run(req *Request) (string, error) {
+	return c.Variables.ParseVariables(req.Ctx, variables.Scope{
+		Channel: req.Channel,
+		User:    req.User,
+		Command: c.Command.Name,
+		Args:    req.Args,
+	}, c.Responses.Pick(c.Command)), nil
+}
@@ -0,0 +1,111 @@
+package responses
+
+import (
+	"math/rand"
+	"slices"
+	"sync"
+
+	"github.com/esfands/retpaladinbot/pkg/domain"
+)
+
+type key struct {
+	channelID string
+	command   string
+}
+
+type history struct {
+	// next is the response the round-robin mode picks next
+	next int
+	// recent are the last picked responses, the most recent one is last
+	recent []int
+}
+
+// Picker picks one of the responses of a custom command, it remembers the last picks of every command
+type Picker struct {
+	mu      sync.Mutex
+	history map[key]*history
+}
+
+func NewPicker() *Picker {
+	return &Picker{
+		history: make(map[key]*history),
+	}
+}
+
+// Pick returns the response to send, or an empty string if the command has no responses
+func (p *Picker) Pick(cmd domain.CustomCommand) string {
+	if len(cmd.Responses) == 0 {
+		return ""
+	}
+	if len(cmd.Responses) == 1 {
+		return cmd.Responses[0].Text
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	k := key{channelID: cmd.ChannelID, command: cmd.Name}
+	h, ok := p.history[k]
+	if !ok {
+		h = &history{}
+		p.history[k] = h
+	}
+
+	var picked int
+	switch cmd.ResponseMode {
+	case domain.ResponseModeRoundRobin:
+		picked = h.next % len(cmd.Responses)
+		h.next = picked + 1
+	default:
+		picked = pickRandom(cmd, h.recent)
+	}
+
+	// At least one response has to stay available, otherwise nothing could be picked
+	if avoid := min(cmd.AvoidRepeats, len(cmd.Responses)-1); avoid > 0 {
+		h.recent = append(h.recent, picked)
+		if len(h.recent) > avoid {
+			h.recent = h.recent[len(h.recent)-avoid:]
+		}
+	} else {
+		h.recent = nil
+	}
+
+	return cmd.Responses[picked].Text
+}
+
+// pickRandom picks a response which wasn't picked recently, uniformly or by weight
+func pickRandom(cmd domain.CustomCommand, recent []int) int {
+	var candidates []int
+	total := 0
+	for i, response := range cmd.Responses {
+		if slices.Contains(recent, i) {
+			continue
+		}
+		candidates = append(candidates, i)
+		total += weight(response)
+	}
+
+	// The responses may have changed since they were picked, which can leave nothing to pick from
+	if len(candidates) == 0 {
+		return rand.Intn(len(cmd.Responses))
+	}
+
+	if cmd.ResponseMode != domain.ResponseModeWeighted {
+		return candidates[rand.Intn(len(candidates))]
+	}
+
+	n := rand.Intn(total)
+	for _, i := range candidates {
+		n -= weight(cmd.Responses[i])
+		if n < 0 {
+			return i
+		}
+	}
+
+	return candidates[len(candidates)-1]
+}
+
+// weight returns the weight of a response, responses without one count as 1
+func weight(response domain.CustomCommandResponse) int {
+	return max(response.Weight, 1)
+}
@@ -0,0 +1,53 @@
+package sender
+
+import (
+	"math"
+	"time"
+)
+
+// bucket is a token bucket which refills continuously up to its capacity
+type bucket struct {
+	capacity float64
+	tokens   float64
+	// rate is the amount of tokens refilled per second
+	rate float64
+	last time.Time
+}
+
+func newBucket(limit rateLimit, now time.Time) *bucket {
+	return &bucket{
+		capacity: float64(limit.messages),
+		tokens:   float64(limit.messages),
+		rate:     float64(limit.messages) / limit.period.Seconds(),
+		last:     now,
+	}
+}
+
+// setLimit changes the limit of the bucket while keeping the tokens that are left
+func (b *bucket) setLimit(limit rateLimit, now time.Time) {
+	b.refill(now)
+
+	b.capacity = float64(limit.messages)
+	b.rate = float64(limit.messages) / limit.period.Seconds()
+	b.tokens = math.Min(b.tokens, b.capacity)
+}
+
+func (b *bucket) refill(now time.Time) {
+	elapsed := now.Sub(b.last).Seconds()
+	if elapsed > 0 {
+		b.tokens = math.Min(b.capacity, b.tokens+elapsed*b.rate)
+	}
+	b.last = now
+}
+
+// take takes a token from the bucket, it returns how long to wait before a token is available if it's empty
+func (b *bucket) take(now time.Time) time.Duration {
+	b.refill(now)
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0
+	}
+
+	return time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+}
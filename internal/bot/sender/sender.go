@@ -0,0 +1,252 @@
+package sender
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+type rateLimit struct {
+	messages int
+	period   time.Duration
+}
+
+var (
+	// Twitch allows 20 messages every 30 seconds for regular users
+	userRateLimit = rateLimit{messages: 20, period: 30 * time.Second}
+	// Moderators and VIPs may send 100 messages every 30 seconds
+	elevatedRateLimit = rateLimit{messages: 100, period: 30 * time.Second}
+)
+
+const (
+	// userMessageInterval is the minimum time between two messages of a regular user in the same channel
+	userMessageInterval = time.Second
+	// duplicateWindow is how long Twitch rejects a message identical to the previous one
+	duplicateWindow = 30 * time.Second
+	// duplicateSuffix is an invisible character appended to get around the identical message rule
+	duplicateSuffix = " \U000E0000"
+	// queueSize is the maximum amount of messages waiting to be sent per channel
+	queueSize = 100
+)
+
+// Client is the part of the go-twitch-irc client the sender writes to
+type Client interface {
+	Say(channel, text string)
+	Reply(channel, parentMsgID, text string)
+}
+
+type Service interface {
+	// Say queues a message to be sent to a channel
+	Say(channel, message string)
+	// Reply queues a message to be sent to a channel as a reply to another message
+	Reply(channel, parentID, message string)
+	// SetElevated sets whether the bot is a moderator or VIP in a channel, which raises the rate limit
+	SetElevated(channel string, elevated bool)
+	// Close stops accepting messages and waits for the queued messages to be sent
+	Close(ctx context.Context) error
+}
+
+type outbound struct {
+	parentID string
+	text     string
+}
+
+type channelQueue struct {
+	messages chan outbound
+
+	mu          sync.Mutex
+	elevated    bool
+	bucket      *bucket
+	lastMessage string
+	lastSentAt  time.Time
+}
+
+type senderService struct {
+	client Client
+
+	mu     sync.Mutex
+	queues map[string]*channelQueue
+	closed bool
+	wg     sync.WaitGroup
+}
+
+func New(client Client) Service {
+	return &senderService{
+		client: client,
+		queues: make(map[string]*channelQueue),
+	}
+}
+
+func (s *senderService) Say(channel, message string) {
+	s.enqueue(channel, "", message)
+}
+
+func (s *senderService) Reply(channel, parentID, message string) {
+	s.enqueue(channel, parentID, message)
+}
+
+func (s *senderService) SetElevated(channel string, elevated bool) {
+	q := s.queue(channel)
+	if q == nil {
+		return
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.elevated == elevated {
+		return
+	}
+
+	q.elevated = elevated
+	q.bucket.setLimit(limitFor(elevated), time.Now())
+
+	slog.Debug("[sender] rate limit changed", "channel", channel, "elevated", elevated)
+}
+
+func (s *senderService) Close(ctx context.Context) error {
+	s.mu.Lock()
+	if !s.closed {
+		s.closed = true
+		for _, q := range s.queues {
+			close(q.messages)
+		}
+	}
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *senderService) enqueue(channel, parentID, message string) {
+	if strings.TrimSpace(message) == "" {
+		return
+	}
+
+	channel = strings.ToLower(channel)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		slog.Warn("[sender] dropping message, sender is closed", "channel", channel)
+		return
+	}
+
+	q := s.queueLocked(channel)
+
+	// Only the first part of a split message is sent as a reply, the parts leave room for the duplicate suffix so they
+	// stay within the limit when it's appended
+	for i, part := range splitMessage(message, MaxMessageLength-utf8.RuneCountInString(duplicateSuffix)) {
+		msg := outbound{text: part}
+		if i == 0 {
+			msg.parentID = parentID
+		}
+
+		select {
+		case q.messages <- msg:
+		default:
+			slog.Warn("[sender] dropping message, queue is full", "channel", channel)
+			return
+		}
+	}
+}
+
+// queue returns the queue of a channel, or nil if the sender is closed
+func (s *senderService) queue(channel string) *channelQueue {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return nil
+	}
+
+	return s.queueLocked(strings.ToLower(channel))
+}
+
+func (s *senderService) queueLocked(channel string) *channelQueue {
+	if q, ok := s.queues[channel]; ok {
+		return q
+	}
+
+	q := &channelQueue{
+		messages: make(chan outbound, queueSize),
+		bucket:   newBucket(userRateLimit, time.Now()),
+	}
+	s.queues[channel] = q
+
+	s.wg.Add(1)
+	go s.run(channel, q)
+
+	return q
+}
+
+// run sends the messages of a channel queue one by one, waiting for the rate limit in between
+func (s *senderService) run(channel string, q *channelQueue) {
+	defer s.wg.Done()
+
+	for msg := range q.messages {
+		text := q.prepare(msg.text)
+
+		if msg.parentID != "" {
+			s.client.Reply(channel, msg.parentID, text)
+		} else {
+			s.client.Say(channel, text)
+		}
+	}
+}
+
+// prepare blocks until the message may be sent and alters it if Twitch would reject it as a duplicate
+func (q *channelQueue) prepare(text string) string {
+	for {
+		q.mu.Lock()
+		now := time.Now()
+
+		wait := q.bucket.take(now)
+		if wait == 0 && !q.elevated {
+			if next := q.lastSentAt.Add(userMessageInterval); now.Before(next) {
+				// Give the token back, it will be taken again once the interval has passed
+				q.bucket.tokens++
+				wait = next.Sub(now)
+			}
+		}
+
+		if wait > 0 {
+			q.mu.Unlock()
+			time.Sleep(wait)
+			continue
+		}
+
+		if now.Sub(q.lastSentAt) < duplicateWindow && text == strings.TrimSuffix(q.lastMessage, duplicateSuffix) {
+			if !strings.HasSuffix(q.lastMessage, duplicateSuffix) {
+				text += duplicateSuffix
+			}
+		}
+
+		q.lastMessage = text
+		q.lastSentAt = now
+		q.mu.Unlock()
+
+		return text
+	}
+}
+
+func limitFor(elevated bool) rateLimit {
+	if elevated {
+		return elevatedRateLimit
+	}
+	return userRateLimit
+}
@@ -0,0 +1,55 @@
+package sender
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxMessageLength is the maximum amount of characters Twitch accepts in a single chat message
+const MaxMessageLength = 500
+
+// splitMessage splits a message into parts of at most limit characters, breaking on word boundaries
+func splitMessage(message string, limit int) []string {
+	message = strings.TrimSpace(message)
+	if utf8.RuneCountInString(message) <= limit {
+		return []string{message}
+	}
+
+	var parts []string
+	var current []rune
+
+	flush := func() {
+		if part := strings.TrimSpace(string(current)); part != "" {
+			parts = append(parts, part)
+		}
+		current = current[:0]
+	}
+
+	for _, word := range strings.Fields(message) {
+		runes := []rune(word)
+
+		// Words that don't fit in a message on their own are cut into pieces
+		for len(runes) > limit {
+			flush()
+			parts = append(parts, string(runes[:limit]))
+			runes = runes[limit:]
+		}
+
+		needed := len(runes)
+		if len(current) > 0 {
+			needed++
+		}
+
+		if len(current)+needed > limit {
+			flush()
+		}
+
+		if len(current) > 0 {
+			current = append(current, ' ')
+		}
+		current = append(current, runes...)
+	}
+	flush()
+
+	return parts
+}
@@ -0,0 +1,29 @@
+package bot
+
+import (
+	"log/slog"
+
+	"github.com/esfands/retpaladinbot/internal/global"
+	"github.com/esfands/retpaladinbot/internal/services/events"
+)
+
+// countUsage increments the usage counts of the commands which ran successfully
+func countUsage(gctx global.Context) {
+	events.Subscribe(gctx.Crate().Events, events.CommandExecuted, "usage", func(event events.CommandExecutedEvent) {
+		if event.Blocked != "" || event.Error != "" {
+			return
+		}
+
+		queries := gctx.Crate().Turso.Queries()
+		if event.Custom {
+			if err := queries.IncrementCustomCommandUsageCount(gctx, event.ChannelID, event.Command); err != nil {
+				slog.Error("Failed to update custom command usage", "error", err.Error())
+			}
+			return
+		}
+
+		if err := queries.IncrementDefaultCommandUsageCount(gctx, event.Command); err != nil {
+			slog.Error("Failed to update default command usage", "error", err.Error())
+		}
+	})
+}
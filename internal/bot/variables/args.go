@@ -0,0 +1,78 @@
+package variables
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/esfands/retpaladinbot/internal/global"
+)
+
+type ArgsVariable struct {
+	gctx global.Context
+
+	name string
+}
+
+func NewArgsVariable(gctx global.Context) VariableI {
+	return &ArgsVariable{
+		gctx: gctx,
+		name: "args",
+	}
+}
+
+func (v *ArgsVariable) GetName() string {
+	return v.name
+}
+
+func (v *ArgsVariable) GetAliases() []string {
+	return []string{}
+}
+
+func (v *ArgsVariable) Description() string {
+	return "All arguments given to the command."
+}
+
+func (v *ArgsVariable) Code(_ context.Context, scope Scope, _ Call) string {
+	return strings.Join(scope.Args, " ")
+}
+
+// ArgVariable is used as ${arg1}, ${arg2} and so on
+type ArgVariable struct {
+	gctx global.Context
+
+	name string
+}
+
+func NewArgVariable(gctx global.Context) VariableI {
+	return &ArgVariable{
+		gctx: gctx,
+		name: "arg",
+	}
+}
+
+func (v *ArgVariable) GetName() string {
+	return v.name
+}
+
+func (v *ArgVariable) GetAliases() []string {
+	return []string{}
+}
+
+func (v *ArgVariable) Description() string {
+	return "A single argument given to the command by its position, e.g. ${arg1} or ${arg(1)} is the first argument."
+}
+
+func (v *ArgVariable) Code(_ context.Context, scope Scope, call Call) string {
+	param := call.Param
+	if param == "" {
+		param = call.Arg(0)
+	}
+
+	position, err := strconv.Atoi(strings.TrimSpace(param))
+	if err != nil || position < 1 || position > len(scope.Args) {
+		return ""
+	}
+
+	return scope.Args[position-1]
+}
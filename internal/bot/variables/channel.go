@@ -0,0 +1,78 @@
+package variables
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+
+	"github.com/esfands/retpaladinbot/internal/global"
+)
+
+type ChannelVariable struct {
+	gctx global.Context
+
+	name string
+}
+
+func NewChannelVariable(gctx global.Context) VariableI {
+	return &ChannelVariable{
+		gctx: gctx,
+		name: "channel",
+	}
+}
+
+func (v *ChannelVariable) GetName() string {
+	return v.name
+}
+
+func (v *ChannelVariable) GetAliases() []string {
+	return []string{}
+}
+
+func (v *ChannelVariable) Description() string {
+	return "The name of the channel the command was used in."
+}
+
+func (v *ChannelVariable) Code(_ context.Context, scope Scope, _ Call) string {
+	return scope.Channel.Name
+}
+
+type CountVariable struct {
+	gctx global.Context
+
+	name string
+}
+
+func NewCountVariable(gctx global.Context) VariableI {
+	return &CountVariable{
+		gctx: gctx,
+		name: "count",
+	}
+}
+
+func (v *CountVariable) GetName() string {
+	return v.name
+}
+
+func (v *CountVariable) GetAliases() []string {
+	return []string{}
+}
+
+func (v *CountVariable) Description() string {
+	return "How many times the command has been used, including this time. In chat event responses it's the number of gifts, bits or the milestone."
+}
+
+func (v *CountVariable) Code(ctx context.Context, scope Scope, _ Call) string {
+	if count, ok := scope.Event["count"]; ok {
+		return count
+	}
+
+	command, err := v.gctx.Crate().Turso.Queries().GetCustomCommandByName(ctx, scope.Channel.ID, scope.Command)
+	if err != nil {
+		slog.Error("[count-variable] error getting the custom command", "command", scope.Command, "error", err.Error())
+		return ""
+	}
+
+	// The usage count is incremented once the command has run
+	return strconv.Itoa(command.UsageCount + 1)
+}
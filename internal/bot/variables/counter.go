@@ -0,0 +1,58 @@
+package variables
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/esfands/retpaladinbot/internal/global"
+)
+
+// CounterVariable is used as ${counter.deaths} or ${counter(deaths)}, without a name it's the counter of the command
+type CounterVariable struct {
+	gctx global.Context
+
+	name string
+}
+
+func NewCounterVariable(gctx global.Context) VariableI {
+	return &CounterVariable{
+		gctx: gctx,
+		name: "counter",
+	}
+}
+
+func (v *CounterVariable) GetName() string {
+	return v.name
+}
+
+func (v *CounterVariable) GetAliases() []string {
+	return []string{}
+}
+
+func (v *CounterVariable) Description() string {
+	return "The value of a counter, e.g. ${counter.deaths}. Without a name it's the counter of the command itself."
+}
+
+func (v *CounterVariable) Code(ctx context.Context, scope Scope, call Call) string {
+	name := call.Param
+	if name == "" {
+		name = call.Arg(0)
+	}
+	if name = strings.ToLower(strings.TrimSpace(name)); name == "" {
+		name = scope.Command
+	}
+
+	counter, err := v.gctx.Crate().Turso.Queries().GetCounter(ctx, scope.Channel.ID, name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "0"
+	} else if err != nil {
+		slog.Error("[counter-variable] error getting the counter", "counter", name, "error", err.Error())
+		return ""
+	}
+
+	return strconv.Itoa(counter.Value)
+}
@@ -0,0 +1,50 @@
+package variables
+
+import (
+	"context"
+
+	"github.com/esfands/retpaladinbot/internal/global"
+)
+
+// EventVariable is a value of the chat event a message responds to, it's empty outside of event responses
+type EventVariable struct {
+	gctx global.Context
+
+	name        string
+	description string
+}
+
+// NewEventVariables returns the variables of chat events, ${count} is handled by the count variable
+func NewEventVariables(gctx global.Context) []VariableI {
+	return []VariableI{
+		&EventVariable{gctx: gctx, name: "months", description: "The number of months someone has been subscribed for."},
+		&EventVariable{gctx: gctx, name: "gifter", description: "Who gifted the subscriptions."},
+		&EventVariable{gctx: gctx, name: "recipient", description: "Who received a gifted subscription."},
+		&EventVariable{gctx: gctx, name: "viewers", description: "The number of viewers a raid brought."},
+		&EventVariable{gctx: gctx, name: "tier", description: "The tier of a subscription, 1, 2, 3 or Prime."},
+		&EventVariable{gctx: gctx, name: "notice", description: "The message Twitch shows in chat for the event."},
+		&EventVariable{gctx: gctx, name: "raider", description: "Who raided the channel, ${raider.login} is their login and ${raider.game} what they last played."},
+		&EventVariable{gctx: gctx, name: "text", description: "What the user wrote along with the event, e.g. the text of an announcement."},
+		&EventVariable{gctx: gctx, name: "reward", description: "The title of the channel point reward which was redeemed."},
+		&EventVariable{gctx: gctx, name: "position", description: "The position of the user in the queue a channel point reward added them to."},
+	}
+}
+
+func (v *EventVariable) GetName() string {
+	return v.name
+}
+
+func (v *EventVariable) GetAliases() []string {
+	return []string{}
+}
+
+func (v *EventVariable) Description() string {
+	return v.description
+}
+
+func (v *EventVariable) Code(_ context.Context, scope Scope, call Call) string {
+	if call.Param != "" {
+		return scope.Event[v.name+"."+call.Param]
+	}
+	return scope.Event[v.name]
+}
@@ -0,0 +1,100 @@
+package variables
+
+import (
+	"context"
+	"math/rand"
+	"strconv"
+	"strings"
+
+	"github.com/esfands/retpaladinbot/internal/global"
+)
+
+// RandomVariable is used as ${random.1-100} or ${random(1, 100)} for a number in a range, and as
+// ${random.pick a|b|c} or ${random.pick(a, b, c)} to pick an option
+type RandomVariable struct {
+	gctx global.Context
+
+	name string
+}
+
+func NewRandomVariable(gctx global.Context) VariableI {
+	return &RandomVariable{
+		gctx: gctx,
+		name: "random",
+	}
+}
+
+func (v *RandomVariable) GetName() string {
+	return v.name
+}
+
+func (v *RandomVariable) GetAliases() []string {
+	return []string{}
+}
+
+func (v *RandomVariable) Description() string {
+	return "A random number in a range like ${random.1-100} or ${random(1, 100)}, or a random option like " +
+		"${random.pick a|b|c} or ${random.pick(a, b, c)}. Empty options aren't picked."
+}
+
+func (v *RandomVariable) Code(_ context.Context, _ Scope, call Call) string {
+	if call.Param == "pick" || strings.HasPrefix(call.Param, "pick ") {
+		choices := call.Args
+		if options := strings.TrimPrefix(call.Param, "pick"); options != "" {
+			choices = strings.Split(options, "|")
+		}
+		return pick(choices)
+	}
+
+	rangeText := call.Param
+	if len(call.Args) == 2 {
+		rangeText = call.Args[0] + "-" + call.Args[1]
+	}
+
+	low, high, ok := parseRange(rangeText)
+	if !ok {
+		return ""
+	}
+
+	return strconv.Itoa(low + rand.Intn(high-low+1))
+}
+
+// pick returns a random choice which isn't empty
+func pick(choices []string) string {
+	var options []string
+	for _, choice := range choices {
+		if choice = strings.TrimSpace(choice); choice != "" {
+			options = append(options, choice)
+		}
+	}
+
+	if len(options) == 0 {
+		return ""
+	}
+
+	return options[rand.Intn(len(options))]
+}
+
+// parseRange parses a range like 1-100, the bounds may be given in any order and can be negative
+func parseRange(param string) (int, int, bool) {
+	// Skip the first character so a negative lower bound isn't mistaken for the separator
+	if len(param) < 3 {
+		return 0, 0, false
+	}
+	separator := strings.Index(param[1:], "-")
+	if separator == -1 {
+		return 0, 0, false
+	}
+	separator++
+
+	low, err := strconv.Atoi(strings.TrimSpace(param[:separator]))
+	if err != nil {
+		return 0, 0, false
+	}
+	high, err := strconv.Atoi(strings.TrimSpace(param[separator+1:]))
+	if err != nil {
+		return 0, 0, false
+	}
+
+	return min(low, high), max(low, high), true
+}
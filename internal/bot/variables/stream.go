@@ -0,0 +1,128 @@
+package variables
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/esfands/retpaladinbot/internal/db"
+	"github.com/esfands/retpaladinbot/internal/global"
+	"github.com/esfands/retpaladinbot/pkg/utils"
+)
+
+// streamStatus returns the most recent stream of the channel, ok is false if it couldn't be found
+func streamStatus(ctx context.Context, gctx global.Context, channelID string) (db.StreamStatus, bool) {
+	stream, err := gctx.Crate().Turso.Queries().GetMostRecentStreamStatus(ctx, channelID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return db.StreamStatus{}, false
+	} else if err != nil {
+		slog.Error("[variables] error getting most recent stream status", "error", err.Error())
+		return db.StreamStatus{}, false
+	}
+
+	return stream, true
+}
+
+type UptimeVariable struct {
+	gctx global.Context
+
+	name string
+}
+
+func NewUptimeVariable(gctx global.Context) VariableI {
+	return &UptimeVariable{
+		gctx: gctx,
+		name: "uptime",
+	}
+}
+
+func (v *UptimeVariable) GetName() string {
+	return v.name
+}
+
+func (v *UptimeVariable) GetAliases() []string {
+	return []string{}
+}
+
+func (v *UptimeVariable) Description() string {
+	return "How long the stream has been live, or \"offline\" if it isn't live."
+}
+
+func (v *UptimeVariable) Code(ctx context.Context, scope Scope, _ Call) string {
+	stream, ok := streamStatus(ctx, v.gctx, scope.Channel.ID)
+	if !ok || !stream.Live || stream.EndedAt.Valid {
+		return "offline"
+	}
+
+	startedAt, err := time.Parse(time.RFC3339, stream.StartedAt)
+	if err != nil {
+		slog.Error("[uptime-variable] error parsing stream start time", "error", err.Error())
+		return ""
+	}
+
+	return utils.TimeDifference(startedAt, time.Now(), true)
+}
+
+type TitleVariable struct {
+	gctx global.Context
+
+	name string
+}
+
+func NewTitleVariable(gctx global.Context) VariableI {
+	return &TitleVariable{
+		gctx: gctx,
+		name: "title",
+	}
+}
+
+func (v *TitleVariable) GetName() string {
+	return v.name
+}
+
+func (v *TitleVariable) GetAliases() []string {
+	return []string{}
+}
+
+func (v *TitleVariable) Description() string {
+	return "The current title of the stream."
+}
+
+func (v *TitleVariable) Code(ctx context.Context, scope Scope, _ Call) string {
+	stream, _ := streamStatus(ctx, v.gctx, scope.Channel.ID)
+
+	return stream.Title.String
+}
+
+type GameVariable struct {
+	gctx global.Context
+
+	name string
+}
+
+func NewGameVariable(gctx global.Context) VariableI {
+	return &GameVariable{
+		gctx: gctx,
+		name: "game",
+	}
+}
+
+func (v *GameVariable) GetName() string {
+	return v.name
+}
+
+func (v *GameVariable) GetAliases() []string {
+	return []string{"category"}
+}
+
+func (v *GameVariable) Description() string {
+	return "The category the stream is currently under."
+}
+
+func (v *GameVariable) Code(ctx context.Context, scope Scope, _ Call) string {
+	stream, _ := streamStatus(ctx, v.gctx, scope.Channel.ID)
+
+	return stream.GameName.String
+}
@@ -0,0 +1,507 @@
+package variables
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Templates are the responses of custom commands. Text is copied as is, except for:
+//
+//	${name}                 a variable
+//	${name.param}           a variable with a param, e.g. ${random.1-100} or ${time.America/Chicago}
+//	${name(a, b)}           a variable with arguments, which may contain variables themselves
+//	${if cond}..${else}..${end}
+//	                        a conditional, the condition is a variable, a "quoted" text or a number,
+//	                        optionally negated with ! or compared with ==, !=, <, <=, > or >= to another one
+//	\$, \\, \, \( \) \" \}  the character after the backslash is used as is
+//
+// The output of a variable is never parsed again.
+
+// TemplateError is returned for templates which can't be parsed
+type TemplateError struct {
+	// Position is the character the problem was found at, starting at 1
+	Position int
+	Message  string
+}
+
+func (e *TemplateError) Error() string {
+	return fmt.Sprintf("%v at character %v", e.Message, e.Position)
+}
+
+// Template is a parsed template which can be executed any number of times
+type Template struct {
+	nodes []node
+}
+
+// Execute fills in the variables of the template
+func (t *Template) Execute(ctx context.Context, scope Scope) string {
+	var sb strings.Builder
+	render(&sb, ctx, scope, t.nodes)
+	return sb.String()
+}
+
+type node interface {
+	render(sb *strings.Builder, ctx context.Context, scope Scope)
+}
+
+func render(sb *strings.Builder, ctx context.Context, scope Scope, nodes []node) {
+	for _, n := range nodes {
+		n.render(sb, ctx, scope)
+	}
+}
+
+func renderString(ctx context.Context, scope Scope, nodes []node) string {
+	var sb strings.Builder
+	render(&sb, ctx, scope, nodes)
+	return sb.String()
+}
+
+type textNode string
+
+func (n textNode) render(sb *strings.Builder, _ context.Context, _ Scope) {
+	sb.WriteString(string(n))
+}
+
+type variableNode struct {
+	variable VariableI
+	param    string
+	args     [][]node
+}
+
+func (n *variableNode) render(sb *strings.Builder, ctx context.Context, scope Scope) {
+	call := Call{Param: n.param}
+	for _, arg := range n.args {
+		call.Args = append(call.Args, renderString(ctx, scope, arg))
+	}
+
+	sb.WriteString(n.variable.Code(ctx, scope, call))
+}
+
+type ifNode struct {
+	negate      bool
+	left, right []node
+	// operator is empty when only the left operand is checked
+	operator  string
+	then, els []node
+}
+
+func (n *ifNode) render(sb *strings.Builder, ctx context.Context, scope Scope) {
+	left := renderString(ctx, scope, n.left)
+
+	var result bool
+	if n.operator == "" {
+		result = truthy(left)
+	} else {
+		result = compare(left, n.operator, renderString(ctx, scope, n.right))
+	}
+
+	if result != n.negate {
+		render(sb, ctx, scope, n.then)
+	} else {
+		render(sb, ctx, scope, n.els)
+	}
+}
+
+// truthy reports whether a value counts as true in a condition
+func truthy(value string) bool {
+	value = strings.TrimSpace(value)
+	return value != "" && value != "0" && !strings.EqualFold(value, "false")
+}
+
+// compare compares numerically when both values are numbers, otherwise as case-insensitive text
+func compare(left, operator, right string) bool {
+	var cmp int
+	l, lerr := strconv.ParseFloat(strings.TrimSpace(left), 64)
+	r, rerr := strconv.ParseFloat(strings.TrimSpace(right), 64)
+	if lerr == nil && rerr == nil {
+		switch {
+		case l < r:
+			cmp = -1
+		case l > r:
+			cmp = 1
+		}
+	} else {
+		cmp = strings.Compare(strings.ToLower(left), strings.ToLower(right))
+	}
+
+	switch operator {
+	case "==":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	default:
+		return cmp >= 0
+	}
+}
+
+var operators = []string{"==", "!=", "<=", ">=", "<", ">"}
+
+// escapable are the characters which are used as is after a backslash
+const escapable = `$\,()"}`
+
+// stop is where the parser stopped reading nodes
+type stop int
+
+const (
+	stopEOF stop = iota
+	stopElse
+	stopEnd
+	stopComma
+	stopParen
+)
+
+type parser struct {
+	input  string
+	pos    int
+	lookup func(name, param string) (VariableI, string)
+	// lenient keeps the parts which can't be parsed as text instead of failing
+	lenient bool
+}
+
+func (p *parser) errorf(pos int, format string, a ...any) error {
+	return &TemplateError{Position: pos + 1, Message: fmt.Sprintf(format, a...)}
+}
+
+func (p *parser) eof() bool {
+	return p.pos >= len(p.input)
+}
+
+func (p *parser) skipSpaces() {
+	for !p.eof() && p.input[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+// parse reads nodes until the end of the input, a ${else} or ${end} when inBlock is set, or a top level comma
+// or closing parenthesis when inArgs is set
+func (p *parser) parse(inBlock, inArgs bool) ([]node, stop, error) {
+	var nodes []node
+	var text strings.Builder
+	depth := 0
+
+	flush := func() {
+		if text.Len() > 0 {
+			nodes = append(nodes, textNode(text.String()))
+			text.Reset()
+		}
+	}
+
+	for !p.eof() {
+		c := p.input[p.pos]
+
+		switch {
+		case c == '\\' && p.pos+1 < len(p.input) && strings.IndexByte(escapable, p.input[p.pos+1]) != -1:
+			text.WriteByte(p.input[p.pos+1])
+			p.pos += 2
+		case strings.HasPrefix(p.input[p.pos:], "${"):
+			start := p.pos
+			p.pos += 2
+
+			n, s, err := p.parseBlock(start, inBlock)
+			if err != nil && p.lenient {
+				// The ${ is kept as text and the parser goes on after it, so only the broken part stays as is
+				p.pos = start + 2
+				text.WriteString("${")
+				continue
+			} else if err != nil {
+				return nil, stopEOF, err
+			}
+
+			flush()
+			if s != stopEOF {
+				return nodes, s, nil
+			}
+			nodes = append(nodes, n)
+		case inArgs && c == '(':
+			depth++
+			text.WriteByte(c)
+			p.pos++
+		case inArgs && c == ')' && depth > 0:
+			depth--
+			text.WriteByte(c)
+			p.pos++
+		case inArgs && depth == 0 && (c == ',' || c == ')'):
+			p.pos++
+			nodes = append(nodes, textNode(strings.TrimRight(text.String(), " ")))
+			if c == ',' {
+				return nodes, stopComma, nil
+			}
+			return nodes, stopParen, nil
+		default:
+			text.WriteByte(c)
+			p.pos++
+		}
+	}
+
+	flush()
+	return nodes, stopEOF, nil
+}
+
+// parseBlock reads what follows a ${, which is a variable, an ${if} or the ${else} or ${end} of the block it's in
+func (p *parser) parseBlock(start int, inBlock bool) (node, stop, error) {
+	switch keyword := p.keyword(); keyword {
+	case "else", "end":
+		if !inBlock {
+			return nil, stopEOF, p.errorf(start, "${%v} without ${if}", keyword)
+		}
+		if keyword == "else" {
+			return nil, stopElse, nil
+		}
+		return nil, stopEnd, nil
+	case "if":
+		n, err := p.parseIf(start)
+		return n, stopEOF, err
+	default:
+		p.skipSpaces()
+		n, err := p.parseVariable(false)
+		if err != nil {
+			return nil, stopEOF, err
+		}
+		p.skipSpaces()
+		if p.eof() || p.input[p.pos] != '}' {
+			return nil, stopEOF, p.errorf(start, "missing } to close the variable")
+		}
+		p.pos++
+		return n, stopEOF, nil
+	}
+}
+
+// keyword checks whether a block keyword follows a ${ and consumes it, it returns an empty string otherwise
+func (p *parser) keyword() string {
+	rest := p.input[p.pos:]
+	for _, keyword := range []string{"else", "end"} {
+		after, ok := strings.CutPrefix(strings.TrimLeft(rest, " "), keyword)
+		if ok && strings.HasPrefix(strings.TrimLeft(after, " "), "}") {
+			p.pos = len(p.input) - len(strings.TrimLeft(after, " ")) + 1
+			return keyword
+		}
+	}
+
+	if after, ok := strings.CutPrefix(strings.TrimLeft(rest, " "), "if"); ok && strings.HasPrefix(after, " ") {
+		p.pos = len(p.input) - len(after)
+		return "if"
+	}
+
+	return ""
+}
+
+// parseVariable reads a variable name with its param and arguments. In conditions the param ends at a space
+func (p *parser) parseVariable(inCondition bool) (node, error) {
+	start := p.pos
+	for !p.eof() && isNameChar(p.input[p.pos]) {
+		p.pos++
+	}
+	name := p.input[start:p.pos]
+	if name == "" {
+		return nil, p.errorf(start, "expected a variable name")
+	}
+
+	var param string
+	if !p.eof() && p.input[p.pos] == '.' {
+		p.pos++
+		paramStart := p.pos
+		for !p.eof() && p.input[p.pos] != '(' && p.input[p.pos] != '}' && !(inCondition && p.input[p.pos] == ' ') {
+			p.pos++
+		}
+		param = p.input[paramStart:p.pos]
+	}
+
+	variable, param := p.lookup(name, param)
+	if variable == nil {
+		return nil, p.errorf(start, "unknown variable %v", name)
+	}
+	n := &variableNode{variable: variable, param: param}
+
+	if !p.eof() && p.input[p.pos] == '(' {
+		open := p.pos
+		p.pos++
+		args, err := p.parseArgs(open)
+		if err != nil {
+			return nil, err
+		}
+		n.args = args
+	}
+
+	return n, nil
+}
+
+func (p *parser) parseArgs(open int) ([][]node, error) {
+	var args [][]node
+
+	p.skipSpaces()
+	if !p.eof() && p.input[p.pos] == ')' {
+		p.pos++
+		return args, nil
+	}
+
+	for {
+		p.skipSpaces()
+
+		var arg []node
+		var s stop
+		if !p.eof() && p.input[p.pos] == '"' {
+			text, err := p.parseQuoted()
+			if err != nil {
+				return nil, err
+			}
+			arg = []node{textNode(text)}
+
+			p.skipSpaces()
+			if p.eof() || (p.input[p.pos] != ',' && p.input[p.pos] != ')') {
+				return nil, p.errorf(p.pos, "expected , or ) after a quoted argument")
+			}
+			s = stopComma
+			if p.input[p.pos] == ')' {
+				s = stopParen
+			}
+			p.pos++
+		} else {
+			var err error
+			arg, s, err = p.parse(false, true)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		args = append(args, arg)
+
+		switch s {
+		case stopParen:
+			return args, nil
+		case stopEOF:
+			return nil, p.errorf(open, "missing ) to close the arguments")
+		}
+	}
+}
+
+// parseQuoted reads a "quoted" text, backslashes escape the next character
+func (p *parser) parseQuoted() (string, error) {
+	start := p.pos
+	p.pos++
+
+	var sb strings.Builder
+	for !p.eof() {
+		c := p.input[p.pos]
+		switch {
+		case c == '\\' && p.pos+1 < len(p.input):
+			sb.WriteByte(p.input[p.pos+1])
+			p.pos += 2
+		case c == '"':
+			p.pos++
+			return sb.String(), nil
+		default:
+			sb.WriteByte(c)
+			p.pos++
+		}
+	}
+
+	return "", p.errorf(start, "missing closing quote")
+}
+
+func (p *parser) parseIf(start int) (node, error) {
+	n := &ifNode{}
+
+	p.skipSpaces()
+	if !p.eof() && p.input[p.pos] == '!' {
+		n.negate = true
+		p.pos++
+		p.skipSpaces()
+	}
+
+	var err error
+	if n.left, err = p.parseOperand(); err != nil {
+		return nil, err
+	}
+
+	p.skipSpaces()
+	for _, operator := range operators {
+		if strings.HasPrefix(p.input[p.pos:], operator) {
+			n.operator = operator
+			p.pos += len(operator)
+			p.skipSpaces()
+			if n.right, err = p.parseOperand(); err != nil {
+				return nil, err
+			}
+			p.skipSpaces()
+			break
+		}
+	}
+
+	if p.eof() || p.input[p.pos] != '}' {
+		return nil, p.errorf(p.pos, "expected } to close the condition")
+	}
+	p.pos++
+
+	var s stop
+	if n.then, s, err = p.parse(true, false); err != nil {
+		return nil, err
+	}
+	if s == stopElse {
+		if n.els, s, err = p.parse(true, false); err != nil {
+			return nil, err
+		}
+		if s == stopElse {
+			return nil, p.errorf(p.pos, "${if} can only have one ${else}")
+		}
+	}
+	if s != stopEnd {
+		return nil, p.errorf(start, "missing ${end} to close the ${if}")
+	}
+
+	return n, nil
+}
+
+// parseOperand reads a side of a condition, a "quoted" text, a number, ${variable} or a bare variable
+func (p *parser) parseOperand() ([]node, error) {
+	if p.eof() {
+		return nil, p.errorf(p.pos, "expected a condition")
+	}
+
+	switch c := p.input[p.pos]; {
+	case c == '"':
+		text, err := p.parseQuoted()
+		if err != nil {
+			return nil, err
+		}
+		return []node{textNode(text)}, nil
+	case c == '-' || (c >= '0' && c <= '9'):
+		start := p.pos
+		p.pos++
+		for !p.eof() && (p.input[p.pos] == '.' || (p.input[p.pos] >= '0' && p.input[p.pos] <= '9')) {
+			p.pos++
+		}
+		return []node{textNode(p.input[start:p.pos])}, nil
+	case strings.HasPrefix(p.input[p.pos:], "${"):
+		start := p.pos
+		p.pos += 2
+		p.skipSpaces()
+		n, err := p.parseVariable(false)
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpaces()
+		if p.eof() || p.input[p.pos] != '}' {
+			return nil, p.errorf(start, "missing } to close the variable")
+		}
+		p.pos++
+		return []node{n}, nil
+	default:
+		n, err := p.parseVariable(true)
+		if err != nil {
+			return nil, err
+		}
+		return []node{n}, nil
+	}
+}
+
+func isNameChar(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
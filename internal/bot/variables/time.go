@@ -0,0 +1,55 @@
+package variables
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/esfands/retpaladinbot/internal/global"
+)
+
+// TimeVariable is used as ${time.<tz>} or ${time(<tz>)} with an IANA time zone, e.g. ${time.America/Chicago}
+type TimeVariable struct {
+	gctx global.Context
+
+	name string
+}
+
+func NewTimeVariable(gctx global.Context) VariableI {
+	return &TimeVariable{
+		gctx: gctx,
+		name: "time",
+	}
+}
+
+func (v *TimeVariable) GetName() string {
+	return v.name
+}
+
+func (v *TimeVariable) GetAliases() []string {
+	return []string{}
+}
+
+func (v *TimeVariable) Description() string {
+	return "The current time in a time zone, e.g. ${time.America/Chicago}. Without a time zone it's in UTC."
+}
+
+func (v *TimeVariable) Code(_ context.Context, _ Scope, call Call) string {
+	param := call.Param
+	if param == "" {
+		param = strings.TrimSpace(call.Arg(0))
+	}
+
+	location := time.UTC
+	if param != "" {
+		var err error
+		location, err = time.LoadLocation(param)
+		if err != nil {
+			slog.Debug("[time-variable] unknown time zone", "timezone", param, "error", err.Error())
+			return ""
+		}
+	}
+
+	return time.Now().In(location).Format("03:04 PM MST")
+}
@@ -0,0 +1,116 @@
+package variables
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/esfands/retpaladinbot/internal/global"
+	helixservice "github.com/esfands/retpaladinbot/internal/services/helix"
+	"github.com/esfands/retpaladinbot/pkg/utils"
+	"github.com/nicklaw5/helix/v2"
+)
+
+type FollowageVariable struct {
+	gctx global.Context
+
+	name string
+}
+
+func NewFollowageVariable(gctx global.Context) VariableI {
+	return &FollowageVariable{
+		gctx: gctx,
+		name: "followage",
+	}
+}
+
+func (v *FollowageVariable) GetName() string {
+	return v.name
+}
+
+func (v *FollowageVariable) GetAliases() []string {
+	return []string{}
+}
+
+func (v *FollowageVariable) Description() string {
+	return "How long the user who used the command has followed the channel, or \"not following\". The broadcaster has to connect their account for it."
+}
+
+func (v *FollowageVariable) Code(ctx context.Context, scope Scope, _ Call) string {
+	// Only a moderator of the channel may see who follows it, which the token the broadcaster connected is
+	client, err := v.gctx.Crate().Helix.BroadcasterClient(ctx, scope.Channel.ID, "moderator:read:followers")
+	if errors.Is(err, helixservice.ErrNotConnected) || errors.Is(err, helixservice.ErrMissingScope) {
+		slog.Debug("[followage-variable] the broadcaster didn't authorize reading followers", "channel", scope.Channel.Name, "reason", err)
+		return "unavailable (the broadcaster has to connect their account)"
+	} else if err != nil {
+		slog.Error("[followage-variable] error getting the broadcaster client", "error", err.Error())
+		return ""
+	}
+
+	res, err := client.GetChannelFollows(&helix.GetChannelFollowsParams{
+		BroadcasterID: scope.Channel.ID,
+		UserID:        scope.User.ID,
+	})
+	if err != nil {
+		slog.Error("[followage-variable] error getting channel follows", "error", err.Error())
+		return ""
+	}
+
+	if res.Error != "" {
+		slog.Error("[followage-variable] Twitch API error while fetching followage", "error", res.ErrorMessage)
+		return ""
+	}
+
+	if len(res.Data.Channels) == 0 {
+		return "not following"
+	}
+
+	return utils.TimeDifference(res.Data.Channels[0].Followed.Time, time.Now(), true)
+}
+
+type AccountAgeVariable struct {
+	gctx global.Context
+
+	name string
+}
+
+func NewAccountAgeVariable(gctx global.Context) VariableI {
+	return &AccountAgeVariable{
+		gctx: gctx,
+		name: "accountage",
+	}
+}
+
+func (v *AccountAgeVariable) GetName() string {
+	return v.name
+}
+
+func (v *AccountAgeVariable) GetAliases() []string {
+	return []string{}
+}
+
+func (v *AccountAgeVariable) Description() string {
+	return "How old the account of the user who used the command is."
+}
+
+func (v *AccountAgeVariable) Code(_ context.Context, scope Scope, _ Call) string {
+	res, err := v.gctx.Crate().Helix.Client().GetUsers(&helix.UsersParams{
+		IDs: []string{scope.User.ID},
+	})
+	if err != nil {
+		slog.Error("[accountage-variable] error getting user", "error", err.Error())
+		return ""
+	}
+
+	if res.Error != "" {
+		slog.Error("[accountage-variable] Twitch API error while fetching account age", "error", res.ErrorMessage)
+		return ""
+	}
+
+	if len(res.Data.Users) == 0 {
+		return ""
+	}
+
+	return utils.TimeDifference(res.Data.Users[0].CreatedAt.Time, time.Now(), true)
+}
@@ -6,7 +6,6 @@ import (
 
 	"github.com/esfands/retpaladinbot/internal/global"
 	"github.com/esfands/retpaladinbot/pkg/utils"
-	"github.com/gempir/go-twitch-irc/v4"
 )
 
 type UserVariable struct {
@@ -30,8 +29,41 @@ func (v *UserVariable) GetAliases() []string {
 	return []string{}
 }
 
-func (v *UserVariable) Code(_ context.Context, user twitch.User, cmdContext []string, _ []string, _ string) string {
-	target := utils.GetTarget(user, cmdContext[1:])
+func (v *UserVariable) Description() string {
+	return "Mentions the user given as the first argument, or the user who used the command."
+}
+
+func (v *UserVariable) Code(_ context.Context, scope Scope, _ Call) string {
+	target := utils.GetTarget(scope.User, scope.Args)
 
 	return fmt.Sprintf("@%v", target)
 }
+
+type SenderVariable struct {
+	gctx global.Context
+
+	name string
+}
+
+func NewSenderVariable(gctx global.Context) VariableI {
+	return &SenderVariable{
+		gctx: gctx,
+		name: "sender",
+	}
+}
+
+func (v *SenderVariable) GetName() string {
+	return v.name
+}
+
+func (v *SenderVariable) GetAliases() []string {
+	return []string{}
+}
+
+func (v *SenderVariable) Description() string {
+	return "The display name of the user who used the command."
+}
+
+func (v *SenderVariable) Code(_ context.Context, scope Scope, _ Call) string {
+	return scope.User.DisplayName
+}
@@ -2,40 +2,84 @@ package variables
 
 import (
 	"context"
+	"log/slog"
+	"regexp"
 	"strings"
+	"sync"
 
 	"github.com/esfands/retpaladinbot/internal/global"
+	"github.com/esfands/retpaladinbot/pkg/domain"
 	"github.com/gempir/go-twitch-irc/v4"
 )
 
 type VariableI interface {
 	GetName() string
 	GetAliases() []string
-	Code(
-		ctx context.Context,
-		user twitch.User,
-		cmdContext []string,
-		context []string,
-		message string,
-	) string
+	// Description explains what the variable is replaced with, it's listed on the dashboard
+	Description() string
+	// Code returns the value the variable is replaced with
+	Code(ctx context.Context, scope Scope, call Call) string
+}
+
+// Call holds what a variable was given in a template
+type Call struct {
+	// Param is the text after the first dot of the variable, e.g. "1-100" in ${random.1-100}
+	Param string
+	// Args are the arguments of the variable with their variables filled in, e.g. a and b in ${random.pick(a, b)}
+	Args []string
+}
+
+// Arg returns an argument, or an empty string if it wasn't given
+func (c Call) Arg(i int) string {
+	if i < 0 || i >= len(c.Args) {
+		return ""
+	}
+	return c.Args[i]
+}
+
+// Scope holds what the variables of a message are filled in with
+type Scope struct {
+	Channel domain.Channel
+	// User is the user who triggered the message
+	User twitch.User
+	// Command is the name of the command the message is the response of
+	Command string
+	// Args are the arguments the command was called with
+	Args []string
+	// Event holds the values of the chat event the message responds to, e.g. the months of a resub
+	Event map[string]string
 }
 
 type Variable struct {
 	// Name is the name of the variable
-	Name string `json:"name"`
+	Name        string   `json:"name"`
+	Aliases     []string `json:"aliases"`
+	Description string   `json:"description"`
 }
 
+// maxCachedTemplates is how many parsed templates are kept before the cache is cleared
+const maxCachedTemplates = 1000
+
 type Service struct {
 	Variables []VariableI
+
+	mu    sync.Mutex
+	cache map[templateKey]*Template
+}
+
+// templateKey is what parsed templates are cached by, lenient templates are kept apart from the valid ones
+type templateKey struct {
+	message string
+	lenient bool
 }
 
 type ServiceI interface {
-	ParseVariables(
-		ctx context.Context,
-		user twitch.User,
-		cmdContext []string,
-		context []string,
-	) string
+	// ParseVariables fills in the variables of a template, the parts of it which can't be parsed are kept as they are
+	ParseVariables(ctx context.Context, scope Scope, message string) string
+	// Parse parses a template, it returns a *TemplateError explaining the problem when the template is invalid
+	Parse(message string) (*Template, error)
+	// List returns the registered variables
+	List() []Variable
 }
 
 // Adds a variable to the list of variables
@@ -44,23 +88,121 @@ func (s *Service) registerVariable(variable VariableI) {
 }
 
 func NewService(gctx global.Context) ServiceI {
-	svc := &Service{}
+	svc := &Service{
+		cache: make(map[templateKey]*Template),
+	}
 
 	svc.registerVariable(NewUserVariable(gctx))
+	svc.registerVariable(NewSenderVariable(gctx))
+	svc.registerVariable(NewArgsVariable(gctx))
+	svc.registerVariable(NewArgVariable(gctx))
+	svc.registerVariable(NewChannelVariable(gctx))
+	svc.registerVariable(NewCountVariable(gctx))
+	svc.registerVariable(NewCounterVariable(gctx))
+	svc.registerVariable(NewUptimeVariable(gctx))
+	svc.registerVariable(NewTitleVariable(gctx))
+	svc.registerVariable(NewGameVariable(gctx))
+	svc.registerVariable(NewRandomVariable(gctx))
+	svc.registerVariable(NewTimeVariable(gctx))
+	svc.registerVariable(NewFollowageVariable(gctx))
+	svc.registerVariable(NewAccountAgeVariable(gctx))
+	for _, variable := range NewEventVariables(gctx) {
+		svc.registerVariable(variable)
+	}
 
 	return svc
 }
 
-func (s *Service) ParseVariables(ctx context.Context, user twitch.User, cmdContext []string, context []string) string {
-	// Join the context into a single message string
-	message := strings.Join(context, " ")
+// argRegex matches the positional argument variables ${arg1}, ${arg2}, ...
+var argRegex = regexp.MustCompile(`^arg(\d+)$`)
+
+func (s *Service) ParseVariables(ctx context.Context, scope Scope, message string) string {
+	template, err := s.Parse(message)
+	if err != nil {
+		slog.Warn("Failed to parse template, keeping the broken parts as they are", "command", scope.Command, "channel", scope.Channel.Name, "error", err.Error())
+		template = s.parseLenient(message)
+	}
+
+	return template.Execute(ctx, scope)
+}
+
+func (s *Service) Parse(message string) (*Template, error) {
+	if template, ok := s.cached(templateKey{message: message}); ok {
+		return template, nil
+	}
+
+	p := &parser{input: message, lookup: s.lookup}
+	nodes, _, err := p.parse(false, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.store(templateKey{message: message}, &Template{nodes: nodes}), nil
+}
+
+// parseLenient parses a template which can't be parsed as a whole, the parts which are broken or use unknown
+// variables are kept as text
+func (s *Service) parseLenient(message string) *Template {
+	key := templateKey{message: message, lenient: true}
+	if template, ok := s.cached(key); ok {
+		return template
+	}
+
+	p := &parser{input: message, lookup: s.lookup, lenient: true}
+	// A lenient parser never fails
+	nodes, _, _ := p.parse(false, false)
+
+	return s.store(key, &Template{nodes: nodes})
+}
+
+func (s *Service) cached(key templateKey) (*Template, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	template, ok := s.cache[key]
+	return template, ok
+}
+
+func (s *Service) store(key templateKey, template *Template) *Template {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.cache) >= maxCachedTemplates {
+		clear(s.cache)
+	}
+	s.cache[key] = template
+
+	return template
+}
+
+func (s *Service) List() []Variable {
+	variables := make([]Variable, 0, len(s.Variables))
+	for _, variable := range s.Variables {
+		variables = append(variables, Variable{
+			Name:        variable.GetName(),
+			Aliases:     variable.GetAliases(),
+			Description: variable.Description(),
+		})
+	}
+	return variables
+}
+
+// lookup finds a variable by its name or alias, the param is changed for variables like ${arg1}
+func (s *Service) lookup(name, param string) (VariableI, string) {
+	if match := argRegex.FindStringSubmatch(name); match != nil && param == "" {
+		name, param = "arg", match[1]
+	}
 
-	// Iterate over the registered variables
 	for _, variable := range s.Variables {
-		// Replace occurrences of the variable in the message
-		placeholder := "${" + variable.GetName() + "}"
-		message = strings.ReplaceAll(message, placeholder, variable.Code(ctx, user, cmdContext, context, message))
+		if strings.EqualFold(variable.GetName(), name) {
+			return variable, param
+		}
+		for _, alias := range variable.GetAliases() {
+			if strings.EqualFold(alias, name) {
+				return variable, param
+			}
+		}
 	}
 
-	return message
+	return nil, ""
 }
@@ -0,0 +1,98 @@
+package cmdmanager
+
+import (
+	"encoding/json"
+
+	"github.com/esfands/retpaladinbot/internal/db"
+	"github.com/esfands/retpaladinbot/pkg/domain"
+	"github.com/esfands/retpaladinbot/pkg/utils"
+)
+
+// ToCustomCommand converts a stored custom command
+func ToCustomCommand(stored db.CustomCommand) (domain.CustomCommand, error) {
+	aliases, err := utils.ConvertJSONStringToSlice(stored.Aliases)
+	if err != nil {
+		return domain.CustomCommand{}, err
+	}
+
+	var permissions []domain.Permission
+	if err := json.Unmarshal([]byte(stored.Permissions), &permissions); err != nil {
+		return domain.CustomCommand{}, err
+	}
+
+	var responses []domain.CustomCommandResponse
+	if err := json.Unmarshal([]byte(stored.Responses), &responses); err != nil {
+		return domain.CustomCommand{}, err
+	}
+
+	return domain.CustomCommand{
+		ChannelID:      stored.ChannelID,
+		Name:           stored.Name,
+		Response:       stored.Response,
+		Responses:      responses,
+		ResponseMode:   domain.ResponseMode(stored.ResponseMode),
+		AvoidRepeats:   stored.AvoidRepeats,
+		Aliases:        aliases,
+		Permissions:    permissions,
+		GlobalCooldown: stored.GlobalCooldown,
+		UserCooldown:   stored.UserCooldown,
+		EnabledOffline: stored.EnabledOffline == 1,
+		EnabledOnline:  stored.EnabledOnline == 1,
+		Enabled:        stored.Enabled == 1,
+		UsageCount:     stored.UsageCount,
+		ThreadedReply:  stored.ThreadedReply == 1,
+	}, nil
+}
+
+// FromCustomCommand converts a custom command to be stored
+func FromCustomCommand(cmd domain.CustomCommand) (db.CustomCommand, error) {
+	if cmd.Aliases == nil {
+		cmd.Aliases = []string{}
+	}
+	if cmd.Permissions == nil {
+		cmd.Permissions = []domain.Permission{}
+	}
+
+	aliases, err := json.Marshal(cmd.Aliases)
+	if err != nil {
+		return db.CustomCommand{}, err
+	}
+
+	permissions, err := json.Marshal(cmd.Permissions)
+	if err != nil {
+		return db.CustomCommand{}, err
+	}
+
+	if cmd.Responses == nil {
+		cmd.Responses = []domain.CustomCommandResponse{}
+	}
+
+	responses, err := json.Marshal(cmd.Responses)
+	if err != nil {
+		return db.CustomCommand{}, err
+	}
+
+	// The response column keeps the first response for anything reading a single response
+	var response string
+	if len(cmd.Responses) > 0 {
+		response = cmd.Responses[0].Text
+	}
+
+	return db.CustomCommand{
+		ChannelID:      cmd.ChannelID,
+		Name:           cmd.Name,
+		Response:       response,
+		Aliases:        string(aliases),
+		Permissions:    string(permissions),
+		GlobalCooldown: cmd.GlobalCooldown,
+		UserCooldown:   cmd.UserCooldown,
+		EnabledOffline: utils.BoolToInt(cmd.EnabledOffline),
+		EnabledOnline:  utils.BoolToInt(cmd.EnabledOnline),
+		Enabled:        utils.BoolToInt(cmd.Enabled),
+		UsageCount:     cmd.UsageCount,
+		Responses:      string(responses),
+		ResponseMode:   string(cmd.ResponseMode),
+		AvoidRepeats:   cmd.AvoidRepeats,
+		ThreadedReply:  utils.BoolToInt(cmd.ThreadedReply),
+	}, nil
+}
@@ -0,0 +1,73 @@
+package cmdmanager
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/esfands/retpaladinbot/internal/db"
+	"github.com/esfands/retpaladinbot/pkg/utils"
+)
+
+// NameConflict returns which command of the channel already uses one of the names as its name or an alias, it's
+// empty when none of them are taken. The command the names are for, owner, is skipped, custom tells whether it's a
+// custom or default command
+func NameConflict(ctx context.Context, queries *db.Queries, channelID string, names []string, owner string, custom bool) (string, error) {
+	defaults, err := queries.GetAllDefaultCommands(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	overrides, err := GetOverrides(ctx, queries, channelID)
+	if err != nil {
+		return "", err
+	}
+
+	for _, command := range defaults {
+		if !custom && command.Name == owner {
+			continue
+		}
+
+		aliases, err := utils.ConvertJSONStringToSlice(command.Aliases)
+		if err != nil {
+			return "", err
+		}
+		taken := append(append([]string{command.Name}, aliases...), overrides[command.Name].Aliases...)
+
+		if name, ok := firstTaken(names, taken); ok {
+			return fmt.Sprintf("'%v' is already used by the default command '%v'", name, command.Name), nil
+		}
+	}
+
+	customs, err := queries.GetChannelCustomCommands(ctx, channelID)
+	if err != nil {
+		return "", err
+	}
+
+	for _, stored := range customs {
+		if custom && stored.Name == owner {
+			continue
+		}
+
+		command, err := ToCustomCommand(stored)
+		if err != nil {
+			return "", err
+		}
+		taken := append([]string{command.Name}, command.Aliases...)
+
+		if name, ok := firstTaken(names, taken); ok {
+			return fmt.Sprintf("'%v' is already used by the command '%v'", name, command.Name), nil
+		}
+	}
+
+	return "", nil
+}
+
+func firstTaken(names, taken []string) (string, bool) {
+	for _, name := range names {
+		if slices.Contains(taken, name) {
+			return name, true
+		}
+	}
+	return "", false
+}
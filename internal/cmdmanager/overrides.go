@@ -0,0 +1,144 @@
+package cmdmanager
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"github.com/esfands/retpaladinbot/internal/db"
+	"github.com/esfands/retpaladinbot/pkg/domain"
+)
+
+// GetOverrides returns the default command overrides of a channel by command name
+func GetOverrides(ctx context.Context, queries *db.Queries, channelID string) (map[string]domain.CommandOverride, error) {
+	stored, err := queries.GetChannelCommands(ctx, channelID)
+	if err != nil {
+		return nil, err
+	}
+
+	overrides := make(map[string]domain.CommandOverride, len(stored))
+	for _, command := range stored {
+		override, err := toOverride(command)
+		if err != nil {
+			return nil, err
+		}
+		overrides[override.Name] = override
+	}
+
+	return overrides, nil
+}
+
+// GetOverride returns the overrides of a default command in a channel, it's empty if nothing was changed
+func GetOverride(ctx context.Context, queries *db.Queries, channelID, name string) (domain.CommandOverride, error) {
+	stored, err := queries.GetChannelCommand(ctx, channelID, name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.CommandOverride{ChannelID: channelID, Name: name}, nil
+	} else if err != nil {
+		return domain.CommandOverride{}, err
+	}
+
+	return toOverride(stored)
+}
+
+// SaveOverride stores the overrides of a default command, replacing the ones stored before
+func SaveOverride(ctx context.Context, queries *db.Queries, override domain.CommandOverride) error {
+	command := db.ChannelCommand{
+		ChannelID:      override.ChannelID,
+		CommandName:    override.Name,
+		Enabled:        1,
+		GlobalCooldown: nullInt(override.GlobalCooldown),
+		UserCooldown:   nullInt(override.UserCooldown),
+		EnabledOnline:  nullBool(override.EnabledOnline),
+		EnabledOffline: nullBool(override.EnabledOffline),
+		ThreadedReply:  nullBool(override.ThreadedReply),
+	}
+
+	if override.Enabled != nil && !*override.Enabled {
+		command.Enabled = 0
+	}
+
+	if override.Permissions != nil {
+		permissions, err := json.Marshal(*override.Permissions)
+		if err != nil {
+			return err
+		}
+		command.Permissions = sql.NullString{String: string(permissions), Valid: true}
+	}
+
+	if len(override.Aliases) > 0 {
+		aliases, err := json.Marshal(override.Aliases)
+		if err != nil {
+			return err
+		}
+		command.Aliases = sql.NullString{String: string(aliases), Valid: true}
+	}
+
+	return queries.UpsertChannelCommand(ctx, command)
+}
+
+func toOverride(command db.ChannelCommand) (domain.CommandOverride, error) {
+	override := domain.CommandOverride{
+		ChannelID:      command.ChannelID,
+		Name:           command.CommandName,
+		GlobalCooldown: intPtr(command.GlobalCooldown),
+		UserCooldown:   intPtr(command.UserCooldown),
+		EnabledOnline:  boolPtr(command.EnabledOnline),
+		EnabledOffline: boolPtr(command.EnabledOffline),
+		ThreadedReply:  boolPtr(command.ThreadedReply),
+	}
+
+	if command.Enabled == 0 {
+		enabled := false
+		override.Enabled = &enabled
+	}
+
+	if command.Permissions.Valid {
+		var permissions []domain.Permission
+		if err := json.Unmarshal([]byte(command.Permissions.String), &permissions); err != nil {
+			return domain.CommandOverride{}, err
+		}
+		override.Permissions = &permissions
+	}
+
+	if command.Aliases.Valid {
+		if err := json.Unmarshal([]byte(command.Aliases.String), &override.Aliases); err != nil {
+			return domain.CommandOverride{}, err
+		}
+	}
+
+	return override, nil
+}
+
+func nullInt(value *int) sql.NullInt64 {
+	if value == nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: int64(*value), Valid: true}
+}
+
+func nullBool(value *bool) sql.NullInt64 {
+	if value == nil {
+		return sql.NullInt64{}
+	}
+	if *value {
+		return sql.NullInt64{Int64: 1, Valid: true}
+	}
+	return sql.NullInt64{Int64: 0, Valid: true}
+}
+
+func intPtr(value sql.NullInt64) *int {
+	if !value.Valid {
+		return nil
+	}
+	v := int(value.Int64)
+	return &v
+}
+
+func boolPtr(value sql.NullInt64) *bool {
+	if !value.Valid {
+		return nil
+	}
+	v := value.Int64 == 1
+	return &v
+}
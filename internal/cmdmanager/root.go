@@ -6,7 +6,9 @@ import "github.com/esfands/retpaladinbot/pkg/domain"
 type CommandManagerInterface interface {
 	AddCustomCommand(cmd domain.CustomCommand) error
 	UpdateCustomCommand(cmd domain.CustomCommand) error
-	DeleteCustomCommand(name string) error
-	CustomCommandExists(name string) bool
-	GetCustomCommands() []domain.CustomCommand
+	DeleteCustomCommand(channelID, name string) error
+	CustomCommandExists(channelID, name string) bool
+	GetCustomCommand(channelID, name string) (domain.CustomCommand, bool)
+	GetCustomCommands(channelID string) []domain.CustomCommand
+	GetDefaultCommand(name string) (domain.DefaultCommandInfo, bool)
 }
@@ -0,0 +1,109 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+)
+
+// Announcement is a message which is sent to a channel on a cron schedule
+type Announcement struct {
+	ID        int
+	ChannelID string
+	Cron      string
+	Timezone  string
+	Message   string
+	// Condition is always, live or offline
+	Condition string
+	Enabled   int
+	CreatedAt string
+}
+
+const announcementColumns = "id, channel_id, cron, timezone, message, condition, enabled, created_at"
+
+func scanAnnouncement(scanner interface{ Scan(dest ...any) error }) (Announcement, error) {
+	var a Announcement
+	err := scanner.Scan(&a.ID, &a.ChannelID, &a.Cron, &a.Timezone, &a.Message, &a.Condition, &a.Enabled, &a.CreatedAt)
+	return a, err
+}
+
+// InsertAnnouncement inserts a new announcement and returns its ID
+func (q *Queries) InsertAnnouncement(ctx context.Context, a Announcement) (int, error) {
+	stmt, err := q.db.Prepare(`INSERT INTO announcements (channel_id, cron, timezone, message, condition, enabled, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?) RETURNING id`)
+	if err != nil {
+		return 0, err
+	}
+	defer func(stmt *sql.Stmt) {
+		err := stmt.Close()
+		if err != nil {
+			slog.Error("Failed to close statement", "error", err)
+		}
+	}(stmt)
+
+	var id int
+	err = stmt.QueryRowContext(ctx, a.ChannelID, a.Cron, a.Timezone, a.Message, a.Condition, a.Enabled, a.CreatedAt).Scan(&id)
+	return id, err
+}
+
+// GetAnnouncement retrieves an announcement of a channel by ID
+func (q *Queries) GetAnnouncement(ctx context.Context, channelID string, id int) (Announcement, error) {
+	row := q.db.QueryRowContext(ctx, "SELECT "+announcementColumns+" FROM announcements WHERE channel_id = ? AND id = ?", channelID, id)
+	return scanAnnouncement(row)
+}
+
+// GetAllAnnouncements retrieves the announcements of every channel
+func (q *Queries) GetAllAnnouncements(ctx context.Context) ([]Announcement, error) {
+	return q.queryAnnouncements(ctx, "SELECT "+announcementColumns+" FROM announcements ORDER BY id")
+}
+
+// GetChannelAnnouncements retrieves the announcements of a channel
+func (q *Queries) GetChannelAnnouncements(ctx context.Context, channelID string) ([]Announcement, error) {
+	return q.queryAnnouncements(ctx, "SELECT "+announcementColumns+" FROM announcements WHERE channel_id = ? ORDER BY id", channelID)
+}
+
+func (q *Queries) queryAnnouncements(ctx context.Context, query string, args ...any) ([]Announcement, error) {
+	rows, err := q.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			slog.Error("Failed to close rows", "error", err)
+		}
+	}(rows)
+
+	var announcements []Announcement
+	for rows.Next() {
+		a, err := scanAnnouncement(rows)
+		if err != nil {
+			return nil, err
+		}
+		announcements = append(announcements, a)
+	}
+
+	return announcements, rows.Err()
+}
+
+// DeleteAnnouncement deletes an announcement of a channel and reports whether it existed
+func (q *Queries) DeleteAnnouncement(ctx context.Context, channelID string, id int) (bool, error) {
+	stmt, err := q.db.Prepare("DELETE FROM announcements WHERE channel_id = ? AND id = ?")
+	if err != nil {
+		return false, err
+	}
+	defer func(stmt *sql.Stmt) {
+		err := stmt.Close()
+		if err != nil {
+			slog.Error("Failed to close statement", "error", err)
+		}
+	}(stmt)
+
+	res, err := stmt.ExecContext(ctx, channelID, id)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	return affected > 0, err
+}
@@ -0,0 +1,265 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+)
+
+// Channel represents a Twitch channel the bot joins
+type Channel struct {
+	ID      string
+	Name    string
+	Prefix  string
+	Enabled int
+	// ThreadedReplies is whether command responses are sent as replies to the triggering message
+	ThreadedReplies int
+	// CooldownNotice is whether users are told how long a command is still on cooldown
+	CooldownNotice int
+	// CooldownBuckets is whether global cooldowns are kept separately for every badge tier
+	CooldownBuckets int
+}
+
+// InsertChannel inserts a new channel into the database, existing channels are left untouched
+func (q *Queries) InsertChannel(ctx context.Context, channel Channel) error {
+	stmt, err := q.db.Prepare("INSERT OR IGNORE INTO channels (id, name, prefix, enabled, threaded_replies) VALUES (?, ?, ?, ?, ?)")
+	if err != nil {
+		return err
+	}
+	defer func(stmt *sql.Stmt) {
+		err := stmt.Close()
+		if err != nil {
+			slog.Error("Failed to close statement", "error", err)
+		}
+	}(stmt)
+
+	_, err = stmt.Exec(channel.ID, channel.Name, channel.Prefix, channel.Enabled, channel.ThreadedReplies)
+	return err
+}
+
+// GetAllChannels retrieves all channels from the database
+func (q *Queries) GetAllChannels(ctx context.Context) ([]Channel, error) {
+	rows, err := q.db.QueryContext(ctx, "SELECT id, name, prefix, enabled, threaded_replies, cooldown_notice, cooldown_buckets FROM channels")
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			slog.Error("Failed to close rows", "error", err)
+		}
+	}(rows)
+
+	var channels []Channel
+	for rows.Next() {
+		var channel Channel
+		if err := rows.Scan(&channel.ID, &channel.Name, &channel.Prefix, &channel.Enabled, &channel.ThreadedReplies, &channel.CooldownNotice, &channel.CooldownBuckets); err != nil {
+			return nil, err
+		}
+		channels = append(channels, channel)
+	}
+	return channels, rows.Err()
+}
+
+// GetChannel retrieves a specific channel by its ID
+func (q *Queries) GetChannel(ctx context.Context, id string) (*Channel, error) {
+	var channel Channel
+	err := q.db.QueryRowContext(ctx, "SELECT id, name, prefix, enabled, threaded_replies, cooldown_notice, cooldown_buckets FROM channels WHERE id = ?", id).Scan(
+		&channel.ID, &channel.Name, &channel.Prefix, &channel.Enabled, &channel.ThreadedReplies, &channel.CooldownNotice, &channel.CooldownBuckets)
+	if err != nil {
+		return nil, err
+	}
+	return &channel, nil
+}
+
+// GetChannelByName retrieves a specific channel by its login name
+func (q *Queries) GetChannelByName(ctx context.Context, name string) (*Channel, error) {
+	var channel Channel
+	err := q.db.QueryRowContext(ctx, "SELECT id, name, prefix, enabled, threaded_replies, cooldown_notice, cooldown_buckets FROM channels WHERE name = ?", name).Scan(
+		&channel.ID, &channel.Name, &channel.Prefix, &channel.Enabled, &channel.ThreadedReplies, &channel.CooldownNotice, &channel.CooldownBuckets)
+	if err != nil {
+		return nil, err
+	}
+	return &channel, nil
+}
+
+// UpdateChannelPrefix updates the command prefix of a channel
+func (q *Queries) UpdateChannelPrefix(ctx context.Context, channelID, prefix string) error {
+	stmt, err := q.db.Prepare("UPDATE channels SET prefix = ? WHERE id = ?")
+	if err != nil {
+		return err
+	}
+	defer func(stmt *sql.Stmt) {
+		err := stmt.Close()
+		if err != nil {
+			slog.Error("Failed to close statement", "error", err)
+		}
+	}(stmt)
+
+	_, err = stmt.Exec(prefix, channelID)
+	return err
+}
+
+// UpdateChannelEnabled sets whether the bot joins a channel
+func (q *Queries) UpdateChannelEnabled(ctx context.Context, channelID string, enabled int) error {
+	stmt, err := q.db.Prepare("UPDATE channels SET enabled = ? WHERE id = ?")
+	if err != nil {
+		return err
+	}
+	defer func(stmt *sql.Stmt) {
+		err := stmt.Close()
+		if err != nil {
+			slog.Error("Failed to close statement", "error", err)
+		}
+	}(stmt)
+
+	_, err = stmt.Exec(enabled, channelID)
+	return err
+}
+
+// UpdateChannelThreadedReplies sets whether command responses in a channel are sent as threaded replies
+func (q *Queries) UpdateChannelThreadedReplies(ctx context.Context, channelID string, threadedReplies int) error {
+	stmt, err := q.db.Prepare("UPDATE channels SET threaded_replies = ? WHERE id = ?")
+	if err != nil {
+		return err
+	}
+	defer func(stmt *sql.Stmt) {
+		err := stmt.Close()
+		if err != nil {
+			slog.Error("Failed to close statement", "error", err)
+		}
+	}(stmt)
+
+	_, err = stmt.Exec(threadedReplies, channelID)
+	return err
+}
+
+// UpdateChannelCooldownNotice sets whether users in a channel are told how long a command is still on cooldown
+func (q *Queries) UpdateChannelCooldownNotice(ctx context.Context, channelID string, cooldownNotice int) error {
+	stmt, err := q.db.Prepare("UPDATE channels SET cooldown_notice = ? WHERE id = ?")
+	if err != nil {
+		return err
+	}
+	defer func(stmt *sql.Stmt) {
+		err := stmt.Close()
+		if err != nil {
+			slog.Error("Failed to close statement", "error", err)
+		}
+	}(stmt)
+
+	_, err = stmt.Exec(cooldownNotice, channelID)
+	return err
+}
+
+// UpdateChannelCooldownBuckets sets whether global cooldowns in a channel are kept separately for every badge tier
+func (q *Queries) UpdateChannelCooldownBuckets(ctx context.Context, channelID string, cooldownBuckets int) error {
+	stmt, err := q.db.Prepare("UPDATE channels SET cooldown_buckets = ? WHERE id = ?")
+	if err != nil {
+		return err
+	}
+	defer func(stmt *sql.Stmt) {
+		err := stmt.Close()
+		if err != nil {
+			slog.Error("Failed to close statement", "error", err)
+		}
+	}(stmt)
+
+	_, err = stmt.Exec(cooldownBuckets, channelID)
+	return err
+}
+
+// ChannelCommand represents the per-channel overrides of a default command, null columns keep the default
+type ChannelCommand struct {
+	ChannelID      string
+	CommandName    string
+	Enabled        int
+	GlobalCooldown sql.NullInt64
+	UserCooldown   sql.NullInt64
+	// Permissions is a JSON array of permissions
+	Permissions    sql.NullString
+	EnabledOnline  sql.NullInt64
+	EnabledOffline sql.NullInt64
+	// Aliases is a JSON array of aliases added on top of the default ones
+	Aliases       sql.NullString
+	ThreadedReply sql.NullInt64
+}
+
+const channelCommandColumns = "channel_id, command_name, enabled, global_cooldown, user_cooldown, permissions, enabled_online, enabled_offline, aliases, threaded_reply"
+
+func scanChannelCommand(row interface{ Scan(dest ...any) error }) (ChannelCommand, error) {
+	var command ChannelCommand
+	err := row.Scan(
+		&command.ChannelID, &command.CommandName, &command.Enabled, &command.GlobalCooldown, &command.UserCooldown,
+		&command.Permissions, &command.EnabledOnline, &command.EnabledOffline, &command.Aliases, &command.ThreadedReply,
+	)
+	return command, err
+}
+
+// GetChannelCommands retrieves the default command overrides of a channel
+func (q *Queries) GetChannelCommands(ctx context.Context, channelID string) ([]ChannelCommand, error) {
+	rows, err := q.db.QueryContext(ctx, "SELECT "+channelCommandColumns+" FROM channel_commands WHERE channel_id = ?", channelID)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			slog.Error("Failed to close rows", "error", err)
+		}
+	}(rows)
+
+	var commands []ChannelCommand
+	for rows.Next() {
+		command, err := scanChannelCommand(rows)
+		if err != nil {
+			return nil, err
+		}
+		commands = append(commands, command)
+	}
+	return commands, rows.Err()
+}
+
+// GetChannelCommand retrieves the overrides of a single default command in a channel
+func (q *Queries) GetChannelCommand(ctx context.Context, channelID, name string) (ChannelCommand, error) {
+	row := q.db.QueryRowContext(ctx, "SELECT "+channelCommandColumns+" FROM channel_commands WHERE channel_id = ? AND command_name = ?", channelID, name)
+	return scanChannelCommand(row)
+}
+
+// UpsertChannelCommand inserts or replaces the overrides of a default command in a channel
+func (q *Queries) UpsertChannelCommand(ctx context.Context, command ChannelCommand) error {
+	stmt, err := q.db.Prepare(
+		"INSERT OR REPLACE INTO channel_commands (" + channelCommandColumns + ") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+	)
+	if err != nil {
+		return err
+	}
+	defer func(stmt *sql.Stmt) {
+		err := stmt.Close()
+		if err != nil {
+			slog.Error("Failed to close statement", "error", err)
+		}
+	}(stmt)
+
+	_, err = stmt.Exec(
+		command.ChannelID, command.CommandName, command.Enabled, command.GlobalCooldown, command.UserCooldown,
+		command.Permissions, command.EnabledOnline, command.EnabledOffline, command.Aliases, command.ThreadedReply,
+	)
+	return err
+}
+
+// DeleteChannelCommand removes every override of a default command in a channel
+func (q *Queries) DeleteChannelCommand(ctx context.Context, channelID, name string) error {
+	stmt, err := q.db.Prepare("DELETE FROM channel_commands WHERE channel_id = ? AND command_name = ?")
+	if err != nil {
+		return err
+	}
+	defer func(stmt *sql.Stmt) {
+		err := stmt.Close()
+		if err != nil {
+			slog.Error("Failed to close statement", "error", err)
+		}
+	}(stmt)
+
+	_, err = stmt.Exec(channelID, name)
+	return err
+}
@@ -7,14 +7,40 @@ import (
 )
 
 type CustomCommand struct {
-	Name       string
-	Response   string
-	UsageCount int
+	ChannelID      string
+	Name           string
+	Response       string
+	UsageCount     int
+	Aliases        string
+	Permissions    string
+	GlobalCooldown int
+	UserCooldown   int
+	EnabledOffline int
+	EnabledOnline  int
+	Enabled        int
+	// Responses is a JSON list of the responses and their weights, Response holds the first one
+	Responses    string
+	ResponseMode string
+	AvoidRepeats int
+	// ThreadedReply is whether the response is sent as a reply to the message which used the command
+	ThreadedReply int
+}
+
+const customCommandColumns = "channel_id, name, response, usage_count, aliases, permissions, global_cooldown, user_cooldown, enabled_offline, enabled_online, enabled, responses, response_mode, avoid_repeats, threaded_reply"
+
+func scanCustomCommand(row interface{ Scan(dest ...any) error }) (CustomCommand, error) {
+	var command CustomCommand
+	err := row.Scan(
+		&command.ChannelID, &command.Name, &command.Response, &command.UsageCount, &command.Aliases, &command.Permissions,
+		&command.GlobalCooldown, &command.UserCooldown, &command.EnabledOffline, &command.EnabledOnline, &command.Enabled,
+		&command.Responses, &command.ResponseMode, &command.AvoidRepeats, &command.ThreadedReply,
+	)
+	return command, err
 }
 
 // InsertCustomCommand inserts a new custom command into the database
 func (q *Queries) InsertCustomCommand(ctx context.Context, command CustomCommand) error {
-	stmt, err := q.db.Prepare("INSERT INTO custom_commands (name, response, usage_count) VALUES (?, ?, ?)")
+	stmt, err := q.db.Prepare("INSERT INTO custom_commands (" + customCommandColumns + ") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)")
 	if err != nil {
 		return err
 	}
@@ -25,7 +51,11 @@ func (q *Queries) InsertCustomCommand(ctx context.Context, command CustomCommand
 		}
 	}(stmt)
 
-	_, err = stmt.Exec(command.Name, command.Response, command.UsageCount)
+	_, err = stmt.Exec(
+		command.ChannelID, command.Name, command.Response, command.UsageCount, command.Aliases, command.Permissions,
+		command.GlobalCooldown, command.UserCooldown, command.EnabledOffline, command.EnabledOnline, command.Enabled,
+		command.Responses, command.ResponseMode, command.AvoidRepeats, command.ThreadedReply,
+	)
 	if err != nil {
 		return err
 	}
@@ -33,9 +63,11 @@ func (q *Queries) InsertCustomCommand(ctx context.Context, command CustomCommand
 	return nil
 }
 
-// UpdateCustomCommand updates an existing custom command in the database
+// UpdateCustomCommand updates the responses and settings of an existing custom command in the database
 func (q *Queries) UpdateCustomCommand(ctx context.Context, command CustomCommand) error {
-	stmt, err := q.db.Prepare("UPDATE custom_commands SET response = ? WHERE name = ?")
+	stmt, err := q.db.Prepare(
+		"UPDATE custom_commands SET response = ?, aliases = ?, permissions = ?, global_cooldown = ?, user_cooldown = ?, enabled_offline = ?, enabled_online = ?, enabled = ?, responses = ?, response_mode = ?, avoid_repeats = ?, threaded_reply = ? WHERE channel_id = ? AND name = ?",
+	)
 	if err != nil {
 		return err
 	}
@@ -46,7 +78,11 @@ func (q *Queries) UpdateCustomCommand(ctx context.Context, command CustomCommand
 		}
 	}(stmt)
 
-	_, err = stmt.Exec(command.Response, command.Name)
+	_, err = stmt.Exec(
+		command.Response, command.Aliases, command.Permissions, command.GlobalCooldown, command.UserCooldown,
+		command.EnabledOffline, command.EnabledOnline, command.Enabled, command.Responses, command.ResponseMode,
+		command.AvoidRepeats, command.ThreadedReply, command.ChannelID, command.Name,
+	)
 	if err != nil {
 		return err
 	}
@@ -54,8 +90,8 @@ func (q *Queries) UpdateCustomCommand(ctx context.Context, command CustomCommand
 	return nil
 }
 
-func (q *Queries) IncrementCustomCommandUsageCount(ctx context.Context, name string) error {
-	stmt, err := q.db.Prepare("UPDATE custom_commands SET usage_count = usage_count + 1 WHERE name = ?")
+func (q *Queries) IncrementCustomCommandUsageCount(ctx context.Context, channelID, name string) error {
+	stmt, err := q.db.Prepare("UPDATE custom_commands SET usage_count = usage_count + 1 WHERE channel_id = ? AND name = ?")
 	if err != nil {
 		return err
 	}
@@ -66,7 +102,7 @@ func (q *Queries) IncrementCustomCommandUsageCount(ctx context.Context, name str
 		}
 	}(stmt)
 
-	_, err = stmt.Exec(name)
+	_, err = stmt.Exec(channelID, name)
 	if err != nil {
 		return err
 	}
@@ -75,8 +111,8 @@ func (q *Queries) IncrementCustomCommandUsageCount(ctx context.Context, name str
 }
 
 // DeleteCustomCommand deletes a custom command from the database
-func (q *Queries) DeleteCustomCommand(ctx context.Context, name string) error {
-	stmt, err := q.db.Prepare("DELETE FROM custom_commands WHERE name = ?")
+func (q *Queries) DeleteCustomCommand(ctx context.Context, channelID, name string) error {
+	stmt, err := q.db.Prepare("DELETE FROM custom_commands WHERE channel_id = ? AND name = ?")
 	if err != nil {
 		return err
 	}
@@ -87,7 +123,7 @@ func (q *Queries) DeleteCustomCommand(ctx context.Context, name string) error {
 		}
 	}(stmt)
 
-	_, err = stmt.Exec(name)
+	_, err = stmt.Exec(channelID, name)
 	if err != nil {
 		return err
 	}
@@ -95,9 +131,9 @@ func (q *Queries) DeleteCustomCommand(ctx context.Context, name string) error {
 	return nil
 }
 
-// GetAllCustomCommands retrieves all custom commands from the database
+// GetAllCustomCommands retrieves all custom commands of every channel from the database
 func (q *Queries) GetAllCustomCommands(ctx context.Context) ([]CustomCommand, error) {
-	rows, err := q.db.QueryContext(ctx, "SELECT name, response, usage_count FROM custom_commands")
+	rows, err := q.db.QueryContext(ctx, "SELECT "+customCommandColumns+" FROM custom_commands")
 	if err != nil {
 		return nil, err
 	}
@@ -110,8 +146,32 @@ func (q *Queries) GetAllCustomCommands(ctx context.Context) ([]CustomCommand, er
 
 	var commands []CustomCommand
 	for rows.Next() {
-		var command CustomCommand
-		if err := rows.Scan(&command.Name, &command.Response, &command.UsageCount); err != nil {
+		command, err := scanCustomCommand(rows)
+		if err != nil {
+			return nil, err
+		}
+		commands = append(commands, command)
+	}
+	return commands, rows.Err()
+}
+
+// GetChannelCustomCommands retrieves all custom commands of a channel from the database
+func (q *Queries) GetChannelCustomCommands(ctx context.Context, channelID string) ([]CustomCommand, error) {
+	rows, err := q.db.QueryContext(ctx, "SELECT "+customCommandColumns+" FROM custom_commands WHERE channel_id = ?", channelID)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			slog.Error("Failed to close rows", "error", err)
+		}
+	}(rows)
+
+	var commands []CustomCommand
+	for rows.Next() {
+		command, err := scanCustomCommand(rows)
+		if err != nil {
 			return nil, err
 		}
 		commands = append(commands, command)
@@ -120,9 +180,9 @@ func (q *Queries) GetAllCustomCommands(ctx context.Context) ([]CustomCommand, er
 }
 
 // GetCustomCommand retrieves a specific custom command from the database
-func (q *Queries) GetCustomCommand(ctx context.Context, name string) (CustomCommand, error) {
-	var command CustomCommand
-	err := q.db.QueryRowContext(ctx, "SELECT name, response, usage_count FROM custom_commands WHERE name = ?", name).Scan(&command.Name, &command.Response, &command.UsageCount)
+func (q *Queries) GetCustomCommand(ctx context.Context, channelID, name string) (CustomCommand, error) {
+	row := q.db.QueryRowContext(ctx, "SELECT "+customCommandColumns+" FROM custom_commands WHERE channel_id = ? AND name = ?", channelID, name)
+	command, err := scanCustomCommand(row)
 	if err != nil {
 		return CustomCommand{}, err
 	}
@@ -269,9 +329,9 @@ func (q *Queries) GetDefaultCommandByName(ctx context.Context, name string) (*De
 	return &cmd, nil
 }
 
-func (q *Queries) GetCustomCommandByName(ctx context.Context, name string) (*CustomCommand, error) {
-	var cmd CustomCommand
-	err := q.db.QueryRowContext(ctx, "SELECT name, response, usage_count FROM custom_commands WHERE name = ?", name).Scan(&cmd.Name, &cmd.Response, &cmd.UsageCount)
+func (q *Queries) GetCustomCommandByName(ctx context.Context, channelID, name string) (*CustomCommand, error) {
+	row := q.db.QueryRowContext(ctx, "SELECT "+customCommandColumns+" FROM custom_commands WHERE channel_id = ? AND name = ?", channelID, name)
+	cmd, err := scanCustomCommand(row)
 	if err != nil {
 		return nil, err
 	}
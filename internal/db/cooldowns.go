@@ -0,0 +1,114 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+)
+
+// Cooldown represents a running cooldown, an empty user is the global cooldown of the command
+type Cooldown struct {
+	ChannelID string
+	Command   string
+	User      string
+	Bucket    string
+	// ExpiresAt is a unix timestamp in milliseconds
+	ExpiresAt int64
+}
+
+// AcquireCooldown stores a cooldown unless one is still running for the same key, it reports whether it was stored
+func (q *Queries) AcquireCooldown(ctx context.Context, cooldown Cooldown, now int64) (bool, error) {
+	stmt, err := q.db.Prepare(`INSERT INTO cooldowns (channel_id, command, user, bucket, expires_at) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (channel_id, command, user, bucket) DO UPDATE SET expires_at = excluded.expires_at WHERE cooldowns.expires_at <= ?`)
+	if err != nil {
+		return false, err
+	}
+	defer func(stmt *sql.Stmt) {
+		err := stmt.Close()
+		if err != nil {
+			slog.Error("Failed to close statement", "error", err)
+		}
+	}(stmt)
+
+	res, err := stmt.ExecContext(ctx, cooldown.ChannelID, cooldown.Command, cooldown.User, cooldown.Bucket, cooldown.ExpiresAt, now)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+// GetCooldownExpiry retrieves when a cooldown expires as a unix timestamp in milliseconds
+func (q *Queries) GetCooldownExpiry(ctx context.Context, cooldown Cooldown) (int64, error) {
+	var expiresAt int64
+	err := q.db.QueryRowContext(
+		ctx,
+		"SELECT expires_at FROM cooldowns WHERE channel_id = ? AND command = ? AND user = ? AND bucket = ?",
+		cooldown.ChannelID, cooldown.Command, cooldown.User, cooldown.Bucket,
+	).Scan(&expiresAt)
+	return expiresAt, err
+}
+
+// DeleteCooldowns deletes the cooldowns matching the filters, empty filters match everything.
+// It returns how many of the deleted cooldowns were still running.
+func (q *Queries) DeleteCooldowns(ctx context.Context, channelID, command, user string, now int64) (int64, error) {
+	var where string
+	var params []any
+
+	filters := []struct {
+		column string
+		value  string
+	}{
+		{"channel_id", channelID},
+		{"command", command},
+		{"user", user},
+	}
+	for _, filter := range filters {
+		if filter.value != "" {
+			where += " AND " + filter.column + " = ?"
+			params = append(params, filter.value)
+		}
+	}
+
+	var running int64
+	err := q.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM cooldowns WHERE expires_at > ?"+where, append([]any{now}, params...)...).Scan(&running)
+	if err != nil {
+		return 0, err
+	}
+
+	stmt, err := q.db.Prepare("DELETE FROM cooldowns WHERE 1 = 1" + where)
+	if err != nil {
+		return 0, err
+	}
+	defer func(stmt *sql.Stmt) {
+		err := stmt.Close()
+		if err != nil {
+			slog.Error("Failed to close statement", "error", err)
+		}
+	}(stmt)
+
+	_, err = stmt.ExecContext(ctx, params...)
+	return running, err
+}
+
+// DeleteExpiredCooldowns deletes every cooldown which expired before now
+func (q *Queries) DeleteExpiredCooldowns(ctx context.Context, now int64) error {
+	stmt, err := q.db.Prepare("DELETE FROM cooldowns WHERE expires_at <= ?")
+	if err != nil {
+		return err
+	}
+	defer func(stmt *sql.Stmt) {
+		err := stmt.Close()
+		if err != nil {
+			slog.Error("Failed to close statement", "error", err)
+		}
+	}(stmt)
+
+	_, err = stmt.ExecContext(ctx, now)
+	return err
+}
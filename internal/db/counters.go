@@ -0,0 +1,120 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+)
+
+// Counter is a named number owned by a custom command, e.g. the deaths of !deaths
+type Counter struct {
+	ChannelID string
+	Name      string
+	Value     int
+	// ResetOnStream is whether the counter goes back to 0 when the stream goes online
+	ResetOnStream int
+}
+
+// GetCounter retrieves a counter of a channel by name
+func (q *Queries) GetCounter(ctx context.Context, channelID, name string) (Counter, error) {
+	var counter Counter
+	err := q.db.QueryRowContext(
+		ctx,
+		"SELECT channel_id, name, value, reset_on_stream FROM counters WHERE channel_id = ? AND name = ?",
+		channelID, name,
+	).Scan(&counter.ChannelID, &counter.Name, &counter.Value, &counter.ResetOnStream)
+	return counter, err
+}
+
+// AddToCounter adds to the value of a counter, creating it if it doesn't exist, and returns the new value
+func (q *Queries) AddToCounter(ctx context.Context, channelID, name string, amount int) (int, error) {
+	stmt, err := q.db.Prepare(`INSERT INTO counters (channel_id, name, value) VALUES (?, ?, ?)
+		ON CONFLICT (channel_id, name) DO UPDATE SET value = counters.value + excluded.value RETURNING value`)
+	if err != nil {
+		return 0, err
+	}
+	defer func(stmt *sql.Stmt) {
+		err := stmt.Close()
+		if err != nil {
+			slog.Error("Failed to close statement", "error", err)
+		}
+	}(stmt)
+
+	var value int
+	err = stmt.QueryRowContext(ctx, channelID, name, amount).Scan(&value)
+	return value, err
+}
+
+// SetCounter sets the value of a counter, creating it if it doesn't exist
+func (q *Queries) SetCounter(ctx context.Context, channelID, name string, value int) error {
+	stmt, err := q.db.Prepare(`INSERT INTO counters (channel_id, name, value) VALUES (?, ?, ?)
+		ON CONFLICT (channel_id, name) DO UPDATE SET value = excluded.value`)
+	if err != nil {
+		return err
+	}
+	defer func(stmt *sql.Stmt) {
+		err := stmt.Close()
+		if err != nil {
+			slog.Error("Failed to close statement", "error", err)
+		}
+	}(stmt)
+
+	_, err = stmt.ExecContext(ctx, channelID, name, value)
+	return err
+}
+
+// UpdateCounterResetOnStream sets whether a counter is reset when the stream goes online, creating it if it doesn't exist
+func (q *Queries) UpdateCounterResetOnStream(ctx context.Context, channelID, name string, resetOnStream int) error {
+	stmt, err := q.db.Prepare(`INSERT INTO counters (channel_id, name, reset_on_stream) VALUES (?, ?, ?)
+		ON CONFLICT (channel_id, name) DO UPDATE SET reset_on_stream = excluded.reset_on_stream`)
+	if err != nil {
+		return err
+	}
+	defer func(stmt *sql.Stmt) {
+		err := stmt.Close()
+		if err != nil {
+			slog.Error("Failed to close statement", "error", err)
+		}
+	}(stmt)
+
+	_, err = stmt.ExecContext(ctx, channelID, name, resetOnStream)
+	return err
+}
+
+// ResetStreamCounters sets the counters of a channel which reset on stream back to 0 and returns how many were reset
+func (q *Queries) ResetStreamCounters(ctx context.Context, channelID string) (int64, error) {
+	stmt, err := q.db.Prepare("UPDATE counters SET value = 0 WHERE channel_id = ? AND reset_on_stream = 1")
+	if err != nil {
+		return 0, err
+	}
+	defer func(stmt *sql.Stmt) {
+		err := stmt.Close()
+		if err != nil {
+			slog.Error("Failed to close statement", "error", err)
+		}
+	}(stmt)
+
+	res, err := stmt.ExecContext(ctx, channelID)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+// DeleteCounter deletes a counter of a channel
+func (q *Queries) DeleteCounter(ctx context.Context, channelID, name string) error {
+	stmt, err := q.db.Prepare("DELETE FROM counters WHERE channel_id = ? AND name = ?")
+	if err != nil {
+		return err
+	}
+	defer func(stmt *sql.Stmt) {
+		err := stmt.Close()
+		if err != nil {
+			slog.Error("Failed to close statement", "error", err)
+		}
+	}(stmt)
+
+	_, err = stmt.ExecContext(ctx, channelID, name)
+	return err
+}
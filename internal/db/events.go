@@ -0,0 +1,49 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+)
+
+// ChannelEvent is an EventSub notification of a channel, e.g. a follow or a cheer
+type ChannelEvent struct {
+	ID int
+	// MessageID is the ID Twitch gave the notification
+	MessageID string
+	ChannelID string
+	Type      string
+	// UserID and UserName are who caused the event, they're empty for events like hype trains
+	UserID   sql.NullString
+	UserName sql.NullString
+	// Payload is the event object of the notification as JSON
+	Payload    string
+	ReceivedAt string
+}
+
+// InsertChannelEvent stores an EventSub notification of a channel
+func (q *Queries) InsertChannelEvent(ctx context.Context, event ChannelEvent) error {
+	stmt, err := q.db.Prepare(`INSERT INTO events (message_id, channel_id, type, user_id, user_name, payload, received_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer func(stmt *sql.Stmt) {
+		err := stmt.Close()
+		if err != nil {
+			slog.Error("Failed to close statement", "error", err)
+		}
+	}(stmt)
+
+	_, err = stmt.ExecContext(
+		ctx,
+		event.MessageID,
+		event.ChannelID,
+		event.Type,
+		event.UserID,
+		event.UserName,
+		event.Payload,
+		event.ReceivedAt,
+	)
+	return err
+}
@@ -8,6 +8,7 @@ import (
 
 type StreamStatus struct {
 	ID        string
+	ChannelID string
 	StreamID  string
 	GameID    sql.NullString
 	GameName  sql.NullString
@@ -17,9 +18,11 @@ type StreamStatus struct {
 	EndedAt   sql.NullString
 }
 
+const streamStatusColumns = "id, channel_id, stream_id, game_id, game_name, live, title, started_at, ended_at"
+
 // InsertStream inserts a new stream into the database
 func (q *Queries) InsertStream(ctx context.Context, stream StreamStatus) error {
-	stmt, err := q.db.Prepare("INSERT INTO stream_status (stream_id, game_id, game_name, live, title, started_at, ended_at) VALUES (?, ?, ?, ?, ?, ?, ?)")
+	stmt, err := q.db.Prepare("INSERT INTO stream_status (channel_id, stream_id, game_id, game_name, live, title, started_at, ended_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)")
 	if err != nil {
 		return err
 	}
@@ -31,6 +34,7 @@ func (q *Queries) InsertStream(ctx context.Context, stream StreamStatus) error {
 	}(stmt)
 
 	_, err = stmt.Exec(
+		stream.ChannelID,
 		stream.StreamID,
 		stream.GameID,
 		stream.GameName,
@@ -46,11 +50,12 @@ func (q *Queries) InsertStream(ctx context.Context, stream StreamStatus) error {
 	return nil
 }
 
-// GetLiveStream returns the currently live stream
-func (q *Queries) GetLiveStream(ctx context.Context) (StreamStatus, error) {
+// GetLiveStream returns the currently live stream of a channel
+func (q *Queries) GetLiveStream(ctx context.Context, channelID string) (StreamStatus, error) {
 	var stream StreamStatus
-	err := q.db.QueryRow("SELECT * FROM stream_status WHERE live = 1").Scan(
+	err := q.db.QueryRow("SELECT "+streamStatusColumns+" FROM stream_status WHERE live = 1 AND channel_id = ?", channelID).Scan(
 		&stream.ID,
+		&stream.ChannelID,
 		&stream.StreamID,
 		&stream.GameID,
 		&stream.GameName,
@@ -87,11 +92,12 @@ func (q *Queries) StreamWentOffline(ctx context.Context, streamID string, timeWe
 	return nil
 }
 
-// GetMostRecentStreamStatus returns the most recent stream based off the the `id` which keeps track of the most recent stream
-func (q *Queries) GetMostRecentStreamStatus(ctx context.Context) (StreamStatus, error) {
+// GetMostRecentStreamStatus returns the most recent stream of a channel based off the the `id` which keeps track of the most recent stream
+func (q *Queries) GetMostRecentStreamStatus(ctx context.Context, channelID string) (StreamStatus, error) {
 	var stream StreamStatus
-	err := q.db.QueryRow("SELECT * FROM stream_status ORDER BY id DESC LIMIT 1").Scan(
+	err := q.db.QueryRow("SELECT "+streamStatusColumns+" FROM stream_status WHERE channel_id = ? ORDER BY id DESC LIMIT 1", channelID).Scan(
 		&stream.ID,
+		&stream.ChannelID,
 		&stream.StreamID,
 		&stream.GameID,
 		&stream.GameName,
@@ -109,15 +115,55 @@ func (q *Queries) GetMostRecentStreamStatus(ctx context.Context) (StreamStatus,
 
 // UpdateStreamInfo updates the stream information in the database
 func (q *Queries) UpdateStreamInfo(ctx context.Context, stream StreamStatus) error {
-	stmt, err := q.db.Prepare("UPDATE stream_status SET game_id = ?, game_name = ?, title = ? WHERE stream_id = ?")
+	stmt, err := q.db.Prepare("UPDATE stream_status SET game_id = ?, game_name = ?, title = ? WHERE id = ?")
 	if err != nil {
 		return err
 	}
 
-	_, err = stmt.Exec(stream.GameID, stream.GameName, stream.Title, stream.StreamID)
+	_, err = stmt.Exec(stream.GameID, stream.GameName, stream.Title, stream.ID)
 	if err != nil {
 		return err
 	}
 
 	return nil
 }
+
+// EventSubRevocation is an EventSub subscription Twitch revoked and whether it was subscribed to again
+type EventSubRevocation struct {
+	ID             int
+	SubscriptionID string
+	Type           string
+	ChannelID      string
+	// Reason is the status of the subscription, e.g. authorization_revoked
+	Reason       string
+	RevokedAt    string
+	Resubscribed int
+	Error        sql.NullString
+}
+
+// InsertEventSubRevocation records a revoked EventSub subscription
+func (q *Queries) InsertEventSubRevocation(ctx context.Context, revocation EventSubRevocation) error {
+	stmt, err := q.db.Prepare(`INSERT INTO eventsub_revocations (subscription_id, type, channel_id, reason, revoked_at, resubscribed, error)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer func(stmt *sql.Stmt) {
+		err := stmt.Close()
+		if err != nil {
+			slog.Error("Failed to close statement", "error", err)
+		}
+	}(stmt)
+
+	_, err = stmt.ExecContext(
+		ctx,
+		revocation.SubscriptionID,
+		revocation.Type,
+		revocation.ChannelID,
+		revocation.Reason,
+		revocation.RevokedAt,
+		revocation.Resubscribed,
+		revocation.Error,
+	)
+	return err
+}
@@ -0,0 +1,434 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// MigrateOptions holds the values the migrations need to backfill existing rows
+type MigrateOptions struct {
+	// DefaultChannelID is the Twitch ID of the channel existing single-channel rows belong to
+	DefaultChannelID string
+}
+
+type migration struct {
+	version int
+	name    string
+	up      func(ctx context.Context, tx *sql.Tx, opts MigrateOptions) error
+}
+
+// statements returns a migration step that executes the given statements in order
+func statements(stmts ...string) func(ctx context.Context, tx *sql.Tx, opts MigrateOptions) error {
+	return func(ctx context.Context, tx *sql.Tx, _ MigrateOptions) error {
+		for _, stmt := range stmts {
+			if _, err := tx.ExecContext(ctx, stmt); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// migrations is the ordered list of schema changes, new migrations must be appended to the end
+var migrations = []migration{
+	{
+		version: 1,
+		name:    "initial schema",
+		up: statements(
+			`CREATE TABLE IF NOT EXISTS "version_info" (
+				"version" VARCHAR(10)
+			)`,
+			`CREATE TABLE IF NOT EXISTS "chatters" (
+				"tid" INTEGER PRIMARY KEY,
+				"username" TEXT UNIQUE,
+				"display_name" TEXT
+			)`,
+			`CREATE TABLE IF NOT EXISTS "commands" (
+				"name" TEXT PRIMARY KEY,
+				"aliases" TEXT,
+				"permissions" TEXT,
+				"description" TEXT,
+				"dynamic_description" TEXT,
+				"global_cooldown" INTEGER,
+				"user_cooldown" INTEGER,
+				"enabled_offline" INTEGER,
+				"enabled_online" INTEGER,
+				"usage_count" INTEGER
+			)`,
+			`CREATE TABLE IF NOT EXISTS "custom_commands" (
+				"name" TEXT PRIMARY KEY,
+				"response" TEXT,
+				"usage_count" INTEGER
+			)`,
+			`CREATE TABLE IF NOT EXISTS "stream_status" (
+				"id" INTEGER PRIMARY KEY AUTOINCREMENT,
+				"stream_id" TEXT NOT NULL,
+				"game_id" TEXT,
+				"game_name" TEXT,
+				"live" INTEGER NOT NULL,
+				"title" TEXT,
+				"started_at" TEXT NOT NULL,
+				"ended_at" TEXT
+			)`,
+		),
+	},
+	{
+		version: 2,
+		name:    "multi channel support",
+		up: func(ctx context.Context, tx *sql.Tx, opts MigrateOptions) error {
+			err := statements(
+				`CREATE TABLE IF NOT EXISTS "channels" (
+					"id" TEXT PRIMARY KEY,
+					"name" TEXT NOT NULL UNIQUE,
+					"prefix" TEXT NOT NULL,
+					"enabled" INTEGER NOT NULL DEFAULT 1
+				)`,
+				`CREATE TABLE IF NOT EXISTS "channel_commands" (
+					"channel_id" TEXT NOT NULL,
+					"command_name" TEXT NOT NULL,
+					"enabled" INTEGER NOT NULL DEFAULT 1,
+					PRIMARY KEY ("channel_id", "command_name")
+				)`,
+				// Custom command names are now unique per channel instead of globally
+				`CREATE TABLE "custom_commands_new" (
+					"channel_id" TEXT NOT NULL,
+					"name" TEXT NOT NULL,
+					"response" TEXT,
+					"usage_count" INTEGER,
+					PRIMARY KEY ("channel_id", "name")
+				)`,
+			)(ctx, tx, opts)
+			if err != nil {
+				return err
+			}
+
+			_, err = tx.ExecContext(ctx, `INSERT INTO custom_commands_new (channel_id, name, response, usage_count) SELECT ?, name, response, usage_count FROM custom_commands`, opts.DefaultChannelID)
+			if err != nil {
+				return err
+			}
+
+			err = statements(
+				`DROP TABLE custom_commands`,
+				`ALTER TABLE custom_commands_new RENAME TO custom_commands`,
+				`ALTER TABLE stream_status ADD COLUMN channel_id TEXT NOT NULL DEFAULT ''`,
+			)(ctx, tx, opts)
+			if err != nil {
+				return err
+			}
+
+			_, err = tx.ExecContext(ctx, `UPDATE stream_status SET channel_id = ? WHERE channel_id = ''`, opts.DefaultChannelID)
+			return err
+		},
+	},
+	{
+		version: 3,
+		name:    "threaded replies",
+		up: statements(
+			`ALTER TABLE channels ADD COLUMN threaded_replies INTEGER NOT NULL DEFAULT 1`,
+		),
+	},
+	{
+		version: 4,
+		name:    "default command overrides",
+		up: statements(
+			`ALTER TABLE channel_commands ADD COLUMN global_cooldown INTEGER`,
+			`ALTER TABLE channel_commands ADD COLUMN user_cooldown INTEGER`,
+			`ALTER TABLE channel_commands ADD COLUMN permissions TEXT`,
+			`ALTER TABLE channel_commands ADD COLUMN enabled_online INTEGER`,
+			`ALTER TABLE channel_commands ADD COLUMN enabled_offline INTEGER`,
+			`ALTER TABLE channel_commands ADD COLUMN aliases TEXT`,
+		),
+	},
+	{
+		version: 5,
+		name:    "cooldowns",
+		up: statements(
+			`CREATE TABLE IF NOT EXISTS "cooldowns" (
+				"channel_id" TEXT NOT NULL,
+				"command" TEXT NOT NULL,
+				"user" TEXT NOT NULL DEFAULT '',
+				"bucket" TEXT NOT NULL DEFAULT '',
+				"expires_at" INTEGER NOT NULL,
+				PRIMARY KEY ("channel_id", "command", "user", "bucket")
+			)`,
+			`ALTER TABLE channels ADD COLUMN cooldown_notice INTEGER NOT NULL DEFAULT 0`,
+			`ALTER TABLE channels ADD COLUMN cooldown_buckets INTEGER NOT NULL DEFAULT 0`,
+		),
+	},
+	{
+		version: 6,
+		name:    "custom command settings",
+		up: statements(
+			`ALTER TABLE custom_commands ADD COLUMN aliases TEXT NOT NULL DEFAULT '[]'`,
+			`ALTER TABLE custom_commands ADD COLUMN permissions TEXT NOT NULL DEFAULT '[]'`,
+			`ALTER TABLE custom_commands ADD COLUMN global_cooldown INTEGER NOT NULL DEFAULT 10`,
+			`ALTER TABLE custom_commands ADD COLUMN user_cooldown INTEGER NOT NULL DEFAULT 30`,
+			`ALTER TABLE custom_commands ADD COLUMN enabled_offline INTEGER NOT NULL DEFAULT 1`,
+			`ALTER TABLE custom_commands ADD COLUMN enabled_online INTEGER NOT NULL DEFAULT 1`,
+			`ALTER TABLE custom_commands ADD COLUMN enabled INTEGER NOT NULL DEFAULT 1`,
+		),
+	},
+	{
+		version: 7,
+		name:    "counters",
+		up: statements(
+			`CREATE TABLE IF NOT EXISTS "counters" (
+				"channel_id" TEXT NOT NULL,
+				"name" TEXT NOT NULL,
+				"value" INTEGER NOT NULL DEFAULT 0,
+				"reset_on_stream" INTEGER NOT NULL DEFAULT 0,
+				PRIMARY KEY ("channel_id", "name")
+			)`,
+		),
+	},
+	{
+		version: 8,
+		name:    "custom command responses",
+		up: statements(
+			`ALTER TABLE custom_commands ADD COLUMN responses TEXT NOT NULL DEFAULT '[]'`,
+			`ALTER TABLE custom_commands ADD COLUMN response_mode TEXT NOT NULL DEFAULT 'uniform'`,
+			`ALTER TABLE custom_commands ADD COLUMN avoid_repeats INTEGER NOT NULL DEFAULT 0`,
+			`UPDATE custom_commands SET responses = json_array(json_object('text', COALESCE(response, ''), 'weight', 1))`,
+		),
+	},
+	{
+		version: 9,
+		name:    "channel modules",
+		up: statements(
+			`CREATE TABLE IF NOT EXISTS "channel_modules" (
+				"channel_id" TEXT NOT NULL,
+				"module" TEXT NOT NULL,
+				"enabled" INTEGER NOT NULL DEFAULT 1,
+				"settings" TEXT NOT NULL DEFAULT '{}',
+				PRIMARY KEY ("channel_id", "module")
+			)`,
+		),
+	},
+	{
+		version: 10,
+		name:    "announcements",
+		up: func(ctx context.Context, tx *sql.Tx, opts MigrateOptions) error {
+			_, err := tx.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS "announcements" (
+				"id" INTEGER PRIMARY KEY AUTOINCREMENT,
+				"channel_id" TEXT NOT NULL,
+				"cron" TEXT NOT NULL,
+				"timezone" TEXT NOT NULL DEFAULT 'UTC',
+				"message" TEXT NOT NULL,
+				"condition" TEXT NOT NULL DEFAULT 'always',
+				"enabled" INTEGER NOT NULL DEFAULT 1,
+				"created_at" TEXT NOT NULL
+			)`)
+			if err != nil {
+				return err
+			}
+
+			// The go live reminder used to be hardcoded, it's kept as an announcement of the default channel
+			if opts.DefaultChannelID == "" {
+				return nil
+			}
+			_, err = tx.ExecContext(
+				ctx,
+				`INSERT INTO announcements (channel_id, cron, timezone, message, condition, created_at) VALUES (?, ?, ?, ?, ?, ?)`,
+				opts.DefaultChannelID, "0 12 * * *", "America/Chicago", "GOLIVERIGHTNOWMADGE", "offline", time.Now().Format(time.RFC3339),
+			)
+			return err
+		},
+	},
+	{
+		version: 11,
+		name:    "timers",
+		up: statements(
+			`CREATE TABLE IF NOT EXISTS "timers" (
+				"id" INTEGER PRIMARY KEY AUTOINCREMENT,
+				"channel_id" TEXT NOT NULL,
+				"name" TEXT NOT NULL,
+				"interval" INTEGER NOT NULL,
+				"min_lines" INTEGER NOT NULL DEFAULT 0,
+				"messages" TEXT NOT NULL DEFAULT '[]',
+				"condition" TEXT NOT NULL DEFAULT 'always',
+				"enabled" INTEGER NOT NULL DEFAULT 1,
+				UNIQUE ("channel_id", "name")
+			)`,
+		),
+	},
+	{
+		version: 12,
+		name:    "usernotice responses",
+		up: func(ctx context.Context, tx *sql.Tx, opts MigrateOptions) error {
+			_, err := tx.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS "usernotice_responses" (
+				"channel_id" TEXT NOT NULL,
+				"type" TEXT NOT NULL,
+				"enabled" INTEGER NOT NULL DEFAULT 1,
+				"template" TEXT NOT NULL,
+				"threshold" INTEGER NOT NULL DEFAULT 0,
+				PRIMARY KEY ("channel_id", "type")
+			)`)
+			if err != nil {
+				return err
+			}
+
+			// The sub and resub responses used to be hardcoded, the default channel keeps them as they were
+			if opts.DefaultChannelID == "" {
+				return nil
+			}
+			for _, noticeType := range []string{"sub", "resub"} {
+				_, err = tx.ExecContext(
+					ctx,
+					`INSERT INTO usernotice_responses (channel_id, type, template) VALUES (?, ?, ?)`,
+					opts.DefaultChannelID, noticeType, "@EsfandTV, ${notice} ${random.pick Pog|PogU|POGGERS|PagChomp|PagMan|PagBounce}",
+				)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		version: 13,
+		name:    "eventsub revocations",
+		up: statements(
+			`CREATE TABLE IF NOT EXISTS "eventsub_revocations" (
+				"id" INTEGER PRIMARY KEY AUTOINCREMENT,
+				"subscription_id" TEXT NOT NULL,
+				"type" TEXT NOT NULL,
+				"channel_id" TEXT NOT NULL,
+				"reason" TEXT NOT NULL,
+				"revoked_at" TEXT NOT NULL,
+				"resubscribed" INTEGER NOT NULL DEFAULT 0,
+				"error" TEXT
+			)`,
+		),
+	},
+	{
+		version: 14,
+		name:    "channel events",
+		up: statements(
+			`CREATE TABLE IF NOT EXISTS "events" (
+				"id" INTEGER PRIMARY KEY AUTOINCREMENT,
+				"message_id" TEXT NOT NULL,
+				"channel_id" TEXT NOT NULL,
+				"type" TEXT NOT NULL,
+				"user_id" TEXT,
+				"user_name" TEXT,
+				"payload" TEXT NOT NULL,
+				"received_at" TEXT NOT NULL
+			)`,
+			`CREATE INDEX IF NOT EXISTS "events_channel_type" ON "events" ("channel_id", "type")`,
+		),
+	},
+	{
+		version: 15,
+		name:    "reward actions",
+		up: statements(
+			`CREATE TABLE IF NOT EXISTS "reward_actions" (
+				"channel_id" TEXT NOT NULL,
+				"reward_id" TEXT NOT NULL,
+				"action" TEXT NOT NULL,
+				"target" TEXT NOT NULL DEFAULT '',
+				"amount" INTEGER NOT NULL DEFAULT 0,
+				"template" TEXT NOT NULL DEFAULT '',
+				"complete" INTEGER NOT NULL DEFAULT 0,
+				"enabled" INTEGER NOT NULL DEFAULT 1,
+				PRIMARY KEY ("channel_id", "reward_id")
+			)`,
+			`CREATE TABLE IF NOT EXISTS "queue_entries" (
+				"id" INTEGER PRIMARY KEY AUTOINCREMENT,
+				"channel_id" TEXT NOT NULL,
+				"queue" TEXT NOT NULL,
+				"user_id" TEXT NOT NULL,
+				"user_login" TEXT NOT NULL,
+				"user_name" TEXT NOT NULL,
+				"input" TEXT NOT NULL DEFAULT '',
+				"added_at" TEXT NOT NULL,
+				UNIQUE ("channel_id", "queue", "user_id")
+			)`,
+		),
+	},
+	{
+		version: 16,
+		name:    "broadcaster tokens",
+		up: statements(
+			`CREATE TABLE IF NOT EXISTS "broadcaster_tokens" (
+				"channel_id" TEXT PRIMARY KEY,
+				"access_token" TEXT NOT NULL,
+				"refresh_token" TEXT NOT NULL,
+				"scopes" TEXT NOT NULL DEFAULT '',
+				"updated_at" TEXT NOT NULL
+			)`,
+		),
+	},
+	{
+		version: 17,
+		name:    "threaded reply per command",
+		up: statements(
+			`ALTER TABLE "custom_commands" ADD COLUMN "threaded_reply" INTEGER NOT NULL DEFAULT 0`,
+			`ALTER TABLE "channel_commands" ADD COLUMN "threaded_reply" INTEGER`,
+		),
+	},
+}
+
+// Migrate applies every migration that hasn't been applied to the database yet
+func (q *Queries) Migrate(ctx context.Context, opts MigrateOptions) error {
+	_, err := q.db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS "schema_migrations" (
+		"version" INTEGER PRIMARY KEY,
+		"name" TEXT NOT NULL,
+		"applied_at" TEXT NOT NULL
+	)`)
+	if err != nil {
+		return err
+	}
+
+	var current int
+	err = q.db.QueryRowContext(ctx, "SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&current)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+
+		slog.Info("Applying database migration", "version", m.version, "name", m.name)
+
+		if err := q.applyMigration(ctx, m, opts); err != nil {
+			return fmt.Errorf("migration %d (%s): %w", m.version, m.name, err)
+		}
+	}
+
+	return nil
+}
+
+func (q *Queries) applyMigration(ctx context.Context, m migration, opts MigrateOptions) error {
+	tx, err := q.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func(tx *sql.Tx) {
+		err := tx.Rollback()
+		if err != nil && err != sql.ErrTxDone {
+			slog.Error("Failed to rollback migration", "error", err)
+		}
+	}(tx)
+
+	if err := m.up(ctx, tx, opts); err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(
+		ctx,
+		"INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)",
+		m.version,
+		m.name,
+		time.Now().Format(time.RFC3339),
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
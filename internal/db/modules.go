@@ -0,0 +1,58 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+)
+
+// ChannelModule is the state of a bot module in a channel, modules without a row use their defaults
+type ChannelModule struct {
+	ChannelID string
+	Module    string
+	Enabled   int
+	// Settings is a JSON object of the settings which were changed from their default
+	Settings string
+}
+
+// GetAllChannelModules retrieves the module states of every channel
+func (q *Queries) GetAllChannelModules(ctx context.Context) ([]ChannelModule, error) {
+	rows, err := q.db.QueryContext(ctx, "SELECT channel_id, module, enabled, settings FROM channel_modules")
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			slog.Error("Failed to close rows", "error", err)
+		}
+	}(rows)
+
+	var modules []ChannelModule
+	for rows.Next() {
+		var module ChannelModule
+		if err := rows.Scan(&module.ChannelID, &module.Module, &module.Enabled, &module.Settings); err != nil {
+			return nil, err
+		}
+		modules = append(modules, module)
+	}
+
+	return modules, rows.Err()
+}
+
+// UpsertChannelModule stores the state of a module in a channel, replacing the one stored before
+func (q *Queries) UpsertChannelModule(ctx context.Context, module ChannelModule) error {
+	stmt, err := q.db.Prepare("INSERT OR REPLACE INTO channel_modules (channel_id, module, enabled, settings) VALUES (?, ?, ?, ?)")
+	if err != nil {
+		return err
+	}
+	defer func(stmt *sql.Stmt) {
+		err := stmt.Close()
+		if err != nil {
+			slog.Error("Failed to close statement", "error", err)
+		}
+	}(stmt)
+
+	_, err = stmt.ExecContext(ctx, module.ChannelID, module.Module, module.Enabled, module.Settings)
+	return err
+}
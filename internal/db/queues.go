@@ -0,0 +1,112 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+)
+
+// QueueEntry is a user waiting in a queue of a channel, e.g. to play with the broadcaster
+type QueueEntry struct {
+	ID        int
+	ChannelID string
+	Queue     string
+	UserID    string
+	UserLogin string
+	UserName  string
+	// Input is what the user wrote when they joined the queue
+	Input   string
+	AddedAt string
+}
+
+// InsertQueueEntry adds a user to a queue and reports whether they were added, a user is only in a queue once
+func (q *Queries) InsertQueueEntry(ctx context.Context, e QueueEntry) (bool, error) {
+	stmt, err := q.db.Prepare(`INSERT INTO queue_entries (channel_id, queue, user_id, user_login, user_name, input, added_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?) ON CONFLICT (channel_id, queue, user_id) DO NOTHING`)
+	if err != nil {
+		return false, err
+	}
+	defer func(stmt *sql.Stmt) {
+		err := stmt.Close()
+		if err != nil {
+			slog.Error("Failed to close statement", "error", err)
+		}
+	}(stmt)
+
+	res, err := stmt.ExecContext(ctx, e.ChannelID, e.Queue, e.UserID, e.UserLogin, e.UserName, e.Input, e.AddedAt)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	return affected > 0, err
+}
+
+// GetQueueEntries retrieves the users in a queue of a channel, the one who joined first is first
+func (q *Queries) GetQueueEntries(ctx context.Context, channelID, queue string) ([]QueueEntry, error) {
+	rows, err := q.db.QueryContext(
+		ctx,
+		`SELECT id, channel_id, queue, user_id, user_login, user_name, input, added_at FROM queue_entries
+			WHERE channel_id = ? AND queue = ? ORDER BY id`,
+		channelID, queue,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			slog.Error("Failed to close rows", "error", err)
+		}
+	}(rows)
+
+	var entries []QueueEntry
+	for rows.Next() {
+		var e QueueEntry
+		if err := rows.Scan(&e.ID, &e.ChannelID, &e.Queue, &e.UserID, &e.UserLogin, &e.UserName, &e.Input, &e.AddedAt); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+
+	return entries, rows.Err()
+}
+
+// DeleteQueueEntry removes a user from a queue of a channel and reports whether they were in it
+func (q *Queries) DeleteQueueEntry(ctx context.Context, channelID, queue, userID string) (bool, error) {
+	stmt, err := q.db.Prepare("DELETE FROM queue_entries WHERE channel_id = ? AND queue = ? AND user_id = ?")
+	if err != nil {
+		return false, err
+	}
+	defer func(stmt *sql.Stmt) {
+		err := stmt.Close()
+		if err != nil {
+			slog.Error("Failed to close statement", "error", err)
+		}
+	}(stmt)
+
+	res, err := stmt.ExecContext(ctx, channelID, queue, userID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	return affected > 0, err
+}
+
+// ClearQueue removes every user from a queue of a channel
+func (q *Queries) ClearQueue(ctx context.Context, channelID, queue string) error {
+	stmt, err := q.db.Prepare("DELETE FROM queue_entries WHERE channel_id = ? AND queue = ?")
+	if err != nil {
+		return err
+	}
+	defer func(stmt *sql.Stmt) {
+		err := stmt.Close()
+		if err != nil {
+			slog.Error("Failed to close statement", "error", err)
+		}
+	}(stmt)
+
+	_, err = stmt.ExecContext(ctx, channelID, queue)
+	return err
+}
@@ -0,0 +1,101 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+)
+
+// RewardAction is what the bot does when a channel point reward of a channel is redeemed
+type RewardAction struct {
+	ChannelID string
+	RewardID  string
+	// Action is message, counter, command, queue or timeout
+	Action string
+	// Target is the counter, custom command or queue the action works on
+	Target string
+	// Amount is what a counter is incremented by or how many seconds a timeout lasts
+	Amount   int
+	Template string
+	// Complete is whether the redemption is fulfilled when the action succeeds and canceled when it fails
+	Complete int
+	Enabled  int
+}
+
+const rewardActionColumns = "channel_id, reward_id, action, target, amount, template, complete, enabled"
+
+func scanRewardAction(scanner interface{ Scan(dest ...any) error }) (RewardAction, error) {
+	var a RewardAction
+	err := scanner.Scan(&a.ChannelID, &a.RewardID, &a.Action, &a.Target, &a.Amount, &a.Template, &a.Complete, &a.Enabled)
+	return a, err
+}
+
+// GetRewardAction retrieves the action of a channel point reward
+func (q *Queries) GetRewardAction(ctx context.Context, channelID, rewardID string) (RewardAction, error) {
+	row := q.db.QueryRowContext(ctx, "SELECT "+rewardActionColumns+" FROM reward_actions WHERE channel_id = ? AND reward_id = ?", channelID, rewardID)
+	return scanRewardAction(row)
+}
+
+// GetChannelRewardActions retrieves the actions of the channel point rewards of a channel
+func (q *Queries) GetChannelRewardActions(ctx context.Context, channelID string) ([]RewardAction, error) {
+	rows, err := q.db.QueryContext(ctx, "SELECT "+rewardActionColumns+" FROM reward_actions WHERE channel_id = ? ORDER BY reward_id", channelID)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			slog.Error("Failed to close rows", "error", err)
+		}
+	}(rows)
+
+	var actions []RewardAction
+	for rows.Next() {
+		a, err := scanRewardAction(rows)
+		if err != nil {
+			return nil, err
+		}
+		actions = append(actions, a)
+	}
+
+	return actions, rows.Err()
+}
+
+// UpsertRewardAction stores the action of a channel point reward, replacing the one stored before
+func (q *Queries) UpsertRewardAction(ctx context.Context, a RewardAction) error {
+	stmt, err := q.db.Prepare("INSERT OR REPLACE INTO reward_actions (" + rewardActionColumns + ") VALUES (?, ?, ?, ?, ?, ?, ?, ?)")
+	if err != nil {
+		return err
+	}
+	defer func(stmt *sql.Stmt) {
+		err := stmt.Close()
+		if err != nil {
+			slog.Error("Failed to close statement", "error", err)
+		}
+	}(stmt)
+
+	_, err = stmt.ExecContext(ctx, a.ChannelID, a.RewardID, a.Action, a.Target, a.Amount, a.Template, a.Complete, a.Enabled)
+	return err
+}
+
+// DeleteRewardAction deletes the action of a channel point reward and reports whether it existed
+func (q *Queries) DeleteRewardAction(ctx context.Context, channelID, rewardID string) (bool, error) {
+	stmt, err := q.db.Prepare("DELETE FROM reward_actions WHERE channel_id = ? AND reward_id = ?")
+	if err != nil {
+		return false, err
+	}
+	defer func(stmt *sql.Stmt) {
+		err := stmt.Close()
+		if err != nil {
+			slog.Error("Failed to close statement", "error", err)
+		}
+	}(stmt)
+
+	res, err := stmt.ExecContext(ctx, channelID, rewardID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	return affected > 0, err
+}
@@ -0,0 +1,129 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+)
+
+// Timer is a group of messages which are posted in rotation when the chat is active
+type Timer struct {
+	ID        int
+	ChannelID string
+	Name      string
+	// Interval is the minimum number of minutes between two messages
+	Interval int
+	// MinLines is the minimum number of chat lines since the last message
+	MinLines int
+	// Messages is a JSON list of the messages
+	Messages  string
+	Condition string
+	Enabled   int
+}
+
+const timerColumns = "id, channel_id, name, interval, min_lines, messages, condition, enabled"
+
+func scanTimer(scanner interface{ Scan(dest ...any) error }) (Timer, error) {
+	var t Timer
+	err := scanner.Scan(&t.ID, &t.ChannelID, &t.Name, &t.Interval, &t.MinLines, &t.Messages, &t.Condition, &t.Enabled)
+	return t, err
+}
+
+// InsertTimer inserts a new timer and returns its ID
+func (q *Queries) InsertTimer(ctx context.Context, t Timer) (int, error) {
+	stmt, err := q.db.Prepare(`INSERT INTO timers (channel_id, name, interval, min_lines, messages, condition, enabled)
+		VALUES (?, ?, ?, ?, ?, ?, ?) RETURNING id`)
+	if err != nil {
+		return 0, err
+	}
+	defer func(stmt *sql.Stmt) {
+		err := stmt.Close()
+		if err != nil {
+			slog.Error("Failed to close statement", "error", err)
+		}
+	}(stmt)
+
+	var id int
+	err = stmt.QueryRowContext(ctx, t.ChannelID, t.Name, t.Interval, t.MinLines, t.Messages, t.Condition, t.Enabled).Scan(&id)
+	return id, err
+}
+
+// UpdateTimer updates the settings and messages of a timer
+func (q *Queries) UpdateTimer(ctx context.Context, t Timer) error {
+	stmt, err := q.db.Prepare(`UPDATE timers SET interval = ?, min_lines = ?, messages = ?, condition = ?, enabled = ?
+		WHERE channel_id = ? AND name = ?`)
+	if err != nil {
+		return err
+	}
+	defer func(stmt *sql.Stmt) {
+		err := stmt.Close()
+		if err != nil {
+			slog.Error("Failed to close statement", "error", err)
+		}
+	}(stmt)
+
+	_, err = stmt.ExecContext(ctx, t.Interval, t.MinLines, t.Messages, t.Condition, t.Enabled, t.ChannelID, t.Name)
+	return err
+}
+
+// GetTimer retrieves a timer of a channel by name
+func (q *Queries) GetTimer(ctx context.Context, channelID, name string) (Timer, error) {
+	row := q.db.QueryRowContext(ctx, "SELECT "+timerColumns+" FROM timers WHERE channel_id = ? AND name = ?", channelID, name)
+	return scanTimer(row)
+}
+
+// GetAllTimers retrieves the timers of every channel
+func (q *Queries) GetAllTimers(ctx context.Context) ([]Timer, error) {
+	return q.queryTimers(ctx, "SELECT "+timerColumns+" FROM timers ORDER BY id")
+}
+
+// GetChannelTimers retrieves the timers of a channel
+func (q *Queries) GetChannelTimers(ctx context.Context, channelID string) ([]Timer, error) {
+	return q.queryTimers(ctx, "SELECT "+timerColumns+" FROM timers WHERE channel_id = ? ORDER BY id", channelID)
+}
+
+func (q *Queries) queryTimers(ctx context.Context, query string, args ...any) ([]Timer, error) {
+	rows, err := q.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			slog.Error("Failed to close rows", "error", err)
+		}
+	}(rows)
+
+	var timers []Timer
+	for rows.Next() {
+		t, err := scanTimer(rows)
+		if err != nil {
+			return nil, err
+		}
+		timers = append(timers, t)
+	}
+
+	return timers, rows.Err()
+}
+
+// DeleteTimer deletes a timer of a channel and reports whether it existed
+func (q *Queries) DeleteTimer(ctx context.Context, channelID, name string) (bool, error) {
+	stmt, err := q.db.Prepare("DELETE FROM timers WHERE channel_id = ? AND name = ?")
+	if err != nil {
+		return false, err
+	}
+	defer func(stmt *sql.Stmt) {
+		err := stmt.Close()
+		if err != nil {
+			slog.Error("Failed to close statement", "error", err)
+		}
+	}(stmt)
+
+	res, err := stmt.ExecContext(ctx, channelID, name)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	return affected > 0, err
+}
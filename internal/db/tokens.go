@@ -0,0 +1,60 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+)
+
+// BroadcasterToken is the user access token a broadcaster connected to the bot, it's what the bot acts on their
+// channel with
+type BroadcasterToken struct {
+	ChannelID    string
+	AccessToken  string
+	RefreshToken string
+	// Scopes are the scopes the broadcaster granted, separated by spaces
+	Scopes    string
+	UpdatedAt string
+}
+
+// GetBroadcasterToken retrieves the token the broadcaster of a channel connected
+func (q *Queries) GetBroadcasterToken(ctx context.Context, channelID string) (BroadcasterToken, error) {
+	var t BroadcasterToken
+	err := q.db.QueryRowContext(ctx, "SELECT channel_id, access_token, refresh_token, scopes, updated_at FROM broadcaster_tokens WHERE channel_id = ?", channelID).Scan(
+		&t.ChannelID, &t.AccessToken, &t.RefreshToken, &t.Scopes, &t.UpdatedAt)
+	return t, err
+}
+
+// UpsertBroadcasterToken stores the token the broadcaster of a channel connected, replacing the one stored before
+func (q *Queries) UpsertBroadcasterToken(ctx context.Context, t BroadcasterToken) error {
+	stmt, err := q.db.Prepare("INSERT OR REPLACE INTO broadcaster_tokens (channel_id, access_token, refresh_token, scopes, updated_at) VALUES (?, ?, ?, ?, ?)")
+	if err != nil {
+		return err
+	}
+	defer func(stmt *sql.Stmt) {
+		err := stmt.Close()
+		if err != nil {
+			slog.Error("Failed to close statement", "error", err)
+		}
+	}(stmt)
+
+	_, err = stmt.ExecContext(ctx, t.ChannelID, t.AccessToken, t.RefreshToken, t.Scopes, t.UpdatedAt)
+	return err
+}
+
+// UpdateBroadcasterAccessToken stores a refreshed token of a channel, the scopes don't change when it's refreshed
+func (q *Queries) UpdateBroadcasterAccessToken(ctx context.Context, channelID, accessToken, refreshToken, updatedAt string) error {
+	stmt, err := q.db.Prepare("UPDATE broadcaster_tokens SET access_token = ?, refresh_token = ?, updated_at = ? WHERE channel_id = ?")
+	if err != nil {
+		return err
+	}
+	defer func(stmt *sql.Stmt) {
+		err := stmt.Close()
+		if err != nil {
+			slog.Error("Failed to close statement", "error", err)
+		}
+	}(stmt)
+
+	_, err = stmt.ExecContext(ctx, accessToken, refreshToken, updatedAt, channelID)
+	return err
+}
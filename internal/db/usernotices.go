@@ -0,0 +1,62 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+)
+
+// UserNoticeResponse is how the bot responds to a kind of chat event in a channel, events without a row use the
+// default response
+type UserNoticeResponse struct {
+	ChannelID string
+	Type      string
+	Enabled   int
+	Template  string
+	Threshold int
+}
+
+// GetUserNoticeResponse retrieves the response of a channel to a kind of chat event
+func (q *Queries) GetUserNoticeResponse(ctx context.Context, channelID, noticeType string) (UserNoticeResponse, error) {
+	var response UserNoticeResponse
+	err := q.db.QueryRowContext(
+		ctx,
+		"SELECT channel_id, type, enabled, template, threshold FROM usernotice_responses WHERE channel_id = ? AND type = ?",
+		channelID, noticeType,
+	).Scan(&response.ChannelID, &response.Type, &response.Enabled, &response.Template, &response.Threshold)
+	return response, err
+}
+
+// UpsertUserNoticeResponse stores the response of a channel to a kind of chat event, replacing the one stored before
+func (q *Queries) UpsertUserNoticeResponse(ctx context.Context, response UserNoticeResponse) error {
+	stmt, err := q.db.Prepare("INSERT OR REPLACE INTO usernotice_responses (channel_id, type, enabled, template, threshold) VALUES (?, ?, ?, ?, ?)")
+	if err != nil {
+		return err
+	}
+	defer func(stmt *sql.Stmt) {
+		err := stmt.Close()
+		if err != nil {
+			slog.Error("Failed to close statement", "error", err)
+		}
+	}(stmt)
+
+	_, err = stmt.ExecContext(ctx, response.ChannelID, response.Type, response.Enabled, response.Template, response.Threshold)
+	return err
+}
+
+// DeleteUserNoticeResponse deletes the response of a channel to a kind of chat event so the default is used again
+func (q *Queries) DeleteUserNoticeResponse(ctx context.Context, channelID, noticeType string) error {
+	stmt, err := q.db.Prepare("DELETE FROM usernotice_responses WHERE channel_id = ? AND type = ?")
+	if err != nil {
+		return err
+	}
+	defer func(stmt *sql.Stmt) {
+		err := stmt.Close()
+		if err != nil {
+			slog.Error("Failed to close statement", "error", err)
+		}
+	}(stmt)
+
+	_, err = stmt.ExecContext(ctx, channelID, noticeType)
+	return err
+}
@@ -0,0 +1,62 @@
+package v1
+
+import (
+	"log/slog"
+	"strings"
+
+	"github.com/esfands/retpaladinbot/internal/global"
+	"github.com/esfands/retpaladinbot/internal/rest/v1/respond"
+	"github.com/esfands/retpaladinbot/internal/rest/v1/routes"
+	"github.com/esfands/retpaladinbot/internal/services/auth"
+	"github.com/esfands/retpaladinbot/pkg/errors"
+)
+
+// authenticated only runs the handler for requests with a valid access token cookie from the Twitch login
+func authenticated(gctx global.Context, fn func(*respond.Ctx) error) func(*respond.Ctx) error {
+	return func(ctx *respond.Ctx) error {
+		token := ctx.Cookies(auth.CookieAuth)
+		if token == "" {
+			return errors.ErrUnauthorized()
+		}
+
+		claims := &auth.JWTClaimUser{}
+		if _, err := gctx.Crate().Auth.VerifyJWT(strings.Split(token, "."), claims); err != nil {
+			slog.Debug("[rest] invalid access token", "error", err.Error())
+			return errors.ErrUnauthorized()
+		}
+
+		ctx.Locals("twitch_id", claims.TwitchID)
+
+		return fn(ctx)
+	}
+}
+
+// authorized only runs the handler for logged in users who may manage the channel of the request, which are its
+// broadcaster and the admins of the bot
+func authorized(gctx global.Context, fn func(*respond.Ctx) error) func(*respond.Ctx) error {
+	return authenticated(gctx, func(ctx *respond.Ctx) error {
+		channelID, err := routes.ChannelID(gctx, ctx)
+		if err != nil {
+			return err
+		}
+
+		twitchID, _ := ctx.Locals("twitch_id").(string)
+		if twitchID != channelID && !routes.IsAdmin(gctx, twitchID) {
+			return errors.ErrInsufficientPermissions().SetDetail("Only the broadcaster may manage this channel")
+		}
+
+		return fn(ctx)
+	})
+}
+
+// adminOnly only runs the handler for logged in admins of the bot, e.g. for the routes which affect every channel
+func adminOnly(gctx global.Context, fn func(*respond.Ctx) error) func(*respond.Ctx) error {
+	return authenticated(gctx, func(ctx *respond.Ctx) error {
+		twitchID, _ := ctx.Locals("twitch_id").(string)
+		if !routes.IsAdmin(gctx, twitchID) {
+			return errors.ErrInsufficientPermissions().SetDetail("Only admins of the bot may do this")
+		}
+
+		return fn(ctx)
+	})
+}
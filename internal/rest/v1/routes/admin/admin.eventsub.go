@@ -0,0 +1,75 @@
+package admin
+
+import (
+	goerrors "errors"
+
+	"github.com/esfands/retpaladinbot/internal/rest/v1/respond"
+	"github.com/esfands/retpaladinbot/internal/services/eventsub"
+	"github.com/esfands/retpaladinbot/pkg/errors"
+	"github.com/gofiber/fiber/v2"
+	"github.com/nicklaw5/helix/v2"
+)
+
+// GetEventSubSubscriptions lists the EventSub subscriptions of the app
+func (rg *RouteGroup) GetEventSubSubscriptions(ctx *respond.Ctx) error {
+	subscriptions, err := rg.gctx.Crate().EventSub.List()
+	if err != nil {
+		return errors.ErrInternalServerError().SetDetail(err.Error())
+	}
+
+	if subscriptions == nil {
+		subscriptions = []helix.EventSubSubscription{}
+	}
+	return ctx.JSON(subscriptions)
+}
+
+type CreateEventSubSubscriptionRequest struct {
+	Type      string `json:"type"`
+	ChannelID string `json:"channel_id"`
+}
+
+// CreateEventSubSubscription subscribes to an event of a channel
+func (rg *RouteGroup) CreateEventSubSubscription(ctx *respond.Ctx) error {
+	var req CreateEventSubSubscriptionRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return errors.ErrBadRequest().SetDetail(err.Error())
+	}
+
+	if _, ok := eventsub.GetType(req.Type); !ok {
+		return errors.ErrValidationRejected().SetDetail("Unknown subscription type %v", req.Type)
+	}
+	if req.ChannelID == "" {
+		return errors.ErrValidationRejected().SetDetail("The channel_id is missing")
+	}
+
+	subscription, err := rg.gctx.Crate().EventSub.Subscribe(req.Type, req.ChannelID)
+	if goerrors.Is(err, eventsub.ErrNoCallback) {
+		return errors.ErrValidationRejected().SetDetail(err.Error())
+	} else if err != nil {
+		return errors.ErrInternalServerError().SetDetail(err.Error())
+	}
+
+	ctx.Status(fiber.StatusCreated)
+	return ctx.JSON(subscription)
+}
+
+// ReconcileEventSubSubscriptions creates the missing subscriptions of the channels and deletes the stale ones
+func (rg *RouteGroup) ReconcileEventSubSubscriptions(ctx *respond.Ctx) error {
+	result, err := rg.gctx.Crate().EventSub.Reconcile(ctx.Context())
+	if goerrors.Is(err, eventsub.ErrNoCallback) {
+		return errors.ErrValidationRejected().SetDetail(err.Error())
+	} else if err != nil {
+		return errors.ErrInternalServerError().SetDetail(err.Error())
+	}
+
+	return ctx.JSON(result)
+}
+
+// DeleteEventSubSubscription deletes a subscription by ID
+func (rg *RouteGroup) DeleteEventSubSubscription(ctx *respond.Ctx) error {
+	if err := rg.gctx.Crate().EventSub.Unsubscribe(ctx.Params("id")); err != nil {
+		return errors.ErrInternalServerError().SetDetail(err.Error())
+	}
+
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
@@ -0,0 +1,91 @@
+package announcements
+
+import (
+	"strconv"
+
+	"github.com/esfands/retpaladinbot/internal/rest/v1/respond"
+	"github.com/esfands/retpaladinbot/internal/rest/v1/routes"
+	"github.com/esfands/retpaladinbot/internal/services/announcements"
+	"github.com/esfands/retpaladinbot/pkg/domain"
+	"github.com/esfands/retpaladinbot/pkg/errors"
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetAnnouncements lists the scheduled announcements of the channel
+func (rg *RouteGroup) GetAnnouncements(ctx *respond.Ctx) error {
+	channelID, err := routes.ChannelID(rg.gctx, ctx)
+	if err != nil {
+		return err
+	}
+
+	list, err := rg.gctx.Crate().Announcements.List(ctx.Context(), channelID)
+	if err != nil {
+		return errors.ErrInternalServerError().SetDetail(err.Error())
+	}
+
+	return ctx.JSON(list)
+}
+
+type CreateAnnouncementRequest struct {
+	Cron      string                 `json:"cron"`
+	Timezone  string                 `json:"timezone"`
+	Message   string                 `json:"message"`
+	Condition domain.StreamCondition `json:"condition"`
+}
+
+// CreateAnnouncement schedules a new announcement in the channel
+func (rg *RouteGroup) CreateAnnouncement(ctx *respond.Ctx) error {
+	channelID, err := routes.ChannelID(rg.gctx, ctx)
+	if err != nil {
+		return err
+	}
+
+	var req CreateAnnouncementRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return errors.ErrBadRequest().SetDetail(err.Error())
+	}
+
+	announcement := domain.Announcement{
+		ChannelID: channelID,
+		Cron:      req.Cron,
+		Timezone:  req.Timezone,
+		Message:   req.Message,
+		Condition: req.Condition,
+	}
+	if err := announcements.Validate(&announcement); err != nil {
+		return errors.ErrValidationRejected().SetDetail(err.Error())
+	}
+	if _, err := rg.variables.Parse(announcement.Message); err != nil {
+		return errors.ErrValidationRejected().SetDetail("Invalid message: %v", err)
+	}
+
+	announcement, err = rg.gctx.Crate().Announcements.Add(ctx.Context(), announcement)
+	if err != nil {
+		return errors.ErrInternalServerError().SetDetail(err.Error())
+	}
+
+	return ctx.JSON(announcement)
+}
+
+// DeleteAnnouncement removes an announcement of the channel
+func (rg *RouteGroup) DeleteAnnouncement(ctx *respond.Ctx) error {
+	channelID, err := routes.ChannelID(rg.gctx, ctx)
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.Atoi(ctx.Params("id"))
+	if err != nil {
+		return errors.ErrBadRequest().SetDetail("Invalid announcement ID")
+	}
+
+	removed, err := rg.gctx.Crate().Announcements.Remove(ctx.Context(), channelID, id)
+	if err != nil {
+		return errors.ErrInternalServerError().SetDetail(err.Error())
+	}
+	if !removed {
+		return errors.ErrNotFound().SetDetail("Announcement not found")
+	}
+
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
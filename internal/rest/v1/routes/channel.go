@@ -0,0 +1,39 @@
+package routes
+
+import (
+	"database/sql"
+	goerrors "errors"
+	"slices"
+	"strings"
+
+	"github.com/esfands/retpaladinbot/internal/global"
+	"github.com/esfands/retpaladinbot/internal/rest/v1/respond"
+	"github.com/esfands/retpaladinbot/pkg/errors"
+)
+
+// ChannelID resolves the channel given in the `channel` query parameter, defaulting to the configured channel
+func ChannelID(gctx global.Context, ctx *respond.Ctx) (string, error) {
+	name := strings.ToLower(ctx.Query("channel"))
+	if name == "" {
+		return gctx.Config().Twitch.Bot.ChannelID, nil
+	}
+
+	channel, err := gctx.Crate().Turso.Queries().GetChannelByName(ctx.Context(), name)
+	if goerrors.Is(err, sql.ErrNoRows) {
+		return "", errors.ErrNotFound().SetDetail("Channel not found")
+	} else if err != nil {
+		return "", errors.ErrInternalServerError().SetDetail(err.Error())
+	}
+
+	return channel.ID, nil
+}
+
+// IsAdmin is whether the Twitch user may manage every channel of the bot, which are the configured admins and the
+// broadcaster of the channel the bot runs for
+func IsAdmin(gctx global.Context, twitchID string) bool {
+	if twitchID == "" {
+		return false
+	}
+
+	return twitchID == gctx.Config().Twitch.Bot.ChannelID || slices.Contains(gctx.Config().Twitch.Bot.Admins, twitchID)
+}
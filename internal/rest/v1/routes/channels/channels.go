@@ -0,0 +1,216 @@
+package channels
+
+import (
+	"context"
+	"database/sql"
+	goerrors "errors"
+	"log/slog"
+	"strings"
+	"unicode"
+
+	"github.com/esfands/retpaladinbot/internal/db"
+	"github.com/esfands/retpaladinbot/internal/rest/v1/respond"
+	"github.com/esfands/retpaladinbot/internal/rest/v1/routes"
+	"github.com/esfands/retpaladinbot/internal/services/events"
+	"github.com/esfands/retpaladinbot/internal/services/eventsub"
+	"github.com/esfands/retpaladinbot/pkg/domain"
+	"github.com/esfands/retpaladinbot/pkg/errors"
+	"github.com/esfands/retpaladinbot/pkg/utils"
+	"github.com/nicklaw5/helix/v2"
+)
+
+// GetChannel returns the settings of the channel
+func (rg *RouteGroup) GetChannel(ctx *respond.Ctx) error {
+	channelID, err := routes.ChannelID(rg.gctx, ctx)
+	if err != nil {
+		return err
+	}
+
+	channel, err := rg.getChannel(ctx, channelID)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(channel)
+}
+
+// GetChannels lists every channel of the bot
+func (rg *RouteGroup) GetChannels(ctx *respond.Ctx) error {
+	stored, err := rg.gctx.Crate().Turso.Queries().GetAllChannels(ctx.Context())
+	if err != nil {
+		return errors.ErrInternalServerError().SetDetail(err.Error())
+	}
+
+	channels := []domain.Channel{}
+	for _, channel := range stored {
+		channels = append(channels, toChannel(channel))
+	}
+
+	return ctx.JSON(channels)
+}
+
+type CreateChannelRequest struct {
+	// Name is the login of the channel
+	Name string `json:"name"`
+	// Prefix defaults to the configured prefix
+	Prefix string `json:"prefix"`
+}
+
+// CreateChannel adds a channel to the bot, which joins it right away
+func (rg *RouteGroup) CreateChannel(ctx *respond.Ctx) error {
+	var req CreateChannelRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return errors.ErrBadRequest().SetDetail(err.Error())
+	}
+
+	name := strings.ToLower(strings.TrimSpace(req.Name))
+	if name == "" {
+		return errors.ErrValidationRejected().SetDetail("The name of the channel is required")
+	}
+
+	prefix := req.Prefix
+	if prefix == "" {
+		prefix = rg.gctx.Config().Twitch.Bot.Prefix
+	}
+	if !validPrefix(prefix) {
+		return errors.ErrValidationRejected().SetDetail("The prefix can't contain whitespace")
+	}
+
+	res, err := rg.gctx.Crate().Helix.Client().GetUsers(&helix.UsersParams{
+		Logins: []string{name},
+	})
+	if err != nil {
+		return errors.ErrInternalServerError().SetDetail(err.Error())
+	} else if res.Error != "" {
+		return errors.ErrInternalServerError().SetDetail(res.ErrorMessage)
+	} else if len(res.Data.Users) == 0 {
+		return errors.ErrNotFound().SetDetail("Twitch user %v not found", name)
+	}
+
+	user := res.Data.Users[0]
+	err = rg.gctx.Crate().Turso.Queries().InsertChannel(ctx.Context(), db.Channel{
+		ID:              user.ID,
+		Name:            user.Login,
+		Prefix:          prefix,
+		Enabled:         1,
+		ThreadedReplies: 1,
+	})
+	if err != nil {
+		return errors.ErrInternalServerError().SetDetail(err.Error())
+	}
+
+	events.Publish(rg.gctx.Crate().Events, events.ChannelChanged, events.ChannelChangedEvent{ChannelID: user.ID})
+	go rg.reconcile()
+
+	channel, err := rg.getChannel(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(channel)
+}
+
+type UpdateChannelRequest struct {
+	Prefix *string `json:"prefix"`
+	// Enabled is whether the bot joins the channel
+	Enabled         *bool `json:"enabled"`
+	ThreadedReplies *bool `json:"threaded_replies"`
+	CooldownNotice  *bool `json:"cooldown_notice"`
+	CooldownBuckets *bool `json:"cooldown_buckets"`
+}
+
+// UpdateChannel changes the settings of the channel, settings which aren't given are left untouched
+func (rg *RouteGroup) UpdateChannel(ctx *respond.Ctx) error {
+	channelID, err := routes.ChannelID(rg.gctx, ctx)
+	if err != nil {
+		return err
+	}
+
+	var req UpdateChannelRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return errors.ErrBadRequest().SetDetail(err.Error())
+	}
+
+	queries := rg.gctx.Crate().Turso.Queries()
+
+	if req.Prefix != nil {
+		if *req.Prefix == "" || !validPrefix(*req.Prefix) {
+			return errors.ErrValidationRejected().SetDetail("The prefix can't be empty or contain whitespace")
+		}
+		if err := queries.UpdateChannelPrefix(ctx.Context(), channelID, *req.Prefix); err != nil {
+			return errors.ErrInternalServerError().SetDetail(err.Error())
+		}
+	}
+
+	for _, setting := range []struct {
+		value  *bool
+		update func(ctx context.Context, channelID string, value int) error
+	}{
+		{req.Enabled, queries.UpdateChannelEnabled},
+		{req.ThreadedReplies, queries.UpdateChannelThreadedReplies},
+		{req.CooldownNotice, queries.UpdateChannelCooldownNotice},
+		{req.CooldownBuckets, queries.UpdateChannelCooldownBuckets},
+	} {
+		if setting.value == nil {
+			continue
+		}
+		if err := setting.update(ctx.Context(), channelID, utils.BoolToInt(*setting.value)); err != nil {
+			return errors.ErrInternalServerError().SetDetail(err.Error())
+		}
+	}
+
+	events.Publish(rg.gctx.Crate().Events, events.ChannelChanged, events.ChannelChangedEvent{ChannelID: channelID})
+	// Enabled channels need their EventSub subscriptions, disabled ones don't anymore
+	if req.Enabled != nil {
+		go rg.reconcile()
+	}
+
+	channel, err := rg.getChannel(ctx, channelID)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(channel)
+}
+
+func (rg *RouteGroup) getChannel(ctx *respond.Ctx, channelID string) (domain.Channel, error) {
+	stored, err := rg.gctx.Crate().Turso.Queries().GetChannel(ctx.Context(), channelID)
+	if goerrors.Is(err, sql.ErrNoRows) {
+		return domain.Channel{}, errors.ErrNotFound().SetDetail("Channel not found")
+	} else if err != nil {
+		return domain.Channel{}, errors.ErrInternalServerError().SetDetail(err.Error())
+	}
+
+	return toChannel(*stored), nil
+}
+
+// reconcile creates the EventSub subscriptions of the channels which were added or enabled and deletes the ones of
+// the channels which were disabled
+func (rg *RouteGroup) reconcile() {
+	result, err := rg.gctx.Crate().EventSub.Reconcile(rg.gctx)
+	if goerrors.Is(err, eventsub.ErrNoCallback) || goerrors.Is(err, eventsub.ErrNoSession) {
+		slog.Warn("EventSub subscriptions can't be reconciled right now", "error", err)
+		return
+	} else if err != nil {
+		slog.Error("Error reconciling EventSub subscriptions", "error", err)
+		return
+	}
+
+	slog.Info("EventSub subscriptions reconciled", "created", len(result.Created), "deleted", len(result.Deleted), "kept", result.Kept)
+}
+
+func validPrefix(prefix string) bool {
+	return !strings.ContainsFunc(prefix, unicode.IsSpace)
+}
+
+func toChannel(stored db.Channel) domain.Channel {
+	return domain.Channel{
+		ID:              stored.ID,
+		Name:            stored.Name,
+		Prefix:          stored.Prefix,
+		Enabled:         stored.Enabled == 1,
+		ThreadedReplies: stored.ThreadedReplies == 1,
+		CooldownNotice:  stored.CooldownNotice == 1,
+		CooldownBuckets: stored.CooldownBuckets == 1,
+	}
+}
@@ -1,7 +1,9 @@
 package commands
 
 import (
+	"github.com/esfands/retpaladinbot/internal/cmdmanager"
 	"github.com/esfands/retpaladinbot/internal/rest/v1/respond"
+	"github.com/esfands/retpaladinbot/internal/rest/v1/routes"
 	"github.com/esfands/retpaladinbot/pkg/domain"
 	"github.com/esfands/retpaladinbot/pkg/errors"
 	"github.com/esfands/retpaladinbot/pkg/utils"
@@ -15,17 +17,32 @@ func convertToPermissions(strings []string) []domain.Permission {
 	return permissions
 }
 
+// channelID resolves the channel given in the `channel` query parameter, defaulting to the configured channel
+func (rg *RouteGroup) channelID(ctx *respond.Ctx) (string, error) {
+	return routes.ChannelID(rg.gctx, ctx)
+}
+
 type GetCommandsResponse struct {
 	DefaultCommands []domain.Command       `json:"default_commands"`
 	CustomCommands  []domain.CustomCommand `json:"custom_commands"`
 }
 
 func (rg *RouteGroup) GetCommands(ctx *respond.Ctx) error {
+	channelID, err := rg.channelID(ctx)
+	if err != nil {
+		return err
+	}
+
 	storedDefaultCommands, err := rg.gctx.Crate().Turso.Queries().GetAllDefaultCommands(ctx.Context())
 	if err != nil {
 		return errors.ErrInternalServerError().SetDetail(err.Error())
 	}
 
+	overrides, err := cmdmanager.GetOverrides(ctx.Context(), rg.gctx.Crate().Turso.Queries(), channelID)
+	if err != nil {
+		return errors.ErrInternalServerError().SetDetail(err.Error())
+	}
+
 	var defaultCommands []domain.Command
 	for _, storedDefaultCommand := range storedDefaultCommands {
 		convertedAliases, err := utils.ConvertJSONStringToSlice(storedDefaultCommand.Aliases)
@@ -44,7 +61,7 @@ func (rg *RouteGroup) GetCommands(ctx *respond.Ctx) error {
 			return errors.ErrInternalServerError().SetDetail(err.Error())
 		}
 
-		defaultCommands = append(defaultCommands, domain.Command{
+		defaultCommands = append(defaultCommands, overrides[storedDefaultCommand.Name].Apply(domain.Command{
 			Name:               storedDefaultCommand.Name,
 			Aliases:            convertedAliases,
 			Description:        storedDefaultCommand.Description,
@@ -55,21 +72,21 @@ func (rg *RouteGroup) GetCommands(ctx *respond.Ctx) error {
 			EnabledOnline:      storedDefaultCommand.EnabledOnline == 1,
 			UsageCount:         storedDefaultCommand.UsageCount,
 			Permissions:        convertToPermissions(convertedPermissions),
-		})
+		}))
 	}
 
-	storedCustomCommands, err := rg.gctx.Crate().Turso.Queries().GetAllCustomCommands(ctx.Context())
+	storedCustomCommands, err := rg.gctx.Crate().Turso.Queries().GetChannelCustomCommands(ctx.Context(), channelID)
 	if err != nil {
 		return errors.ErrInternalServerError().SetDetail(err.Error())
 	}
 
 	var customCommands []domain.CustomCommand
 	for _, storedCustomCommand := range storedCustomCommands {
-		customCommands = append(customCommands, domain.CustomCommand{
-			Name:       storedCustomCommand.Name,
-			Response:   storedCustomCommand.Response,
-			UsageCount: storedCustomCommand.UsageCount,
-		})
+		customCommand, err := cmdmanager.ToCustomCommand(storedCustomCommand)
+		if err != nil {
+			return errors.ErrInternalServerError().SetDetail(err.Error())
+		}
+		customCommands = append(customCommands, customCommand)
 	}
 
 	return ctx.JSON(GetCommandsResponse{
@@ -86,6 +103,11 @@ type GetCommandResponse struct {
 func (rg *RouteGroup) GetCommandByName(ctx *respond.Ctx) error {
 	name := ctx.Params("name")
 
+	channelID, err := rg.channelID(ctx)
+	if err != nil {
+		return err
+	}
+
 	// Query the default commands
 	storedDefaultCommand, err := rg.gctx.Crate().Turso.Queries().GetDefaultCommandByName(ctx.Context(), name)
 	if err == nil && storedDefaultCommand != nil {
@@ -105,7 +127,12 @@ func (rg *RouteGroup) GetCommandByName(ctx *respond.Ctx) error {
 			return errors.ErrInternalServerError().SetDetail(err.Error())
 		}
 
-		command := domain.Command{
+		override, err := cmdmanager.GetOverride(ctx.Context(), rg.gctx.Crate().Turso.Queries(), channelID, storedDefaultCommand.Name)
+		if err != nil {
+			return errors.ErrInternalServerError().SetDetail(err.Error())
+		}
+
+		command := override.Apply(domain.Command{
 			Name:               storedDefaultCommand.Name,
 			Aliases:            convertedAliases,
 			Permissions:        convertToPermissions(convertedPermissions),
@@ -116,18 +143,17 @@ func (rg *RouteGroup) GetCommandByName(ctx *respond.Ctx) error {
 			EnabledOffline:     storedDefaultCommand.EnabledOffline == 1,
 			EnabledOnline:      storedDefaultCommand.EnabledOnline == 1,
 			UsageCount:         storedDefaultCommand.UsageCount,
-		}
+		})
 
 		return ctx.JSON(GetCommandResponse{DefaultCommand: &command, CustomCommand: nil})
 	}
 
 	// Query the custom commands
-	storedCustomCommand, err := rg.gctx.Crate().Turso.Queries().GetCustomCommandByName(ctx.Context(), name)
+	storedCustomCommand, err := rg.gctx.Crate().Turso.Queries().GetCustomCommandByName(ctx.Context(), channelID, name)
 	if err == nil && storedCustomCommand != nil {
-		command := domain.CustomCommand{
-			Name:       storedCustomCommand.Name,
-			Response:   storedCustomCommand.Response,
-			UsageCount: storedCustomCommand.UsageCount,
+		command, err := cmdmanager.ToCustomCommand(*storedCustomCommand)
+		if err != nil {
+			return errors.ErrInternalServerError().SetDetail(err.Error())
 		}
 
 		return ctx.JSON(GetCommandResponse{DefaultCommand: nil, CustomCommand: &command})
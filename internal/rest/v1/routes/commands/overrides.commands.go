@@ -0,0 +1,110 @@
+package commands
+
+import (
+	"database/sql"
+	goerrors "errors"
+	"slices"
+
+	"github.com/esfands/retpaladinbot/internal/cmdmanager"
+	"github.com/esfands/retpaladinbot/internal/rest/v1/respond"
+	"github.com/esfands/retpaladinbot/pkg/domain"
+	"github.com/esfands/retpaladinbot/pkg/errors"
+	"github.com/gofiber/fiber/v2"
+)
+
+// defaultCommandName makes sure the default command in the `name` parameter exists
+func (rg *RouteGroup) defaultCommandName(ctx *respond.Ctx) (string, error) {
+	name := ctx.Params("name")
+
+	_, err := rg.gctx.Crate().Turso.Queries().GetDefaultCommandByName(ctx.Context(), name)
+	if goerrors.Is(err, sql.ErrNoRows) {
+		return "", errors.ErrNotFound().SetDetail("Default command not found")
+	} else if err != nil {
+		return "", errors.ErrInternalServerError().SetDetail(err.Error())
+	}
+
+	return name, nil
+}
+
+func (rg *RouteGroup) GetCommandOverrides(ctx *respond.Ctx) error {
+	name, err := rg.defaultCommandName(ctx)
+	if err != nil {
+		return err
+	}
+
+	channelID, err := rg.channelID(ctx)
+	if err != nil {
+		return err
+	}
+
+	override, err := cmdmanager.GetOverride(ctx.Context(), rg.gctx.Crate().Turso.Queries(), channelID, name)
+	if err != nil {
+		return errors.ErrInternalServerError().SetDetail(err.Error())
+	}
+
+	return ctx.JSON(override)
+}
+
+// UpdateCommandOverrides replaces the overrides of a default command, fields left out keep the default
+func (rg *RouteGroup) UpdateCommandOverrides(ctx *respond.Ctx) error {
+	name, err := rg.defaultCommandName(ctx)
+	if err != nil {
+		return err
+	}
+
+	channelID, err := rg.channelID(ctx)
+	if err != nil {
+		return err
+	}
+
+	var override domain.CommandOverride
+	if err := ctx.BodyParser(&override); err != nil {
+		return errors.ErrBadRequest().SetDetail(err.Error())
+	}
+
+	override.ChannelID = channelID
+	override.Name = name
+
+	if (override.GlobalCooldown != nil && *override.GlobalCooldown < 0) || (override.UserCooldown != nil && *override.UserCooldown < 0) {
+		return errors.ErrValidationRejected().SetDetail("Cooldowns can't be negative")
+	}
+
+	if override.Permissions != nil {
+		for _, permission := range *override.Permissions {
+			if !slices.Contains(domain.AssignablePermissions, permission) {
+				return errors.ErrValidationRejected().SetDetail("Unknown permission %v", permission)
+			}
+		}
+	}
+
+	problem, err := cmdmanager.NameConflict(ctx.Context(), rg.gctx.Crate().Turso.Queries(), channelID, override.Aliases, name, false)
+	if err != nil {
+		return errors.ErrInternalServerError().SetDetail(err.Error())
+	} else if problem != "" {
+		return errors.ErrValidationRejected().SetDetail("%v", problem)
+	}
+
+	if err := cmdmanager.SaveOverride(ctx.Context(), rg.gctx.Crate().Turso.Queries(), override); err != nil {
+		return errors.ErrInternalServerError().SetDetail(err.Error())
+	}
+
+	return ctx.JSON(override)
+}
+
+func (rg *RouteGroup) DeleteCommandOverrides(ctx *respond.Ctx) error {
+	name, err := rg.defaultCommandName(ctx)
+	if err != nil {
+		return err
+	}
+
+	channelID, err := rg.channelID(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := rg.gctx.Crate().Turso.Queries().DeleteChannelCommand(ctx.Context(), channelID, name); err != nil {
+		return errors.ErrInternalServerError().SetDetail(err.Error())
+	}
+
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
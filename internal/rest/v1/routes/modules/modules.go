@@ -0,0 +1,91 @@
+package modules
+
+import (
+	"github.com/esfands/retpaladinbot/internal/rest/v1/respond"
+	"github.com/esfands/retpaladinbot/internal/rest/v1/routes"
+	"github.com/esfands/retpaladinbot/internal/services/modulestore"
+	"github.com/esfands/retpaladinbot/pkg/errors"
+)
+
+type ModuleResponse struct {
+	modulestore.Module
+	State modulestore.State `json:"state"`
+}
+
+// GetModules lists the bot modules and their state in the channel
+func (rg *RouteGroup) GetModules(ctx *respond.Ctx) error {
+	channelID, err := routes.ChannelID(rg.gctx, ctx)
+	if err != nil {
+		return err
+	}
+
+	store := rg.gctx.Crate().Modules
+
+	response := []ModuleResponse{}
+	for _, module := range store.Modules() {
+		response = append(response, ModuleResponse{
+			Module: module,
+			State:  store.State(channelID, module.Name),
+		})
+	}
+
+	return ctx.JSON(response)
+}
+
+type UpdateModuleRequest struct {
+	Enabled *bool `json:"enabled"`
+	// Settings are the settings to change, an empty value resets a setting to its default
+	Settings map[string]string `json:"settings"`
+}
+
+// UpdateModule enables or disables a module in the channel and changes its settings
+func (rg *RouteGroup) UpdateModule(ctx *respond.Ctx) error {
+	channelID, err := routes.ChannelID(rg.gctx, ctx)
+	if err != nil {
+		return err
+	}
+
+	store := rg.gctx.Crate().Modules
+
+	module, ok := store.Get(ctx.Params("name"))
+	if !ok {
+		return errors.ErrNotFound().SetDetail("Module not found")
+	}
+
+	var req UpdateModuleRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return errors.ErrBadRequest().SetDetail(err.Error())
+	}
+
+	for setting := range req.Settings {
+		if !hasSetting(module, setting) {
+			return errors.ErrValidationRejected().SetDetail("Unknown setting %v", setting)
+		}
+	}
+
+	if req.Enabled != nil {
+		if err := store.SetEnabled(ctx.Context(), channelID, module.Name, *req.Enabled); err != nil {
+			return errors.ErrInternalServerError().SetDetail(err.Error())
+		}
+	}
+
+	for setting, value := range req.Settings {
+		if err := store.SetSetting(ctx.Context(), channelID, module.Name, setting, value); err != nil {
+			return errors.ErrInternalServerError().SetDetail(err.Error())
+		}
+	}
+
+	return ctx.JSON(ModuleResponse{
+		Module: module,
+		State:  store.State(channelID, module.Name),
+	})
+}
+
+func hasSetting(module modulestore.Module, name string) bool {
+	for _, setting := range module.Settings {
+		if setting.Name == name {
+			return true
+		}
+	}
+	return false
+}
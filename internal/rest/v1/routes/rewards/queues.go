@@ -0,0 +1,57 @@
+package rewards
+
+import (
+	"strings"
+
+	"github.com/esfands/retpaladinbot/internal/rest/v1/respond"
+	"github.com/esfands/retpaladinbot/internal/rest/v1/routes"
+	"github.com/esfands/retpaladinbot/pkg/errors"
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetQueue lists the users in a queue of the channel, the one who joined first is first
+func (rg *RouteGroup) GetQueue(ctx *respond.Ctx) error {
+	channelID, err := routes.ChannelID(rg.gctx, ctx)
+	if err != nil {
+		return err
+	}
+
+	entries, err := rg.gctx.Crate().Rewards.Queue(ctx.Context(), channelID, strings.ToLower(ctx.Params("name")))
+	if err != nil {
+		return errors.ErrInternalServerError().SetDetail(err.Error())
+	}
+
+	return ctx.JSON(entries)
+}
+
+// ClearQueue removes every user from a queue of the channel
+func (rg *RouteGroup) ClearQueue(ctx *respond.Ctx) error {
+	channelID, err := routes.ChannelID(rg.gctx, ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := rg.gctx.Crate().Rewards.ClearQueue(ctx.Context(), channelID, strings.ToLower(ctx.Params("name"))); err != nil {
+		return errors.ErrInternalServerError().SetDetail(err.Error())
+	}
+
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
+
+// DeleteQueueEntry removes a user from a queue of the channel, e.g. once it was their turn
+func (rg *RouteGroup) DeleteQueueEntry(ctx *respond.Ctx) error {
+	channelID, err := routes.ChannelID(rg.gctx, ctx)
+	if err != nil {
+		return err
+	}
+
+	removed, err := rg.gctx.Crate().Rewards.Dequeue(ctx.Context(), channelID, strings.ToLower(ctx.Params("name")), ctx.Params("user"))
+	if err != nil {
+		return errors.ErrInternalServerError().SetDetail(err.Error())
+	}
+	if !removed {
+		return errors.ErrNotFound().SetDetail("The user isn't in the queue")
+	}
+
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
@@ -0,0 +1,184 @@
+package rewards
+
+import (
+	goerrors "errors"
+
+	"github.com/esfands/retpaladinbot/internal/rest/v1/respond"
+	"github.com/esfands/retpaladinbot/internal/rest/v1/routes"
+	"github.com/esfands/retpaladinbot/internal/services/rewards"
+	"github.com/esfands/retpaladinbot/pkg/domain"
+	"github.com/esfands/retpaladinbot/pkg/errors"
+	"github.com/gofiber/fiber/v2"
+	"github.com/nicklaw5/helix/v2"
+)
+
+type RewardResponse struct {
+	helix.ChannelCustomReward
+	// Action is what the bot does when the reward is redeemed, it's null for rewards without one
+	Action *domain.RewardAction `json:"action"`
+}
+
+// GetRewards lists the channel point rewards of the channel along with their actions
+func (rg *RouteGroup) GetRewards(ctx *respond.Ctx) error {
+	channelID, err := routes.ChannelID(rg.gctx, ctx)
+	if err != nil {
+		return err
+	}
+
+	list, err := rg.gctx.Crate().Rewards.Rewards(channelID)
+	if err != nil {
+		return rewardError(err)
+	}
+
+	actions, err := rg.gctx.Crate().Rewards.Actions(ctx.Context(), channelID)
+	if err != nil {
+		return errors.ErrInternalServerError().SetDetail(err.Error())
+	}
+	byReward := make(map[string]domain.RewardAction, len(actions))
+	for _, action := range actions {
+		byReward[action.RewardID] = action
+	}
+
+	response := make([]RewardResponse, 0, len(list))
+	for _, reward := range list {
+		r := RewardResponse{ChannelCustomReward: reward}
+		if action, ok := byReward[reward.ID]; ok {
+			r.Action = &action
+		}
+		response = append(response, r)
+	}
+
+	return ctx.JSON(response)
+}
+
+// CreateReward creates a channel point reward in the channel
+func (rg *RouteGroup) CreateReward(ctx *respond.Ctx) error {
+	channelID, err := routes.ChannelID(rg.gctx, ctx)
+	if err != nil {
+		return err
+	}
+
+	var req rewards.RewardOptions
+	if err := ctx.BodyParser(&req); err != nil {
+		return errors.ErrBadRequest().SetDetail(err.Error())
+	}
+
+	reward, err := rg.gctx.Crate().Rewards.CreateReward(channelID, req)
+	if err != nil {
+		return rewardError(err)
+	}
+
+	ctx.Status(fiber.StatusCreated)
+	return ctx.JSON(RewardResponse{ChannelCustomReward: reward})
+}
+
+// UpdateReward changes the settings of a channel point reward the bot created, settings which aren't sent are kept
+func (rg *RouteGroup) UpdateReward(ctx *respond.Ctx) error {
+	channelID, err := routes.ChannelID(rg.gctx, ctx)
+	if err != nil {
+		return err
+	}
+
+	var req rewards.RewardOptions
+	if err := ctx.BodyParser(&req); err != nil {
+		return errors.ErrBadRequest().SetDetail(err.Error())
+	}
+
+	reward, err := rg.gctx.Crate().Rewards.UpdateReward(channelID, ctx.Params("id"), req)
+	if err != nil {
+		return rewardError(err)
+	}
+
+	return ctx.JSON(RewardResponse{ChannelCustomReward: reward})
+}
+
+// GetRewardActions lists the actions of the channel point rewards of the channel
+func (rg *RouteGroup) GetRewardActions(ctx *respond.Ctx) error {
+	channelID, err := routes.ChannelID(rg.gctx, ctx)
+	if err != nil {
+		return err
+	}
+
+	actions, err := rg.gctx.Crate().Rewards.Actions(ctx.Context(), channelID)
+	if err != nil {
+		return errors.ErrInternalServerError().SetDetail(err.Error())
+	}
+
+	return ctx.JSON(actions)
+}
+
+type UpdateRewardActionRequest struct {
+	Action   domain.RewardActionType `json:"action"`
+	Target   string                  `json:"target"`
+	Amount   int                     `json:"amount"`
+	Template string                  `json:"template"`
+	Complete bool                    `json:"complete"`
+	Enabled  *bool                   `json:"enabled"`
+}
+
+// UpdateRewardAction sets what the bot does when a channel point reward of the channel is redeemed
+func (rg *RouteGroup) UpdateRewardAction(ctx *respond.Ctx) error {
+	channelID, err := routes.ChannelID(rg.gctx, ctx)
+	if err != nil {
+		return err
+	}
+
+	var req UpdateRewardActionRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return errors.ErrBadRequest().SetDetail(err.Error())
+	}
+
+	action := domain.RewardAction{
+		ChannelID: channelID,
+		RewardID:  ctx.Params("id"),
+		Action:    req.Action,
+		Target:    req.Target,
+		Amount:    req.Amount,
+		Template:  req.Template,
+		Complete:  req.Complete,
+		Enabled:   req.Enabled == nil || *req.Enabled,
+	}
+	if err := rewards.Validate(&action); err != nil {
+		return errors.ErrValidationRejected().SetDetail(err.Error())
+	}
+	if _, err := rg.variables.Parse(action.Template); err != nil {
+		return errors.ErrValidationRejected().SetDetail("Invalid template: %v", err)
+	}
+
+	action, err = rg.gctx.Crate().Rewards.SaveAction(ctx.Context(), action)
+	if err != nil {
+		return errors.ErrInternalServerError().SetDetail(err.Error())
+	}
+
+	return ctx.JSON(action)
+}
+
+// DeleteRewardAction removes the action of a channel point reward of the channel
+func (rg *RouteGroup) DeleteRewardAction(ctx *respond.Ctx) error {
+	channelID, err := routes.ChannelID(rg.gctx, ctx)
+	if err != nil {
+		return err
+	}
+
+	removed, err := rg.gctx.Crate().Rewards.RemoveAction(ctx.Context(), channelID, ctx.Params("id"))
+	if err != nil {
+		return errors.ErrInternalServerError().SetDetail(err.Error())
+	}
+	if !removed {
+		return errors.ErrNotFound().SetDetail("The reward has no action")
+	}
+
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
+
+// rewardError turns an error of Twitch about rewards into the error of the API
+func rewardError(err error) error {
+	switch {
+	case goerrors.Is(err, rewards.ErrNotLoggedIn):
+		return errors.ErrInsufficientPermissions().SetDetail(err.Error())
+	case goerrors.Is(err, rewards.ErrRewardNotFound):
+		return errors.ErrNotFound().SetDetail(err.Error())
+	default:
+		return errors.ErrValidationRejected().SetDetail(err.Error())
+	}
+}
@@ -0,0 +1,18 @@
+package rewards
+
+import (
+	"github.com/esfands/retpaladinbot/internal/bot/variables"
+	"github.com/esfands/retpaladinbot/internal/global"
+)
+
+type RouteGroup struct {
+	gctx      global.Context
+	variables variables.ServiceI
+}
+
+func NewRouteGroup(gctx global.Context) *RouteGroup {
+	return &RouteGroup{
+		gctx:      gctx,
+		variables: variables.NewService(gctx),
+	}
+}
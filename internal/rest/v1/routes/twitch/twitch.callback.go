@@ -2,15 +2,18 @@ package twitch
 
 import (
 	"context"
+	"database/sql"
+	goerrors "errors"
 	"log/slog"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/esfands/retpaladinbot/internal/rest/v1/respond"
+	"github.com/esfands/retpaladinbot/internal/rest/v1/routes"
 	"github.com/esfands/retpaladinbot/internal/services/auth"
 	"github.com/esfands/retpaladinbot/pkg/errors"
 	"github.com/esfands/retpaladinbot/pkg/utils"
-	"github.com/nicklaw5/helix/v2"
 )
 
 func (rg *RouteGroup) LoginCallback(ctx *respond.Ctx) error {
@@ -46,22 +49,43 @@ func (rg *RouteGroup) LoginCallback(ctx *respond.Ctx) error {
 		return err
 	}
 
-	// Set the user access token
-	rg.gctx.Crate().Helix.Client().SetUserAccessToken(twitchToken.AccessToken)
-
-	// Get user that authenticated
-	userReq, err := rg.gctx.Crate().Helix.Client().GetUsers(&helix.UsersParams{})
+	// Get user that authenticated, the token is only kept when a broadcaster connects their channel
+	validation, err := rg.gctx.Crate().Helix.ValidateToken(twitchToken.AccessToken)
 	if err != nil {
-		slog.Error("[twitch-login-callback] error getting user", "error", err.Error())
+		slog.Error("[twitch-login-callback] error validating token", "error", err.Error())
+		return errors.ErrInternalServerError()
+	}
+
+	user := validation.Data
+
+	// Only the broadcasters of the channels the bot joins and its admins may use the dashboard
+	_, err = rg.gctx.Crate().Turso.Queries().GetChannel(ctx.Context(), user.UserID)
+	broadcaster := err == nil
+	if err != nil && !goerrors.Is(err, sql.ErrNoRows) {
+		slog.Error("[twitch-login-callback] error getting channel", "error", err.Error())
 		return errors.ErrInternalServerError()
 	}
+	if !broadcaster && !routes.IsAdmin(rg.gctx, user.UserID) {
+		slog.Warn("[twitch-login-callback] user isn't allowed to use the dashboard", "user", user.Login)
+		return errors.ErrInsufficientPermissions()
+	}
 
-	user := userReq.Data.Users[0]
+	if strings.HasPrefix(state, connectState) {
+		if !broadcaster {
+			return errors.ErrInsufficientPermissions().SetDetail("Only the broadcasters of the channels the bot joins can connect their account")
+		}
 
-	// TODO: Check if user is whitelisted to be able to access the dashboard
+		err := rg.gctx.Crate().Helix.Connect(ctx.Context(), user.UserID, twitchToken.AccessToken, twitchToken.RefreshToken, user.Scopes)
+		if err != nil {
+			slog.Error("[twitch-login-callback] error storing broadcaster token", "error", err.Error())
+			return errors.ErrInternalServerError()
+		}
+
+		slog.Info("[twitch-login-callback] broadcaster connected", "user", user.Login, "scopes", user.Scopes)
+	}
 
 	accessToken, expiry, err := rg.gctx.Crate().Auth.CreateAccessToken(
-		user.ID,
+		user.UserID,
 	)
 	if err != nil {
 		slog.Error("[twitch-login-callback] error creating access token", "error", err.Error())
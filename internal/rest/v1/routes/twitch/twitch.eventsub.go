@@ -3,90 +3,72 @@ package twitch
 import (
 	"bytes"
 	"crypto/hmac"
-	"crypto/sha256"
-	"database/sql"
-	"encoding/hex"
 	"encoding/json"
-	"fmt"
 	"log/slog"
 	"net/http"
 	"time"
 
-	"github.com/esfands/retpaladinbot/internal/db"
 	"github.com/esfands/retpaladinbot/internal/rest/v1/respond"
+	"github.com/esfands/retpaladinbot/internal/services/eventsub"
 	"github.com/esfands/retpaladinbot/pkg/errors"
 	"github.com/esfands/retpaladinbot/pkg/utils"
-	"github.com/nicklaw5/helix/v2"
 )
 
 func (rg *RouteGroup) EventSubRecievedNotification(ctx *respond.Ctx) error {
 	body := ctx.Request().Body()
 
+	messageID := utils.B2S(ctx.Request().Header.Peek("Twitch-Eventsub-Message-Id"))
+	timestamp := utils.B2S(ctx.Request().Header.Peek("Twitch-Eventsub-Message-Timestamp"))
+	signature := utils.B2S(ctx.Request().Header.Peek("Twitch-Eventsub-Message-Signature"))
+	messageType := utils.B2S(ctx.Request().Header.Peek("Twitch-Eventsub-Message-Type"))
+
+	if messageID == "" || timestamp == "" || signature == "" || messageType == "" {
+		slog.Error("[eventsub] missing eventsub headers")
+		return errors.ErrBadRequest().SetDetail("Missing EventSub headers")
+	}
+
 	// Verify Twitch sent the event
-	mac := hmac.New(sha256.New, utils.S2B(rg.gctx.Config().Twitch.Helix.EventSubSecret))
-
-	mac.Write(
-		utils.S2B(
-			fmt.Sprintf(
-				"%s%s%s",
-				utils.B2S(ctx.Request().Header.Peek("Twitch-Eventsub-Message-Id")),
-				utils.B2S(ctx.Request().Header.Peek("Twitch-Eventsub-Message-Timestamp")),
-				utils.B2S(body),
-			),
-		),
-	)
-	hmacsha256 := fmt.Sprintf("sha256=%s", hex.EncodeToString(mac.Sum(nil)))
-
-	if hmacsha256 != utils.B2S(ctx.Request().Header.Peek("Twitch-Eventsub-Message-Signature")) {
+	expected := eventsub.Signature(rg.gctx.Config().Twitch.Helix.EventSubSecret, messageID, timestamp, body)
+	if !hmac.Equal(utils.S2B(expected), utils.S2B(signature)) {
 		slog.Error("[eventsub] invalid signature on subscription")
 		return errors.ErrInvalidSignature().SetDetail("No valid signature on subscription")
 	}
 
+	// Old messages are rejected so a captured message can't be replayed once its ID is forgotten
+	sentAt, err := time.Parse(time.RFC3339Nano, timestamp)
+	if err != nil {
+		slog.Error("[eventsub] invalid message timestamp", "timestamp", timestamp)
+		return errors.ErrBadRequest().SetDetail("Invalid message timestamp")
+	}
+	if time.Since(sentAt) > eventsub.MaxMessageAge {
+		slog.Warn("[eventsub] rejected an expired message", "id", messageID, "timestamp", timestamp)
+		return errors.ErrExpiredMessage().SetDetail("The message is older than %v", eventsub.MaxMessageAge)
+	}
+
 	var vals utils.EventSubNotification
-	err := json.NewDecoder(bytes.NewReader(body)).Decode(&vals)
+	err = json.NewDecoder(bytes.NewReader(body)).Decode(&vals)
 	if err != nil {
 		slog.Error("[eventsub] couldn't decode the eventsub notification", "error", err.Error())
 		return errors.ErrBadRequest().SetDetail("Could not decode body")
 	}
 
-	messageType := utils.B2S(ctx.Request().Header.Peek("Twitch-Eventsub-Message-Type"))
+	// Twitch retries messages it didn't get an answer to in time, a duplicate is acknowledged without handling it again
+	if messageType != eventsub.MessageVerification && rg.gctx.Crate().EventSub.Seen(messageID) {
+		slog.Info("[eventsub] ignoring a duplicate message", "id", messageID, "type", vals.Subscription.Type)
+		return ctx.SendStatus(http.StatusNoContent)
+	}
 
-	fmt.Println(messageType)
 	switch messageType {
-	case "notification":
-		ctx.Response().SetStatusCode(http.StatusOK)
-
-		switch vals.Subscription.Type {
-		case "stream.online":
-			var streamOnlinePayload helix.EventSubStreamOnlineEvent
-			if err := json.Unmarshal(vals.Event, &streamOnlinePayload); err != nil {
-				slog.Error("[eventsub] couldn't unmarshal the stream.online event", "error", err.Error())
-				return errors.ErrBadRequest().SetDetail("Could not unmarshal stream.online event")
-			}
-
-			rg.streamOnline(streamOnlinePayload)
-
-		case "stream.offline":
-			var streamOfflinePayload helix.EventSubStreamOfflineEvent
-			if err := json.Unmarshal(vals.Event, &streamOfflinePayload); err != nil {
-				slog.Error("[eventsub] couldn't unmarshal the stream.offline event", "error", err.Error())
-				return errors.ErrBadRequest().SetDetail("Could not unmarshal stream.offline event")
-			}
-
-			rg.streamOffline(streamOfflinePayload)
-
-		case "channel.update":
-			var channelUpdatePayload helix.EventSubChannelUpdateEvent
-			if err := json.Unmarshal(vals.Event, &channelUpdatePayload); err != nil {
-				slog.Error("[eventsub] couldn't unmarshal the channel.update event", "error", err.Error())
-				return errors.ErrBadRequest().SetDetail("Could not unmarshal channel.update event")
-			}
-
-			rg.channelUpdate(channelUpdatePayload)
+	case eventsub.MessageNotification:
+		if err := rg.gctx.Crate().EventSub.Notify(rg.gctx, messageID, vals.Subscription, vals.Event); err != nil {
+			slog.Error("[eventsub] couldn't handle the notification", "type", vals.Subscription.Type, "error", err.Error())
+			return errors.ErrBadRequest().SetDetail("Could not unmarshal %v event", vals.Subscription.Type)
 		}
 
-	case "webhook_callback_verification":
-		fmt.Println("=== CHALLENGE ===")
+		ctx.Response().SetStatusCode(http.StatusOK)
+
+	case eventsub.MessageVerification:
+		slog.Info("[eventsub] answering the challenge", "type", vals.Subscription.Type)
 		ctx.Response().SetStatusCode(http.StatusOK)
 
 		_, err = ctx.Write(utils.S2B(vals.Challenge))
@@ -95,83 +77,17 @@ func (rg *RouteGroup) EventSubRecievedNotification(ctx *respond.Ctx) error {
 			return errors.ErrInternalServerError().SetDetail("Could not write challenge")
 		}
 
-	case "revocation":
-		ctx.Response().SetStatusCode(http.StatusOK)
-
-		fmt.Println("=== REVOCATION ===")
-		fmt.Println(vals.Subscription)
-	}
-
-	return nil
-}
-
-func (rg RouteGroup) streamOnline(event helix.EventSubStreamOnlineEvent) {
-	fmt.Println("=== STREAM ONLINE ===")
-	fmt.Println(event)
-
-	// Get the channel information from Helix
-	channelInfoRes, err := rg.gctx.Crate().Helix.Client().GetChannelInformation(&helix.GetChannelInformationParams{
-		BroadcasterIDs: []string{event.BroadcasterUserID},
-	})
-	if err != nil {
-		slog.Error("[eventsub] couldn't get the channel information", "error", err.Error())
-		return
-	}
-
-	channelInfo := channelInfoRes.Data.Channels[0]
-
-	rg.gctx.Crate().Turso.Queries().InsertStream(rg.gctx, db.StreamStatus{
-		StreamID:  event.ID,
-		GameID:    sql.NullString{String: channelInfo.GameID, Valid: true},
-		GameName:  sql.NullString{String: channelInfo.GameName, Valid: true},
-		Live:      true,
-		Title:     sql.NullString{String: channelInfo.Title, Valid: true},
-		StartedAt: event.StartedAt.Format(time.RFC3339),
-		EndedAt:   sql.NullString{String: "", Valid: false},
-	})
-}
-
-func (rg RouteGroup) streamOffline(event helix.EventSubStreamOfflineEvent) {
-	fmt.Println("=== STREAM OFFLINE ===")
-	fmt.Println(event)
+	case eventsub.MessageRevocation:
+		slog.Warn("[eventsub] subscription revoked", "id", vals.Subscription.ID, "type", vals.Subscription.Type, "reason", vals.Subscription.Status)
 
-	// First get the stream from the database to get the ID of the current live stream
-	currentLiveStream, err := rg.gctx.Crate().Turso.Queries().GetLiveStream(rg.gctx)
-	if err != nil {
-		slog.Error("[eventsub] couldn't get the currently live stream", "error", err.Error())
-		return
-	}
+		// Resubscribing makes Twitch call back for the challenge, so it's done after answering
+		go rg.gctx.Crate().EventSub.Revoked(rg.gctx, vals.Subscription)
 
-	// Update the stream that went offline
-	if err := rg.gctx.Crate().Turso.Queries().StreamWentOffline(rg.gctx, currentLiveStream.StreamID, sql.NullString{String: time.Now().Format(time.RFC3339), Valid: true}); err != nil {
-		slog.Error("[eventsub] couldn't update the stream that went offline", "error", err.Error())
-		return
-	}
-}
+		ctx.Response().SetStatusCode(http.StatusNoContent)
 
-func (rg RouteGroup) channelUpdate(event helix.EventSubChannelUpdateEvent) {
-	fmt.Println("=== CHANNEL UPDATE ===")
-	// First get the stream from the database to get the ID of the latest stream
-	recentStream, err := rg.gctx.Crate().Turso.Queries().GetMostRecentStreamStatus(rg.gctx)
-	if err != nil {
-		slog.Error("[eventsub] couldn't get the most recent stream status", "error", err.Error())
-		return
+	default:
+		return errors.ErrBadRequest().SetDetail("Unknown message type %v", messageType)
 	}
 
-	err = rg.gctx.Crate().Turso.Queries().UpdateStreamInfo(rg.gctx, db.StreamStatus{
-		ID:        recentStream.ID,
-		StreamID:  recentStream.StreamID,
-		GameID:    sql.NullString{String: event.CategoryID, Valid: true},
-		GameName:  sql.NullString{String: event.CategoryName, Valid: true},
-		Live:      recentStream.Live,
-		Title:     sql.NullString{String: event.Title, Valid: true},
-		StartedAt: recentStream.StartedAt,
-		EndedAt:   recentStream.EndedAt,
-	})
-	if err != nil {
-		slog.Error("[eventsub] couldn't update the stream info", "error", err.Error())
-		return
-	}
-
-	fmt.Println(recentStream)
+	return nil
 }
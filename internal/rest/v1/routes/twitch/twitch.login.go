@@ -13,14 +13,27 @@ import (
 	"github.com/nicklaw5/helix/v2"
 )
 
+// connectState starts the state of the connect flow, so the callback knows to keep the token of the broadcaster
+const connectState = "connect-"
+
+// Login sends the user to Twitch to log in to the dashboard, which only tells who they are
 func (rg *RouteGroup) Login(ctx *respond.Ctx) error {
+	return rg.authorize(ctx, "", rg.gctx.Crate().Auth.GetTwitchScopes())
+}
+
+// Connect sends a broadcaster to Twitch to grant the bot the permissions it acts on their channel with
+func (rg *RouteGroup) Connect(ctx *respond.Ctx) error {
+	return rg.authorize(ctx, connectState, rg.gctx.Crate().Auth.GetBroadcasterScopes())
+}
+
+func (rg *RouteGroup) authorize(ctx *respond.Ctx, prefix string, scopes []string) error {
 	var tokenBytes [255]byte
 	if _, err := rand.Read(tokenBytes[:]); err != nil {
 		slog.Error("[twitch-login] error generating bytes", "error", err.Error())
 		return errors.ErrInternalServerError()
 	}
 
-	state := hex.EncodeToString(tokenBytes[:])
+	state := prefix + hex.EncodeToString(tokenBytes[:])
 
 	// Create CSRF token
 	csrfToken, err := rg.gctx.Crate().Auth.CreateCSRFToken(state)
@@ -36,7 +49,7 @@ func (rg *RouteGroup) Login(ctx *respond.Ctx) error {
 	// Redirect to provider
 	return ctx.Redirect(rg.gctx.Crate().Helix.Client().GetAuthorizationURL(&helix.AuthorizationURLParams{
 		ResponseType: "code",
-		Scopes:       rg.gctx.Crate().Auth.GetTwitchScopes(),
+		Scopes:       scopes,
 		State:        state,
 		ForceVerify:  false,
 	}), http.StatusTemporaryRedirect)
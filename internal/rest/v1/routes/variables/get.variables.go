@@ -0,0 +1,10 @@
+package variables
+
+import (
+	"github.com/esfands/retpaladinbot/internal/rest/v1/respond"
+)
+
+// GetVariables lists the variables which can be used in custom command responses
+func (rg *RouteGroup) GetVariables(ctx *respond.Ctx) error {
+	return ctx.JSON(rg.variables.List())
+}
@@ -4,8 +4,14 @@ import (
 	"github.com/esfands/retpaladinbot/internal/global"
 	"github.com/esfands/retpaladinbot/internal/rest/v1/respond"
 	"github.com/esfands/retpaladinbot/internal/rest/v1/routes"
+	"github.com/esfands/retpaladinbot/internal/rest/v1/routes/admin"
+	"github.com/esfands/retpaladinbot/internal/rest/v1/routes/announcements"
+	"github.com/esfands/retpaladinbot/internal/rest/v1/routes/channels"
 	"github.com/esfands/retpaladinbot/internal/rest/v1/routes/commands"
+	"github.com/esfands/retpaladinbot/internal/rest/v1/routes/modules"
+	"github.com/esfands/retpaladinbot/internal/rest/v1/routes/rewards"
 	"github.com/esfands/retpaladinbot/internal/rest/v1/routes/twitch"
+	"github.com/esfands/retpaladinbot/internal/rest/v1/routes/variables"
 	"github.com/gofiber/fiber/v2"
 )
 
@@ -20,12 +26,51 @@ func New(gctx global.Context, router fiber.Router) {
 	indexRoute := routes.NewRouteGroup(gctx)
 	router.Get("/", indexRoute.Index)
 
+	channelRoutes := channels.NewRouteGroup(gctx)
+	router.Get("/channel", ctx(channelRoutes.GetChannel))
+	router.Put("/channel", ctx(authorized(gctx, channelRoutes.UpdateChannel)))
+	router.Get("/channels", ctx(adminOnly(gctx, channelRoutes.GetChannels)))
+	router.Post("/channels", ctx(adminOnly(gctx, channelRoutes.CreateChannel)))
+
 	commandRotues := commands.NewRouteGroup(gctx)
 	router.Get("/commands", ctx(commandRotues.GetCommands))
 	router.Get("/commands/:name", ctx(commandRotues.GetCommandByName))
+	router.Get("/commands/:name/overrides", ctx(commandRotues.GetCommandOverrides))
+	router.Put("/commands/:name/overrides", ctx(authorized(gctx, commandRotues.UpdateCommandOverrides)))
+	router.Delete("/commands/:name/overrides", ctx(authorized(gctx, commandRotues.DeleteCommandOverrides)))
+
+	moduleRoutes := modules.NewRouteGroup(gctx)
+	router.Get("/modules", ctx(moduleRoutes.GetModules))
+	router.Put("/modules/:name", ctx(authorized(gctx, moduleRoutes.UpdateModule)))
+
+	announcementRoutes := announcements.NewRouteGroup(gctx)
+	router.Get("/announcements", ctx(announcementRoutes.GetAnnouncements))
+	router.Post("/announcements", ctx(authorized(gctx, announcementRoutes.CreateAnnouncement)))
+	router.Delete("/announcements/:id", ctx(authorized(gctx, announcementRoutes.DeleteAnnouncement)))
+
+	rewardRoutes := rewards.NewRouteGroup(gctx)
+	router.Get("/rewards", ctx(authorized(gctx, rewardRoutes.GetRewards)))
+	router.Post("/rewards", ctx(authorized(gctx, rewardRoutes.CreateReward)))
+	router.Get("/rewards/actions", ctx(authorized(gctx, rewardRoutes.GetRewardActions)))
+	router.Put("/rewards/:id", ctx(authorized(gctx, rewardRoutes.UpdateReward)))
+	router.Put("/rewards/:id/action", ctx(authorized(gctx, rewardRoutes.UpdateRewardAction)))
+	router.Delete("/rewards/:id/action", ctx(authorized(gctx, rewardRoutes.DeleteRewardAction)))
+	router.Get("/queues/:name", ctx(authorized(gctx, rewardRoutes.GetQueue)))
+	router.Delete("/queues/:name", ctx(authorized(gctx, rewardRoutes.ClearQueue)))
+	router.Delete("/queues/:name/:user", ctx(authorized(gctx, rewardRoutes.DeleteQueueEntry)))
+
+	variableRoutes := variables.NewRouteGroup(gctx)
+	router.Get("/variables", ctx(variableRoutes.GetVariables))
+
+	adminRoutes := admin.NewRouteGroup(gctx)
+	router.Get("/admin/eventsub", ctx(adminOnly(gctx, adminRoutes.GetEventSubSubscriptions)))
+	router.Post("/admin/eventsub", ctx(adminOnly(gctx, adminRoutes.CreateEventSubSubscription)))
+	router.Post("/admin/eventsub/reconcile", ctx(adminOnly(gctx, adminRoutes.ReconcileEventSubSubscriptions)))
+	router.Delete("/admin/eventsub/:id", ctx(adminOnly(gctx, adminRoutes.DeleteEventSubSubscription)))
 
 	twitchRoutes := twitch.NewRouteGroup(gctx)
 	router.Get("/twitch/login", ctx(twitchRoutes.Login))
+	router.Get("/twitch/connect", ctx(twitchRoutes.Connect))
 	router.Get("/twitch/redirect", ctx(twitchRoutes.LoginCallback))
 	router.Post("/twitch/eventsub", ctx(twitchRoutes.EventSubRecievedNotification))
 }
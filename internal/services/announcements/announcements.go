@@ -0,0 +1,180 @@
+package announcements
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/esfands/retpaladinbot/internal/db"
+	"github.com/esfands/retpaladinbot/internal/services/scheduler"
+	"github.com/esfands/retpaladinbot/pkg/domain"
+)
+
+// Runner sends an announcement when its schedule fires
+type Runner func(announcement domain.Announcement)
+
+type Service interface {
+	// Add validates, stores and schedules a new announcement, the ID and creation time are filled in
+	Add(ctx context.Context, announcement domain.Announcement) (domain.Announcement, error)
+	// List returns the announcements of a channel
+	List(ctx context.Context, channelID string) ([]domain.Announcement, error)
+	// Remove unschedules and deletes an announcement of a channel and reports whether it existed
+	Remove(ctx context.Context, channelID string, id int) (bool, error)
+	// SetRunner sets what's done when an announcement fires, announcements are skipped until it's set
+	SetRunner(runner Runner)
+}
+
+type announcementsService struct {
+	queries   *db.Queries
+	scheduler scheduler.Service
+
+	mu     sync.RWMutex
+	runner Runner
+}
+
+// Setup schedules the enabled announcements of every channel
+func Setup(ctx context.Context, queries *db.Queries, scheduler scheduler.Service) (Service, error) {
+	s := &announcementsService{
+		queries:   queries,
+		scheduler: scheduler,
+	}
+
+	stored, err := queries.GetAllAnnouncements(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, a := range stored {
+		announcement := toAnnouncement(a)
+		if !announcement.Enabled {
+			continue
+		}
+
+		// A broken announcement shouldn't keep the others from running
+		if err := s.schedule(announcement); err != nil {
+			slog.Error("[announcements] error scheduling announcement", "id", announcement.ID, "channel", announcement.ChannelID, "error", err.Error())
+		}
+	}
+
+	return s, nil
+}
+
+// Validate checks the schedule and condition of an announcement and fills in the defaults
+func Validate(announcement *domain.Announcement) error {
+	announcement.Cron = strings.TrimSpace(announcement.Cron)
+	announcement.Message = strings.TrimSpace(announcement.Message)
+
+	if announcement.Timezone == "" {
+		announcement.Timezone = "UTC"
+	}
+	if announcement.Condition == "" {
+		announcement.Condition = domain.StreamAlways
+	}
+
+	if announcement.Message == "" {
+		return errors.New("the message can't be empty")
+	}
+	if !announcement.Condition.Valid() {
+		return fmt.Errorf("unknown condition %q, it has to be always, live or offline", announcement.Condition)
+	}
+
+	return scheduler.ValidateCron(announcement.Cron, announcement.Timezone)
+}
+
+func (s *announcementsService) Add(ctx context.Context, announcement domain.Announcement) (domain.Announcement, error) {
+	if err := Validate(&announcement); err != nil {
+		return announcement, err
+	}
+
+	announcement.Enabled = true
+	announcement.CreatedAt = time.Now().Format(time.RFC3339)
+
+	id, err := s.queries.InsertAnnouncement(ctx, db.Announcement{
+		ChannelID: announcement.ChannelID,
+		Cron:      announcement.Cron,
+		Timezone:  announcement.Timezone,
+		Message:   announcement.Message,
+		Condition: string(announcement.Condition),
+		Enabled:   1,
+		CreatedAt: announcement.CreatedAt,
+	})
+	if err != nil {
+		return announcement, err
+	}
+	announcement.ID = id
+
+	if err := s.schedule(announcement); err != nil {
+		return announcement, err
+	}
+
+	return announcement, nil
+}
+
+func (s *announcementsService) List(ctx context.Context, channelID string) ([]domain.Announcement, error) {
+	stored, err := s.queries.GetChannelAnnouncements(ctx, channelID)
+	if err != nil {
+		return nil, err
+	}
+
+	announcements := make([]domain.Announcement, 0, len(stored))
+	for _, a := range stored {
+		announcements = append(announcements, toAnnouncement(a))
+	}
+	return announcements, nil
+}
+
+func (s *announcementsService) Remove(ctx context.Context, channelID string, id int) (bool, error) {
+	_, err := s.queries.GetAnnouncement(ctx, channelID, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	s.scheduler.Remove(tag(id))
+
+	return s.queries.DeleteAnnouncement(ctx, channelID, id)
+}
+
+func (s *announcementsService) SetRunner(runner Runner) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.runner = runner
+}
+
+func (s *announcementsService) schedule(announcement domain.Announcement) error {
+	return s.scheduler.Cron(tag(announcement.ID), announcement.Cron, announcement.Timezone, func() {
+		s.mu.RLock()
+		runner := s.runner
+		s.mu.RUnlock()
+
+		if runner == nil {
+			slog.Warn("[announcements] skipping announcement, nothing is running them yet", "id", announcement.ID)
+			return
+		}
+		runner(announcement)
+	})
+}
+
+func tag(id int) string {
+	return fmt.Sprintf("announcement:%d", id)
+}
+
+func toAnnouncement(a db.Announcement) domain.Announcement {
+	return domain.Announcement{
+		ID:        a.ID,
+		ChannelID: a.ChannelID,
+		Cron:      a.Cron,
+		Timezone:  a.Timezone,
+		Message:   a.Message,
+		Condition: domain.StreamCondition(a.Condition),
+		Enabled:   a.Enabled == 1,
+		CreatedAt: a.CreatedAt,
+	}
+}
@@ -25,7 +25,11 @@ type Authmen interface {
 	Cookie(key, token string, duration time.Duration) *fiber.Cookie
 
 	// Twitch
+	// GetTwitchScopes are the scopes asked for when logging in to the dashboard, they only tell who the user is
 	GetTwitchScopes() []string
+	// GetBroadcasterScopes are the scopes asked for when a broadcaster connects their account, they let the bot act
+	// on their channel
+	GetBroadcasterScopes() []string
 	TwitchExchange(ctx context.Context, code string) (*oauth2.Token, error)
 }
 
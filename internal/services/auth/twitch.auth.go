@@ -10,13 +10,14 @@ import (
 )
 
 type TwitchAuth struct {
-	StateCallbackKey string
-	OauthSessionName string
-	OauthTokenKey    string
-	Scopes           []string
-	Claims           oauth2.AuthCodeOption
-	Config           *oauth2.Config
-	OidcVerifier     *oidc.IDTokenVerifier
+	StateCallbackKey  string
+	OauthSessionName  string
+	OauthTokenKey     string
+	Scopes            []string
+	BroadcasterScopes []string
+	Claims            oauth2.AuthCodeOption
+	Config            *oauth2.Config
+	OidcVerifier      *oidc.IDTokenVerifier
 }
 
 // initTwitchProvider initializes the Twitch OAuth2 provider.
@@ -31,6 +32,22 @@ func (a *authmen) initTwtchProvider(cfg *config.Config) {
 		"openid",
 	}
 
+	broadcasterScopes := []string{
+		// Lets the bot send Twitch shoutouts to raiders
+		"moderator:manage:shoutouts",
+		// Lets the bot subscribe to the follows, subs, cheers, bans, redemptions, hype trains and ads of the broadcaster
+		"moderator:read:followers",
+		"channel:read:subscriptions",
+		"bits:read",
+		"channel:moderate",
+		"channel:read:redemptions",
+		"channel:read:hype_train",
+		"channel:read:ads",
+		// Lets the bot manage channel point rewards, complete redemptions and time out redeemers who asked for it
+		"channel:manage:redemptions",
+		"moderator:manage:banned_users",
+	}
+
 	TwitchOauth2Config := &oauth2.Config{
 		ClientID:     cfg.Twitch.Helix.ClientID,
 		ClientSecret: cfg.Twitch.Helix.ClientSecret,
@@ -42,13 +59,14 @@ func (a *authmen) initTwtchProvider(cfg *config.Config) {
 	TwitchOidcVerifier := provider.Verifier(&oidc.Config{ClientID: cfg.Twitch.Helix.ClientID})
 
 	a.Twitch = TwitchAuth{
-		StateCallbackKey: "oauth-state-callback",
-		OauthSessionName: CookieCSRF,
-		OauthTokenKey:    "oauth-token",
-		Scopes:           scopes,
-		Claims:           oauth2.SetAuthURLParam("claims", `{"id_token":{"email":null}}`),
-		Config:           TwitchOauth2Config,
-		OidcVerifier:     TwitchOidcVerifier,
+		StateCallbackKey:  "oauth-state-callback",
+		OauthSessionName:  CookieCSRF,
+		OauthTokenKey:     "oauth-token",
+		Scopes:            scopes,
+		BroadcasterScopes: broadcasterScopes,
+		Claims:            oauth2.SetAuthURLParam("claims", `{"id_token":{"email":null}}`),
+		Config:            TwitchOauth2Config,
+		OidcVerifier:      TwitchOidcVerifier,
 	}
 }
 
@@ -56,6 +74,10 @@ func (a *authmen) GetTwitchScopes() []string {
 	return a.Twitch.Scopes
 }
 
+func (a *authmen) GetBroadcasterScopes() []string {
+	return a.Twitch.BroadcasterScopes
+}
+
 // TwitchExchange exchanges an authorization code into a token
 func (a *authmen) TwitchExchange(ctx context.Context, code string) (*oauth2.Token, error) {
 	return a.Twitch.Config.Exchange(ctx, code)
@@ -0,0 +1,29 @@
+package cooldowns
+
+import "github.com/gempir/go-twitch-irc/v4"
+
+// Bucket is the badge tier a global cooldown is kept for, chatters only share a global cooldown with their own tier
+type Bucket string
+
+const (
+	BucketEveryone   Bucket = ""
+	BucketSubscriber Bucket = "subscriber"
+	BucketVIP        Bucket = "vip"
+	// BucketNotice keeps a user from being told more than once that a command is on cooldown
+	BucketNotice Bucket = "notice"
+)
+
+// BucketFor returns the highest badge tier of a user
+func BucketFor(user twitch.User) Bucket {
+	if _, ok := user.Badges["vip"]; ok {
+		return BucketVIP
+	}
+
+	for _, badge := range []string{"subscriber", "founder"} {
+		if _, ok := user.Badges[badge]; ok {
+			return BucketSubscriber
+		}
+	}
+
+	return BucketEveryone
+}
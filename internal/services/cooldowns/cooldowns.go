@@ -0,0 +1,33 @@
+package cooldowns
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/esfands/retpaladinbot/internal/db"
+)
+
+// sweepInterval is how often expired cooldowns are cleaned up
+const sweepInterval = 10 * time.Minute
+
+const (
+	BackendMemory   = "memory"
+	BackendDatabase = "database"
+)
+
+type SetupOptions struct {
+	// Backend is either memory or database, it defaults to memory
+	Backend string
+	Queries *db.Queries
+}
+
+func Setup(opts SetupOptions) (Store, error) {
+	switch opts.Backend {
+	case "", BackendMemory:
+		return NewMemoryStore(), nil
+	case BackendDatabase:
+		return NewDatabaseStore(opts.Queries), nil
+	default:
+		return nil, fmt.Errorf("unknown cooldown store backend %q", opts.Backend)
+	}
+}
@@ -0,0 +1,91 @@
+package cooldowns
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/esfands/retpaladinbot/internal/db"
+)
+
+// databaseStore keeps cooldowns in the database so they survive restarts and are shared between instances
+type databaseStore struct {
+	queries *db.Queries
+
+	mu        sync.Mutex
+	lastSweep time.Time
+}
+
+func NewDatabaseStore(queries *db.Queries) Store {
+	return &databaseStore{
+		queries:   queries,
+		lastSweep: time.Now(),
+	}
+}
+
+func (s *databaseStore) Acquire(ctx context.Context, key Key, duration time.Duration) (time.Duration, error) {
+	now := time.Now()
+	s.sweep(ctx, now)
+
+	if duration <= 0 {
+		return s.Remaining(ctx, key)
+	}
+
+	acquired, err := s.queries.AcquireCooldown(ctx, toDB(key, now.Add(duration)), now.UnixMilli())
+	if err != nil {
+		return 0, err
+	}
+	if acquired {
+		return 0, nil
+	}
+
+	return s.Remaining(ctx, key)
+}
+
+func (s *databaseStore) Remaining(ctx context.Context, key Key) (time.Duration, error) {
+	expiresAt, err := s.queries.GetCooldownExpiry(ctx, toDB(key, time.Time{}))
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+
+	remaining := time.Until(time.UnixMilli(expiresAt))
+	if remaining < 0 {
+		return 0, nil
+	}
+	return remaining, nil
+}
+
+func (s *databaseStore) Reset(ctx context.Context, filter Filter) (int, error) {
+	count, err := s.queries.DeleteCooldowns(ctx, filter.ChannelID, filter.Command, filter.User, time.Now().UnixMilli())
+	return int(count), err
+}
+
+// sweep removes expired cooldowns from the database every once in a while
+func (s *databaseStore) sweep(ctx context.Context, now time.Time) {
+	s.mu.Lock()
+	if now.Sub(s.lastSweep) < sweepInterval {
+		s.mu.Unlock()
+		return
+	}
+	s.lastSweep = now
+	s.mu.Unlock()
+
+	if err := s.queries.DeleteExpiredCooldowns(ctx, now.UnixMilli()); err != nil {
+		slog.Error("[cooldowns] failed to delete expired cooldowns", "error", err.Error())
+	}
+}
+
+func toDB(key Key, expiresAt time.Time) db.Cooldown {
+	return db.Cooldown{
+		ChannelID: key.ChannelID,
+		Command:   key.Command,
+		User:      key.User,
+		Bucket:    string(key.Bucket),
+		ExpiresAt: expiresAt.UnixMilli(),
+	}
+}
@@ -0,0 +1,37 @@
+package cooldowns
+
+import (
+	"context"
+	"time"
+)
+
+// Key identifies a single cooldown. Global cooldowns of a command have no user, a bucket splits them by badge tier.
+type Key struct {
+	ChannelID string
+	Command   string
+	User      string
+	Bucket    Bucket
+}
+
+// Filter selects the cooldowns to reset, empty fields match everything
+type Filter struct {
+	ChannelID string
+	Command   string
+	User      string
+}
+
+func (f Filter) matches(key Key) bool {
+	return (f.ChannelID == "" || f.ChannelID == key.ChannelID) &&
+		(f.Command == "" || f.Command == key.Command) &&
+		(f.User == "" || f.User == key.User)
+}
+
+type Store interface {
+	// Acquire starts the cooldown unless it's already running, it returns the time left on the running cooldown
+	// or 0 when the cooldown was started
+	Acquire(ctx context.Context, key Key, duration time.Duration) (time.Duration, error)
+	// Remaining returns the time left on a cooldown, 0 if it isn't running
+	Remaining(ctx context.Context, key Key) (time.Duration, error)
+	// Reset stops every cooldown matching the filter and returns how many were running
+	Reset(ctx context.Context, filter Filter) (int, error)
+}
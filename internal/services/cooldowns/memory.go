@@ -0,0 +1,84 @@
+package cooldowns
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// memoryStore keeps cooldowns in memory, they are lost on restart and not shared between instances
+type memoryStore struct {
+	mu        sync.Mutex
+	cooldowns map[Key]time.Time
+	lastSweep time.Time
+}
+
+func NewMemoryStore() Store {
+	return &memoryStore{
+		cooldowns: make(map[Key]time.Time),
+		lastSweep: time.Now(),
+	}
+}
+
+func (s *memoryStore) Acquire(_ context.Context, key Key, duration time.Duration) (time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.sweep(now)
+
+	if expiresAt, ok := s.cooldowns[key]; ok && now.Before(expiresAt) {
+		return expiresAt.Sub(now), nil
+	}
+
+	if duration > 0 {
+		s.cooldowns[key] = now.Add(duration)
+	}
+
+	return 0, nil
+}
+
+func (s *memoryStore) Remaining(_ context.Context, key Key) (time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if expiresAt, ok := s.cooldowns[key]; ok && now.Before(expiresAt) {
+		return expiresAt.Sub(now), nil
+	}
+
+	return 0, nil
+}
+
+func (s *memoryStore) Reset(_ context.Context, filter Filter) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+
+	count := 0
+	for key, expiresAt := range s.cooldowns {
+		if filter.matches(key) {
+			if now.Before(expiresAt) {
+				count++
+			}
+			delete(s.cooldowns, key)
+		}
+	}
+
+	return count, nil
+}
+
+// sweep removes expired cooldowns every once in a while so users who never come back don't stay in memory
+func (s *memoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+
+	for key, expiresAt := range s.cooldowns {
+		if !now.Before(expiresAt) {
+			delete(s.cooldowns, key)
+		}
+	}
+}
@@ -1,15 +1,31 @@
 package services
 
 import (
+	"github.com/esfands/retpaladinbot/internal/services/announcements"
 	"github.com/esfands/retpaladinbot/internal/services/auth"
+	"github.com/esfands/retpaladinbot/internal/services/cooldowns"
+	"github.com/esfands/retpaladinbot/internal/services/events"
+	"github.com/esfands/retpaladinbot/internal/services/eventsub"
 	"github.com/esfands/retpaladinbot/internal/services/helix"
+	"github.com/esfands/retpaladinbot/internal/services/modulestore"
+	"github.com/esfands/retpaladinbot/internal/services/rewards"
 	"github.com/esfands/retpaladinbot/internal/services/scheduler"
+	"github.com/esfands/retpaladinbot/internal/services/timers"
 	"github.com/esfands/retpaladinbot/internal/services/turso"
+	"github.com/esfands/retpaladinbot/internal/services/usernotices"
 )
 
 type Crate struct {
-	Turso     turso.Service
-	Helix     helix.Service
-	Scheduler scheduler.Service
-	Auth      auth.Authmen
+	Turso         turso.Service
+	Helix         helix.Service
+	Scheduler     scheduler.Service
+	Auth          auth.Authmen
+	Cooldowns     cooldowns.Store
+	Events        events.Service
+	EventSub      eventsub.Service
+	Modules       modulestore.Store
+	Announcements announcements.Service
+	Timers        timers.Store
+	UserNotices   usernotices.Store
+	Rewards       rewards.Service
 }
@@ -0,0 +1,122 @@
+package events
+
+import (
+	"log/slog"
+	"runtime/debug"
+	"sync"
+)
+
+// QueueSize is how many events a subscriber can fall behind by, events published to a full queue are dropped
+const QueueSize = 256
+
+// Topic is a kind of event along with the type of its payload, events are published and subscribed to by topic
+type Topic[T any] struct {
+	Name string
+}
+
+// Service is the bus the API, the bot and the modules talk to each other through. Every subscriber has a queue of
+// its own, so a slow subscriber only holds up itself.
+type Service interface {
+	// Close stops taking events, the subscribers still handle the events which were already queued
+	Close()
+
+	publish(topic string, payload any)
+	subscribe(topic, name string, handler func(payload any))
+}
+
+// Publish queues an event for every subscriber of the topic without waiting for them
+func Publish[T any](s Service, topic Topic[T], payload T) {
+	s.publish(topic.Name, payload)
+}
+
+// Subscribe calls the handler with the events of the topic published from now on, one at a time and in order.
+// The name of the subscriber shows up in the logs when it panics or falls behind.
+func Subscribe[T any](s Service, topic Topic[T], name string, handler func(payload T)) {
+	s.subscribe(topic.Name, name, func(payload any) {
+		handler(payload.(T))
+	})
+}
+
+type subscriber struct {
+	name    string
+	handler func(payload any)
+	queue   chan any
+}
+
+type eventsService struct {
+	mu          sync.RWMutex
+	closed      bool
+	subscribers map[string][]*subscriber
+}
+
+func New() Service {
+	return &eventsService{
+		subscribers: make(map[string][]*subscriber),
+	}
+}
+
+func (s *eventsService) publish(topic string, payload any) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.closed {
+		return
+	}
+
+	for _, sub := range s.subscribers[topic] {
+		select {
+		case sub.queue <- payload:
+		default:
+			slog.Warn("[events] dropping an event, the subscriber is falling behind", "topic", topic, "subscriber", sub.name)
+		}
+	}
+}
+
+func (s *eventsService) subscribe(topic, name string, handler func(payload any)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return
+	}
+
+	sub := &subscriber{
+		name:    name,
+		handler: handler,
+		queue:   make(chan any, QueueSize),
+	}
+	s.subscribers[topic] = append(s.subscribers[topic], sub)
+
+	go func() {
+		for payload := range sub.queue {
+			run(topic, sub, payload)
+		}
+	}()
+}
+
+func (s *eventsService) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return
+	}
+	s.closed = true
+
+	for _, subs := range s.subscribers {
+		for _, sub := range subs {
+			close(sub.queue)
+		}
+	}
+}
+
+// run calls a handler, a panicking handler is logged instead of taking the app down
+func run(topic string, sub *subscriber, payload any) {
+	defer func() {
+		if r := recover(); r != nil {
+			slog.Error("[events] handler panicked", "topic", topic, "subscriber", sub.name, "panic", r, "stack", string(debug.Stack()))
+		}
+	}()
+
+	sub.handler(payload)
+}
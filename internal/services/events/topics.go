@@ -0,0 +1,85 @@
+package events
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/gempir/go-twitch-irc/v4"
+)
+
+var (
+	// EventSub carries every EventSub notification the API receives
+	EventSub = Topic[Event]{Name: "eventsub"}
+	// StreamOnline is published once the API stored a stream which went live
+	StreamOnline = Topic[StreamOnlineEvent]{Name: "stream.online"}
+	// StreamOffline is published once the API stored a stream which ended
+	StreamOffline = Topic[StreamOfflineEvent]{Name: "stream.offline"}
+	// ChannelUpdate is published when the title or category of a channel changed
+	ChannelUpdate = Topic[ChannelUpdateEvent]{Name: "channel.update"}
+	// ChatMessage carries the chat messages of the joined channels
+	ChatMessage = Topic[ChatMessageEvent]{Name: "chat.message"}
+	// CommandExecuted is published for every command which was used, whether it ran, failed or was blocked
+	CommandExecuted = Topic[CommandExecutedEvent]{Name: "command.executed"}
+	// UserNotice carries the subs, raids and other USERNOTICEs of the joined channels
+	UserNotice = Topic[UserNoticeEvent]{Name: "chat.usernotice"}
+	// ChannelChanged is published once the settings of a channel were changed through the API
+	ChannelChanged = Topic[ChannelChangedEvent]{Name: "channel.changed"}
+)
+
+// Event is an EventSub notification which was received by the API
+type Event struct {
+	// Type is the subscription type, e.g. stream.online
+	Type string
+	// ChannelID is the broadcaster the event belongs to
+	ChannelID string
+	// Payload is the event object of the notification
+	Payload json.RawMessage
+}
+
+type StreamOnlineEvent struct {
+	ChannelID string
+	StreamID  string
+	Title     string
+	GameName  string
+	StartedAt time.Time
+}
+
+type StreamOfflineEvent struct {
+	ChannelID string
+	StreamID  string
+}
+
+type ChannelUpdateEvent struct {
+	ChannelID    string
+	Title        string
+	CategoryID   string
+	CategoryName string
+}
+
+type ChatMessageEvent struct {
+	ChannelID string
+	Message   twitch.PrivateMessage
+}
+
+type CommandExecutedEvent struct {
+	ChannelID string
+	// Command is the name of the command, Trigger is what the user typed to use it
+	Command string
+	Trigger string
+	User    twitch.User
+	// Custom is whether it's a custom command of the channel rather than a default command
+	Custom bool
+	// Blocked is why a middleware stopped the command before it ran, e.g. cooldown, it's empty when it ran
+	Blocked string
+	// Error is why the command failed, it's empty when it succeeded or was blocked
+	Error string
+}
+
+type UserNoticeEvent struct {
+	ChannelID string
+	Message   twitch.UserNoticeMessage
+}
+
+type ChannelChangedEvent struct {
+	ChannelID string
+}
@@ -0,0 +1,16 @@
+package eventsub
+
+import "github.com/nicklaw5/helix/v2"
+
+// AdBreakBeginEvent is the event of a channel.ad_break.begin notification
+type AdBreakBeginEvent struct {
+	DurationSeconds      int        `json:"duration_seconds"`
+	StartedAt            helix.Time `json:"started_at"`
+	IsAutomatic          bool       `json:"is_automatic"`
+	BroadcasterUserID    string     `json:"broadcaster_user_id"`
+	BroadcasterUserLogin string     `json:"broadcaster_user_login"`
+	BroadcasterUserName  string     `json:"broadcaster_user_name"`
+	RequesterUserID      string     `json:"requester_user_id"`
+	RequesterUserLogin   string     `json:"requester_user_login"`
+	RequesterUserName    string     `json:"requester_user_name"`
+}
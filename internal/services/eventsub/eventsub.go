@@ -0,0 +1,415 @@
+package eventsub
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/esfands/retpaladinbot/internal/db"
+	"github.com/esfands/retpaladinbot/internal/services/events"
+	helixservice "github.com/esfands/retpaladinbot/internal/services/helix"
+	"github.com/nicklaw5/helix/v2"
+)
+
+// Type is an EventSub subscription type the app subscribes to for every channel
+type Type struct {
+	Name    string
+	Version string
+	// Scope is the scope the broadcaster has to grant the app for the subscription, empty when it needs none
+	Scope string
+	// condition returns the condition of the subscription for a channel
+	condition func(channelID string) helix.EventSubCondition
+}
+
+func broadcasterCondition(channelID string) helix.EventSubCondition {
+	return helix.EventSubCondition{BroadcasterUserID: channelID}
+}
+
+// Types are the subscriptions every channel should have
+var Types = []Type{
+	{Name: helix.EventSubTypeStreamOnline, Version: "1", condition: broadcasterCondition},
+	{Name: helix.EventSubTypeStreamOffline, Version: "1", condition: broadcasterCondition},
+	{Name: helix.EventSubTypeChannelUpdate, Version: "2", condition: broadcasterCondition},
+	{Name: helix.EventSubTypeChannelRaid, Version: "1", condition: func(channelID string) helix.EventSubCondition {
+		return helix.EventSubCondition{ToBroadcasterUserID: channelID}
+	}},
+	// The broadcaster moderates their own channel, so they're the moderator follows are read as. Channels whose
+	// broadcaster didn't grant moderator:read:followers aren't subscribed to, Twitch would reject it.
+	{Name: helix.EventSubTypeChannelFollow, Version: "2", Scope: "moderator:read:followers", condition: func(channelID string) helix.EventSubCondition {
+		return helix.EventSubCondition{BroadcasterUserID: channelID, ModeratorUserID: channelID}
+	}},
+	{Name: helix.EventSubTypeChannelSubscription, Version: "1", Scope: "channel:read:subscriptions", condition: broadcasterCondition},
+	{Name: helix.EventSubTypeChannelSubscriptionGift, Version: "1", Scope: "channel:read:subscriptions", condition: broadcasterCondition},
+	{Name: helix.EventSubTypeChannelCheer, Version: "1", Scope: "bits:read", condition: broadcasterCondition},
+	{Name: helix.EventSubTypeChannelBan, Version: "1", Scope: "channel:moderate", condition: broadcasterCondition},
+	{Name: helix.EventSubTypeChannelPointsCustomRewardRedemptionAdd, Version: "1", Scope: "channel:read:redemptions", condition: broadcasterCondition},
+	{Name: helix.EventSubTypeHypeTrainBegin, Version: "1", Scope: "channel:read:hype_train", condition: broadcasterCondition},
+	{Name: helix.EventSubTypeHypeTrainProgress, Version: "1", Scope: "channel:read:hype_train", condition: broadcasterCondition},
+	{Name: helix.EventSubTypeHypeTrainEnd, Version: "1", Scope: "channel:read:hype_train", condition: broadcasterCondition},
+	{Name: TypeAdBreakBegin, Version: "1", Scope: "channel:read:ads", condition: broadcasterCondition},
+}
+
+// TypeAdBreakBegin is sent when an ad break starts, helix doesn't know it yet
+const TypeAdBreakBegin = "channel.ad_break.begin"
+
+// GetType returns a subscription type the app knows by name
+func GetType(name string) (Type, bool) {
+	for _, t := range Types {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return Type{}, false
+}
+
+// The transports notifications can be received with
+const (
+	TransportWebhook   = "webhook"
+	TransportWebSocket = "websocket"
+)
+
+var (
+	// ErrNoCallback is returned when subscriptions are created without a callback URL in the config
+	ErrNoCallback = errors.New("no EventSub callback URL is configured")
+	// ErrNoSession is returned when subscriptions are created before the WebSocket was welcomed
+	ErrNoSession = errors.New("the EventSub WebSocket isn't connected")
+)
+
+// ReconcileResult is what reconciling changed
+type ReconcileResult struct {
+	// Created are the types and channels of the subscriptions which were missing
+	Created []string `json:"created"`
+	// Deleted are the IDs of the subscriptions which weren't needed or had failed
+	Deleted []string `json:"deleted"`
+	Kept    int      `json:"kept"`
+}
+
+type Service interface {
+	// List returns every subscription of the app
+	List() ([]helix.EventSubSubscription, error)
+	// Subscribe creates a subscription of a type for a channel
+	Subscribe(subscriptionType, channelID string) (helix.EventSubSubscription, error)
+	// Unsubscribe deletes a subscription
+	Unsubscribe(id string) error
+	// Reconcile creates the missing subscriptions of the enabled channels and deletes the ones which aren't needed,
+	// subscriptions sent to other callbacks or WebSocket sessions are left alone
+	Reconcile(ctx context.Context) (ReconcileResult, error)
+	// Notify handles a notification which was received with either transport
+	Notify(ctx context.Context, messageID string, subscription helix.EventSubSubscription, payload json.RawMessage) error
+	// Connect receives the notifications through the EventSub WebSocket until the context is done, the subscriptions
+	// are created whenever a new session starts
+	Connect(ctx context.Context) error
+	// Seen records the ID of a received notification and reports whether it was received before
+	Seen(messageID string) bool
+	// Revoked records a subscription Twitch revoked and tries to subscribe to it again
+	Revoked(ctx context.Context, subscription helix.EventSubSubscription)
+}
+
+type SetupOptions struct {
+	Helix   helixservice.Service
+	Queries *db.Queries
+	Events  events.Service
+	// Transport is how notifications are received, webhook unless it's websocket
+	Transport string
+	// DefaultChannelID is the channel from the config, it's subscribed to even before the bot stores it
+	DefaultChannelID string
+	// Callback is the URL Twitch sends the notifications to, it has to be https
+	Callback string
+	Secret   string
+	// WebSocketURL is the EventSub WebSocket to connect to, it's the one of Twitch unless it's set
+	WebSocketURL string
+}
+
+type eventSubService struct {
+	opts     SetupOptions
+	messages *messageIDs
+
+	mu sync.RWMutex
+	// sessionID is the session of the WebSocket connection which is welcomed, subscriptions are sent to it
+	sessionID string
+}
+
+func Setup(opts SetupOptions) Service {
+	if opts.Transport == "" {
+		opts.Transport = TransportWebhook
+	}
+	if opts.WebSocketURL == "" {
+		opts.WebSocketURL = DefaultWebSocketURL
+	}
+
+	return &eventSubService{
+		opts:     opts,
+		messages: newMessageIDs(),
+	}
+}
+
+func (s *eventSubService) Seen(messageID string) bool {
+	return s.messages.remember(messageID, time.Now())
+}
+
+func (s *eventSubService) List() ([]helix.EventSubSubscription, error) {
+	var subscriptions []helix.EventSubSubscription
+
+	client, err := s.client(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	params := &helix.EventSubSubscriptionsParams{}
+	for {
+		res, err := client.GetEventSubSubscriptions(params)
+		if err != nil {
+			return nil, err
+		}
+		if err := responseError(res.ResponseCommon); err != nil {
+			return nil, err
+		}
+
+		subscriptions = append(subscriptions, res.Data.EventSubSubscriptions...)
+
+		if res.Data.Pagination.Cursor == "" {
+			return subscriptions, nil
+		}
+		params.After = res.Data.Pagination.Cursor
+	}
+}
+
+func (s *eventSubService) Subscribe(subscriptionType, channelID string) (helix.EventSubSubscription, error) {
+	t, ok := GetType(subscriptionType)
+	if !ok {
+		return helix.EventSubSubscription{}, fmt.Errorf("unknown subscription type %q", subscriptionType)
+	}
+	transport, err := s.transport()
+	if err != nil {
+		return helix.EventSubSubscription{}, err
+	}
+	client, err := s.client(context.Background())
+	if err != nil {
+		return helix.EventSubSubscription{}, err
+	}
+
+	res, err := client.CreateEventSubSubscription(&helix.EventSubSubscription{
+		Type:      t.Name,
+		Version:   t.Version,
+		Condition: t.condition(channelID),
+		Transport: transport,
+	})
+	if err != nil {
+		return helix.EventSubSubscription{}, err
+	}
+	if err := responseError(res.ResponseCommon); err != nil {
+		return helix.EventSubSubscription{}, err
+	}
+	if len(res.Data.EventSubSubscriptions) == 0 {
+		return helix.EventSubSubscription{}, errors.New("twitch didn't return the subscription")
+	}
+
+	return res.Data.EventSubSubscriptions[0], nil
+}
+
+func (s *eventSubService) Unsubscribe(id string) error {
+	client, err := s.client(context.Background())
+	if err != nil {
+		return err
+	}
+
+	res, err := client.RemoveEventSubSubscription(id)
+	if err != nil {
+		return err
+	}
+	return responseError(res.ResponseCommon)
+}
+
+func (s *eventSubService) Reconcile(ctx context.Context) (ReconcileResult, error) {
+	result := ReconcileResult{Created: []string{}, Deleted: []string{}}
+	transport, err := s.transport()
+	if err != nil {
+		return result, err
+	}
+
+	channelIDs, err := s.channelIDs(ctx)
+	if err != nil {
+		return result, err
+	}
+
+	// wanted holds the subscriptions every enabled channel should have, the ones which exist are taken out
+	wanted := make(map[string]bool)
+	types := make(map[string][]Type, len(channelIDs))
+	for _, channelID := range channelIDs {
+		types[channelID] = s.authorized(ctx, channelID)
+		for _, t := range types[channelID] {
+			wanted[key(t.Name, t.Version, t.condition(channelID))] = true
+		}
+	}
+
+	subscriptions, err := s.List()
+	if err != nil {
+		return result, err
+	}
+
+	for _, sub := range subscriptions {
+		if !sameTransport(sub.Transport, transport) {
+			continue
+		}
+
+		k := key(sub.Type, sub.Version, sub.Condition)
+		// Subscriptions which failed or were revoked never receive anything again, they're created anew
+		healthy := sub.Status == helix.EventSubStatusEnabled || sub.Status == helix.EventSubStatusPending
+		if wanted[k] && healthy {
+			delete(wanted, k)
+			result.Kept++
+			continue
+		}
+
+		if err := s.Unsubscribe(sub.ID); err != nil {
+			slog.Error("[eventsub] couldn't delete a stale subscription", "id", sub.ID, "type", sub.Type, "error", err.Error())
+			continue
+		}
+		result.Deleted = append(result.Deleted, sub.ID)
+	}
+
+	for _, channelID := range channelIDs {
+		for _, t := range types[channelID] {
+			if !wanted[key(t.Name, t.Version, t.condition(channelID))] {
+				continue
+			}
+
+			if _, err := s.Subscribe(t.Name, channelID); err != nil {
+				slog.Error("[eventsub] couldn't create a subscription", "type", t.Name, "channel", channelID, "error", err.Error())
+				continue
+			}
+			result.Created = append(result.Created, fmt.Sprintf("%v:%v", t.Name, channelID))
+		}
+	}
+
+	return result, nil
+}
+
+// transport returns where the subscriptions send their notifications
+func (s *eventSubService) transport() (helix.EventSubTransport, error) {
+	if s.opts.Transport == TransportWebSocket {
+		sessionID := s.session()
+		if sessionID == "" {
+			return helix.EventSubTransport{}, ErrNoSession
+		}
+		return helix.EventSubTransport{Method: TransportWebSocket, SessionID: sessionID}, nil
+	}
+
+	if s.opts.Callback == "" {
+		return helix.EventSubTransport{}, ErrNoCallback
+	}
+	return helix.EventSubTransport{Method: TransportWebhook, Callback: s.opts.Callback, Secret: s.opts.Secret}, nil
+}
+
+// client returns the Helix client the subscriptions are managed with, webhooks need an app token and WebSockets a
+// user token. The WebSocket uses the token the broadcaster of the default channel connected, never the one of
+// whoever logged in to the dashboard.
+func (s *eventSubService) client(ctx context.Context) (*helix.Client, error) {
+	if s.opts.Transport != TransportWebSocket {
+		return s.opts.Helix.AppClient(), nil
+	}
+
+	client, err := s.opts.Helix.BroadcasterClient(ctx, s.opts.DefaultChannelID, "")
+	if err != nil {
+		return nil, fmt.Errorf("the websocket needs the token of the broadcaster of the default channel: %w", err)
+	}
+	return client, nil
+}
+
+// authorized returns the types the app may subscribe to for a channel, the types which need a scope are skipped
+// unless the broadcaster of the channel granted it. The WebSocket subscribes with the token of the broadcaster of the
+// default channel, so for other channels it only gets the types which need no scope.
+func (s *eventSubService) authorized(ctx context.Context, channelID string) []Type {
+	var scopes []string
+	if s.opts.Transport != TransportWebSocket || channelID == s.opts.DefaultChannelID {
+		var err error
+		scopes, err = s.opts.Helix.Scopes(ctx, channelID)
+		if err != nil && !errors.Is(err, helixservice.ErrNotConnected) {
+			slog.Error("[eventsub] couldn't get the scopes of the broadcaster", "channel", channelID, "error", err.Error())
+		}
+	}
+
+	var subscribed, skipped []string
+	var authorized []Type
+	for _, t := range Types {
+		if t.Scope == "" || slices.Contains(scopes, t.Scope) {
+			authorized = append(authorized, t)
+			subscribed = append(subscribed, t.Name)
+		} else {
+			skipped = append(skipped, t.Name)
+		}
+	}
+
+	if len(skipped) > 0 {
+		slog.Warn("[eventsub] the broadcaster didn't authorize some subscriptions of the channel, they're skipped until they connect their account",
+			"channel", channelID, "transport", s.opts.Transport, "skipped", skipped, "subscribed", subscribed)
+	}
+
+	return authorized
+}
+
+func (s *eventSubService) session() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.sessionID
+}
+
+func (s *eventSubService) setSession(sessionID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sessionID = sessionID
+}
+
+// sameTransport reports whether a subscription sends its notifications to the transport
+func sameTransport(subscription, transport helix.EventSubTransport) bool {
+	if subscription.Method != transport.Method {
+		return false
+	}
+	if transport.Method == TransportWebSocket {
+		return subscription.SessionID == transport.SessionID
+	}
+	return subscription.Callback == transport.Callback
+}
+
+// channelIDs returns the IDs of the enabled channels
+func (s *eventSubService) channelIDs(ctx context.Context) ([]string, error) {
+	channels, err := s.opts.Queries.GetAllChannels(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	if s.opts.DefaultChannelID != "" {
+		ids = append(ids, s.opts.DefaultChannelID)
+	}
+	for _, channel := range channels {
+		if channel.Enabled == 1 && channel.ID != s.opts.DefaultChannelID {
+			ids = append(ids, channel.ID)
+		}
+	}
+	return ids, nil
+}
+
+// key identifies a subscription by its type, version and the users of its condition
+func key(subscriptionType, version string, condition helix.EventSubCondition) string {
+	return fmt.Sprintf(
+		"%v:%v:%v:%v:%v",
+		subscriptionType, version, condition.BroadcasterUserID, condition.ToBroadcasterUserID, condition.ModeratorUserID,
+	)
+}
+
+// responseError turns an error response of Helix into an error
+func responseError(res helix.ResponseCommon) error {
+	if res.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("twitch responded with %d: %v", res.StatusCode, res.ErrorMessage)
+	}
+	return nil
+}
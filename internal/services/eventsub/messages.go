@@ -0,0 +1,65 @@
+package eventsub
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// The types of the messages Twitch sends to the webhook, in the Twitch-Eventsub-Message-Type header
+const (
+	MessageNotification = "notification"
+	MessageVerification = "webhook_callback_verification"
+	MessageRevocation   = "revocation"
+)
+
+// MaxMessageAge is how old a notification can be before it's rejected, message IDs are remembered as long so a
+// replayed message is either a duplicate or too old
+const MaxMessageAge = 10 * time.Minute
+
+// messageIDs remembers the IDs of the received messages until they're too old to be accepted anyway
+type messageIDs struct {
+	mu        sync.Mutex
+	seen      map[string]time.Time
+	lastSweep time.Time
+}
+
+func newMessageIDs() *messageIDs {
+	return &messageIDs{
+		seen:      make(map[string]time.Time),
+		lastSweep: time.Now(),
+	}
+}
+
+// remember records a message ID and reports whether it was received before
+func (m *messageIDs) remember(id string, now time.Time) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if now.Sub(m.lastSweep) > time.Minute {
+		for seenID, expires := range m.seen {
+			if now.After(expires) {
+				delete(m.seen, seenID)
+			}
+		}
+		m.lastSweep = now
+	}
+
+	if expires, ok := m.seen[id]; ok && now.Before(expires) {
+		return true
+	}
+
+	m.seen[id] = now.Add(MaxMessageAge)
+	return false
+}
+
+// Signature returns the Twitch-Eventsub-Message-Signature of a webhook message, an HMAC of its ID, timestamp and body
+// keyed with the secret of the subscription
+func Signature(secret, messageID, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(messageID + timestamp))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
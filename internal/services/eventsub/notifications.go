@@ -0,0 +1,277 @@
+package eventsub
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/esfands/retpaladinbot/internal/db"
+	"github.com/esfands/retpaladinbot/internal/services/events"
+	"github.com/nicklaw5/helix/v2"
+)
+
+func (s *eventSubService) Notify(ctx context.Context, messageID string, subscription helix.EventSubSubscription, payload json.RawMessage) error {
+	switch subscription.Type {
+	case helix.EventSubTypeStreamOnline:
+		var event helix.EventSubStreamOnlineEvent
+		if err := json.Unmarshal(payload, &event); err != nil {
+			return fmt.Errorf("unmarshalling the stream.online event: %w", err)
+		}
+
+		s.streamOnline(ctx, event)
+
+	case helix.EventSubTypeStreamOffline:
+		var event helix.EventSubStreamOfflineEvent
+		if err := json.Unmarshal(payload, &event); err != nil {
+			return fmt.Errorf("unmarshalling the stream.offline event: %w", err)
+		}
+
+		s.streamOffline(ctx, event)
+
+	case helix.EventSubTypeChannelUpdate:
+		var event helix.EventSubChannelUpdateEvent
+		if err := json.Unmarshal(payload, &event); err != nil {
+			return fmt.Errorf("unmarshalling the channel.update event: %w", err)
+		}
+
+		s.channelUpdate(ctx, event)
+	}
+
+	// Raids are sent to the channel being raided
+	channelID := subscription.Condition.BroadcasterUserID
+	if channelID == "" {
+		channelID = subscription.Condition.ToBroadcasterUserID
+	}
+
+	// Follows, subs, cheers and the other channel events are kept in the events table
+	user, stored, err := decodeChannelEvent(subscription.Type, payload)
+	if err != nil {
+		return fmt.Errorf("unmarshalling the %v event: %w", subscription.Type, err)
+	}
+	if stored {
+		s.storeChannelEvent(ctx, messageID, channelID, subscription.Type, user, payload)
+	}
+
+	// Hand the event to the bot modules so chat can react to it
+	events.Publish(s.opts.Events, events.EventSub, events.Event{
+		Type:      subscription.Type,
+		ChannelID: channelID,
+		Payload:   payload,
+	})
+
+	return nil
+}
+
+func (s *eventSubService) streamOnline(ctx context.Context, event helix.EventSubStreamOnlineEvent) {
+	slog.Info("[eventsub] stream online", "channel", event.BroadcasterUserLogin, "stream", event.ID)
+
+	// A stream which is already stored was delivered twice
+	if liveStream, err := s.opts.Queries.GetLiveStream(ctx, event.BroadcasterUserID); err == nil && liveStream.StreamID == event.ID {
+		slog.Info("[eventsub] the stream is already stored", "stream", event.ID)
+		return
+	}
+
+	// Get the channel information from Helix
+	channelInfoRes, err := s.opts.Helix.Client().GetChannelInformation(&helix.GetChannelInformationParams{
+		BroadcasterIDs: []string{event.BroadcasterUserID},
+	})
+	if err != nil {
+		slog.Error("[eventsub] couldn't get the channel information", "error", err.Error())
+		return
+	}
+	if len(channelInfoRes.Data.Channels) == 0 {
+		slog.Error("[eventsub] twitch didn't return the channel information", "channel", event.BroadcasterUserID)
+		return
+	}
+
+	channelInfo := channelInfoRes.Data.Channels[0]
+
+	err = s.opts.Queries.InsertStream(ctx, db.StreamStatus{
+		ChannelID: event.BroadcasterUserID,
+		StreamID:  event.ID,
+		GameID:    sql.NullString{String: channelInfo.GameID, Valid: true},
+		GameName:  sql.NullString{String: channelInfo.GameName, Valid: true},
+		Live:      true,
+		Title:     sql.NullString{String: channelInfo.Title, Valid: true},
+		StartedAt: event.StartedAt.Format(time.RFC3339),
+		EndedAt:   sql.NullString{String: "", Valid: false},
+	})
+	if err != nil {
+		slog.Error("[eventsub] couldn't insert the stream", "error", err.Error())
+	}
+
+	events.Publish(s.opts.Events, events.StreamOnline, events.StreamOnlineEvent{
+		ChannelID: event.BroadcasterUserID,
+		StreamID:  event.ID,
+		Title:     channelInfo.Title,
+		GameName:  channelInfo.GameName,
+		StartedAt: event.StartedAt.Time,
+	})
+
+	// Counters like deaths can be set to start over every stream
+	reset, err := s.opts.Queries.ResetStreamCounters(ctx, event.BroadcasterUserID)
+	if err != nil {
+		slog.Error("[eventsub] couldn't reset the stream counters", "error", err.Error())
+		return
+	}
+	if reset > 0 {
+		slog.Info("[eventsub] reset stream counters", "channel", event.BroadcasterUserLogin, "counters", reset)
+	}
+}
+
+func (s *eventSubService) streamOffline(ctx context.Context, event helix.EventSubStreamOfflineEvent) {
+	slog.Info("[eventsub] stream offline", "channel", event.BroadcasterUserLogin)
+
+	// First get the stream from the database to get the ID of the current live stream
+	currentLiveStream, err := s.opts.Queries.GetLiveStream(ctx, event.BroadcasterUserID)
+	if err != nil {
+		slog.Error("[eventsub] couldn't get the currently live stream", "error", err.Error())
+		return
+	}
+
+	// Update the stream that went offline
+	if err := s.opts.Queries.StreamWentOffline(ctx, currentLiveStream.StreamID, sql.NullString{String: time.Now().Format(time.RFC3339), Valid: true}); err != nil {
+		slog.Error("[eventsub] couldn't update the stream that went offline", "error", err.Error())
+		return
+	}
+
+	events.Publish(s.opts.Events, events.StreamOffline, events.StreamOfflineEvent{
+		ChannelID: event.BroadcasterUserID,
+		StreamID:  currentLiveStream.StreamID,
+	})
+}
+
+func (s *eventSubService) channelUpdate(ctx context.Context, event helix.EventSubChannelUpdateEvent) {
+	slog.Info("[eventsub] channel updated", "channel", event.BroadcasterUserLogin, "title", event.Title, "category", event.CategoryName)
+	// Published before the stream is looked up, so the update isn't lost for channels which never streamed
+	events.Publish(s.opts.Events, events.ChannelUpdate, events.ChannelUpdateEvent{
+		ChannelID:    event.BroadcasterUserID,
+		Title:        event.Title,
+		CategoryID:   event.CategoryID,
+		CategoryName: event.CategoryName,
+	})
+
+	// First get the stream from the database to get the ID of the latest stream
+	recentStream, err := s.opts.Queries.GetMostRecentStreamStatus(ctx, event.BroadcasterUserID)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Channels which never streamed get an offline stream without a start to keep the title and category in
+		err = s.opts.Queries.InsertStream(ctx, db.StreamStatus{
+			ChannelID: event.BroadcasterUserID,
+			GameID:    sql.NullString{String: event.CategoryID, Valid: true},
+			GameName:  sql.NullString{String: event.CategoryName, Valid: true},
+			Live:      false,
+			Title:     sql.NullString{String: event.Title, Valid: true},
+		})
+		if err != nil {
+			slog.Error("[eventsub] couldn't insert the stream info", "error", err.Error())
+		}
+		return
+	} else if err != nil {
+		slog.Error("[eventsub] couldn't get the most recent stream status", "error", err.Error())
+		return
+	}
+
+	err = s.opts.Queries.UpdateStreamInfo(ctx, db.StreamStatus{
+		ID:        recentStream.ID,
+		ChannelID: recentStream.ChannelID,
+		StreamID:  recentStream.StreamID,
+		GameID:    sql.NullString{String: event.CategoryID, Valid: true},
+		GameName:  sql.NullString{String: event.CategoryName, Valid: true},
+		Live:      recentStream.Live,
+		Title:     sql.NullString{String: event.Title, Valid: true},
+		StartedAt: recentStream.StartedAt,
+		EndedAt:   recentStream.EndedAt,
+	})
+	if err != nil {
+		slog.Error("[eventsub] couldn't update the stream info", "error", err.Error())
+	}
+}
+
+// eventUser is who caused a channel event
+type eventUser struct {
+	id   string
+	name string
+}
+
+// decodeChannelEvent decodes the event of a notification which is stored in the events table and returns who caused
+// it, ok is false for the types which aren't stored
+func decodeChannelEvent(subscriptionType string, payload json.RawMessage) (user eventUser, ok bool, err error) {
+	switch subscriptionType {
+	case helix.EventSubTypeChannelFollow:
+		var event helix.EventSubChannelFollowEvent
+		err = json.Unmarshal(payload, &event)
+		user = eventUser{id: event.UserID, name: event.UserLogin}
+
+	case helix.EventSubTypeChannelSubscription:
+		var event helix.EventSubChannelSubscribeEvent
+		err = json.Unmarshal(payload, &event)
+		user = eventUser{id: event.UserID, name: event.UserLogin}
+
+	case helix.EventSubTypeChannelSubscriptionGift:
+		var event helix.EventSubChannelSubscriptionGiftEvent
+		err = json.Unmarshal(payload, &event)
+		user = eventUser{id: event.UserID, name: event.UserLogin}
+
+	case helix.EventSubTypeChannelCheer:
+		var event helix.EventSubChannelCheerEvent
+		err = json.Unmarshal(payload, &event)
+		user = eventUser{id: event.UserID, name: event.UserLogin}
+
+	case helix.EventSubTypeChannelRaid:
+		var event helix.EventSubChannelRaidEvent
+		err = json.Unmarshal(payload, &event)
+		user = eventUser{id: event.FromBroadcasterUserID, name: event.FromBroadcasterUserLogin}
+
+	case helix.EventSubTypeChannelBan:
+		var event helix.EventSubChannelBanEvent
+		err = json.Unmarshal(payload, &event)
+		user = eventUser{id: event.UserID, name: event.UserLogin}
+
+	case helix.EventSubTypeChannelPointsCustomRewardRedemptionAdd:
+		var event helix.EventSubChannelPointsCustomRewardRedemptionEvent
+		err = json.Unmarshal(payload, &event)
+		user = eventUser{id: event.UserID, name: event.UserLogin}
+
+	case helix.EventSubTypeHypeTrainBegin:
+		var event helix.EventSubHypeTrainBeginEvent
+		err = json.Unmarshal(payload, &event)
+
+	case helix.EventSubTypeHypeTrainProgress:
+		var event helix.EventSubHypeTrainProgressEvent
+		err = json.Unmarshal(payload, &event)
+
+	case helix.EventSubTypeHypeTrainEnd:
+		var event helix.EventSubHypeTrainEndEvent
+		err = json.Unmarshal(payload, &event)
+
+	case TypeAdBreakBegin:
+		var event AdBreakBeginEvent
+		err = json.Unmarshal(payload, &event)
+		user = eventUser{id: event.RequesterUserID, name: event.RequesterUserLogin}
+
+	default:
+		return eventUser{}, false, nil
+	}
+
+	return user, true, err
+}
+
+// storeChannelEvent stores an event of a channel along with who caused it
+func (s *eventSubService) storeChannelEvent(ctx context.Context, messageID, channelID, subscriptionType string, user eventUser, payload json.RawMessage) {
+	err := s.opts.Queries.InsertChannelEvent(ctx, db.ChannelEvent{
+		MessageID:  messageID,
+		ChannelID:  channelID,
+		Type:       subscriptionType,
+		UserID:     sql.NullString{String: user.id, Valid: user.id != ""},
+		UserName:   sql.NullString{String: user.name, Valid: user.name != ""},
+		Payload:    string(payload),
+		ReceivedAt: time.Now().Format(time.RFC3339),
+	})
+	if err != nil {
+		slog.Error("[eventsub] couldn't store the event", "type", subscriptionType, "channel", channelID, "error", err.Error())
+	}
+}
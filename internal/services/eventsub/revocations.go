@@ -0,0 +1,41 @@
+package eventsub
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"github.com/esfands/retpaladinbot/internal/db"
+	"github.com/esfands/retpaladinbot/pkg/utils"
+	"github.com/nicklaw5/helix/v2"
+)
+
+func (s *eventSubService) Revoked(ctx context.Context, subscription helix.EventSubSubscription) {
+	channelID := subscription.Condition.BroadcasterUserID
+	if channelID == "" {
+		channelID = subscription.Condition.ToBroadcasterUserID
+	}
+
+	revocation := db.EventSubRevocation{
+		SubscriptionID: subscription.ID,
+		Type:           subscription.Type,
+		ChannelID:      channelID,
+		Reason:         subscription.Status,
+		RevokedAt:      time.Now().Format(time.RFC3339),
+	}
+
+	// Types the app doesn't subscribe to on its own are only recorded
+	if _, ok := GetType(subscription.Type); ok && channelID != "" {
+		_, err := s.Subscribe(subscription.Type, channelID)
+		if err != nil {
+			slog.Error("[eventsub] couldn't resubscribe after a revocation", "type", subscription.Type, "channel", channelID, "reason", subscription.Status, "error", err.Error())
+			revocation.Error = sql.NullString{String: err.Error(), Valid: true}
+		}
+		revocation.Resubscribed = utils.BoolToInt(err == nil)
+	}
+
+	if err := s.opts.Queries.InsertEventSubRevocation(ctx, revocation); err != nil {
+		slog.Error("[eventsub] couldn't record the revocation", "id", subscription.ID, "error", err.Error())
+	}
+}
@@ -0,0 +1,273 @@
+package eventsub
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/nicklaw5/helix/v2"
+)
+
+// SimulateOptions describe a webhook message to simulate
+type SimulateOptions struct {
+	// Type is the subscription type, it has to be one of Types
+	Type string
+	// MessageType is notification, webhook_callback_verification or revocation
+	MessageType string
+	// Callback is the URL the message is sent to, it's also the callback of the simulated subscription
+	Callback string
+
+	BroadcasterID    string
+	BroadcasterLogin string
+	// UserID and UserLogin are who follows, subs, cheers, raids and so on
+	UserID    string
+	UserLogin string
+	// RewardID is the channel point reward which is redeemed, a random one unless it's set
+	RewardID string
+	// Input is what the redeemer typed for a reward which asks for it
+	Input string
+}
+
+// Simulation is a webhook message the way Twitch would send it
+type Simulation struct {
+	MessageID   string
+	MessageType string
+	Timestamp   string
+	// Challenge is what the callback has to respond with to a verification
+	Challenge string
+	Body      []byte
+	// subscription is the subscription the message belongs to
+	subscription helix.EventSubSubscription
+}
+
+// Simulate builds a webhook message of a subscription type with a realistic payload
+func Simulate(opts SimulateOptions) (Simulation, error) {
+	t, ok := GetType(opts.Type)
+	if !ok {
+		return Simulation{}, fmt.Errorf("unknown subscription type %q", opts.Type)
+	}
+	if opts.MessageType == "" {
+		opts.MessageType = MessageNotification
+	}
+
+	now := time.Now().UTC()
+	sim := Simulation{
+		MessageID:   randomID(),
+		MessageType: opts.MessageType,
+		Timestamp:   now.Format(time.RFC3339Nano),
+		subscription: helix.EventSubSubscription{
+			ID:        randomID(),
+			Type:      t.Name,
+			Version:   t.Version,
+			Status:    helix.EventSubStatusEnabled,
+			Condition: t.condition(opts.BroadcasterID),
+			Transport: helix.EventSubTransport{Method: TransportWebhook, Callback: opts.Callback},
+			CreatedAt: helix.Time{Time: now.Add(-time.Hour)},
+		},
+	}
+
+	body := map[string]any{}
+	switch opts.MessageType {
+	case MessageNotification:
+		body["event"] = simulatedEvent(t.Name, opts, now)
+	case MessageVerification:
+		sim.subscription.Status = helix.EventSubStatusPending
+		sim.Challenge = randomID()
+		body["challenge"] = sim.Challenge
+	case MessageRevocation:
+		sim.subscription.Status = helix.EventSubStatusAuthorizationRevoked
+	default:
+		return Simulation{}, fmt.Errorf("unknown message type %q", opts.MessageType)
+	}
+	body["subscription"] = sim.subscription
+
+	var err error
+	sim.Body, err = json.Marshal(body)
+	if err != nil {
+		return Simulation{}, err
+	}
+
+	return sim, nil
+}
+
+// Request returns the request which sends the message to a URL, signed with the secret
+func (s Simulation) Request(url, secret string) (*http.Request, error) {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(s.Body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Twitch-Eventsub-Message-Id", s.MessageID)
+	req.Header.Set("Twitch-Eventsub-Message-Retry", "0")
+	req.Header.Set("Twitch-Eventsub-Message-Type", s.MessageType)
+	req.Header.Set("Twitch-Eventsub-Message-Timestamp", s.Timestamp)
+	req.Header.Set("Twitch-Eventsub-Message-Signature", Signature(secret, s.MessageID, s.Timestamp, s.Body))
+	req.Header.Set("Twitch-Eventsub-Subscription-Type", s.subscription.Type)
+	req.Header.Set("Twitch-Eventsub-Subscription-Version", s.subscription.Version)
+
+	return req, nil
+}
+
+// simulatedEvent returns the event object of a notification of a subscription type
+func simulatedEvent(subscriptionType string, opts SimulateOptions, now time.Time) map[string]any {
+	broadcaster := user("broadcaster_user", opts.BroadcasterID, opts.BroadcasterLogin)
+	viewer := user("user", opts.UserID, opts.UserLogin)
+	timestamp := now.Format(time.RFC3339Nano)
+
+	contribution := map[string]any{
+		"user_id":    opts.UserID,
+		"user_login": opts.UserLogin,
+		"user_name":  opts.UserLogin,
+		"type":       "bits",
+		"total":      500,
+	}
+
+	switch subscriptionType {
+	case helix.EventSubTypeStreamOnline:
+		return with(broadcaster, map[string]any{
+			"id":         randomNumber(),
+			"type":       "live",
+			"started_at": timestamp,
+		})
+
+	case helix.EventSubTypeStreamOffline:
+		return broadcaster
+
+	case helix.EventSubTypeChannelUpdate:
+		return with(broadcaster, map[string]any{
+			"title":                         "Simulated stream title",
+			"language":                      "en",
+			"category_id":                   "509658",
+			"category_name":                 "Just Chatting",
+			"content_classification_labels": []string{},
+		})
+
+	case helix.EventSubTypeChannelRaid:
+		return with(
+			user("from_broadcaster_user", opts.UserID, opts.UserLogin),
+			user("to_broadcaster_user", opts.BroadcasterID, opts.BroadcasterLogin),
+			map[string]any{"viewers": 42},
+		)
+
+	case helix.EventSubTypeChannelFollow:
+		return with(broadcaster, viewer, map[string]any{"followed_at": timestamp})
+
+	case helix.EventSubTypeChannelSubscription:
+		return with(broadcaster, viewer, map[string]any{"tier": "1000", "is_gift": false})
+
+	case helix.EventSubTypeChannelSubscriptionGift:
+		return with(broadcaster, viewer, map[string]any{
+			"total":            5,
+			"tier":             "1000",
+			"cumulative_total": 25,
+			"is_anonymous":     false,
+		})
+
+	case helix.EventSubTypeChannelCheer:
+		return with(broadcaster, viewer, map[string]any{
+			"is_anonymous": false,
+			"message":      "Cheer100 simulated cheer",
+			"bits":         100,
+		})
+
+	case helix.EventSubTypeChannelBan:
+		return with(broadcaster, viewer, user("moderator_user", opts.BroadcasterID, opts.BroadcasterLogin), map[string]any{
+			"reason":       "Simulated ban",
+			"banned_at":    timestamp,
+			"ends_at":      nil,
+			"is_permanent": true,
+		})
+
+	case helix.EventSubTypeChannelPointsCustomRewardRedemptionAdd:
+		rewardID := opts.RewardID
+		if rewardID == "" {
+			rewardID = randomID()
+		}
+		return with(broadcaster, viewer, map[string]any{
+			"id":         randomID(),
+			"user_input": opts.Input,
+			"status":     "unfulfilled",
+			"reward": map[string]any{
+				"id":     rewardID,
+				"title":  "Hydrate",
+				"cost":   500,
+				"prompt": "Drink some water",
+			},
+			"redeemed_at": timestamp,
+		})
+
+	case helix.EventSubTypeHypeTrainBegin, helix.EventSubTypeHypeTrainProgress:
+		level := 1
+		if subscriptionType == helix.EventSubTypeHypeTrainProgress {
+			level = 2
+		}
+		return with(broadcaster, map[string]any{
+			"id":                randomID(),
+			"level":             level,
+			"total":             500 * level,
+			"progress":          500,
+			"goal":              1800,
+			"top_contributions": []map[string]any{contribution},
+			"last_contribution": contribution,
+			"started_at":        timestamp,
+			"expires_at":        now.Add(5 * time.Minute).Format(time.RFC3339Nano),
+		})
+
+	case helix.EventSubTypeHypeTrainEnd:
+		return with(broadcaster, map[string]any{
+			"id":                randomID(),
+			"level":             3,
+			"total":             4000,
+			"top_contributions": []map[string]any{contribution},
+			"started_at":        now.Add(-10 * time.Minute).Format(time.RFC3339Nano),
+			"ended_at":          timestamp,
+			"cooldown_ends_at":  now.Add(time.Hour).Format(time.RFC3339Nano),
+		})
+
+	case TypeAdBreakBegin:
+		return with(broadcaster, user("requester_user", opts.BroadcasterID, opts.BroadcasterLogin), map[string]any{
+			"duration_seconds": 60,
+			"started_at":       timestamp,
+			"is_automatic":     false,
+		})
+	}
+
+	return broadcaster
+}
+
+// user returns the ID, login and name fields of a user in an event, e.g. broadcaster_user_id
+func user(prefix, id, login string) map[string]any {
+	return map[string]any{
+		prefix + "_id":    id,
+		prefix + "_login": login,
+		prefix + "_name":  login,
+	}
+}
+
+// with merges the fields of an event
+func with(fields ...map[string]any) map[string]any {
+	merged := map[string]any{}
+	for _, f := range fields {
+		for k, v := range f {
+			merged[k] = v
+		}
+	}
+	return merged
+}
+
+func randomID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func randomNumber() string {
+	n, _ := rand.Int(rand.Reader, big.NewInt(1_000_000_000))
+	return n.String()
+}
@@ -0,0 +1,219 @@
+package eventsub
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/nicklaw5/helix/v2"
+	"nhooyr.io/websocket"
+)
+
+// DefaultWebSocketURL is the EventSub WebSocket of Twitch
+const DefaultWebSocketURL = "wss://eventsub.wss.twitch.tv/ws"
+
+const (
+	// welcomeTimeout is how long the welcome message is waited for after connecting
+	welcomeTimeout = 10 * time.Second
+	// defaultKeepalive is how often Twitch sends a keepalive when the welcome doesn't say
+	defaultKeepalive = 10 * time.Second
+	// readLimit is the size of the biggest message which is read, notifications are a few kilobytes at most
+	readLimit = 1 << 20
+
+	minBackoff = time.Second
+	maxBackoff = time.Minute
+)
+
+// keepaliveGrace is how much longer than the keepalive timeout a message is waited for before the connection counts
+// as lost, the tests shorten it
+var keepaliveGrace = 5 * time.Second
+
+// wsMessage is a message of the EventSub WebSocket
+type wsMessage struct {
+	Metadata struct {
+		MessageID   string `json:"message_id"`
+		MessageType string `json:"message_type"`
+	} `json:"metadata"`
+	Payload struct {
+		Session      wsSession                  `json:"session"`
+		Subscription helix.EventSubSubscription `json:"subscription"`
+		Event        json.RawMessage            `json:"event"`
+	} `json:"payload"`
+}
+
+type wsSession struct {
+	ID                      string `json:"id"`
+	KeepaliveTimeoutSeconds int    `json:"keepalive_timeout_seconds"`
+	ReconnectURL            string `json:"reconnect_url"`
+}
+
+// keepalive returns how long the connection may be silent before it counts as lost
+func (s wsSession) keepalive() time.Duration {
+	if s.KeepaliveTimeoutSeconds <= 0 {
+		return defaultKeepalive + keepaliveGrace
+	}
+	return time.Duration(s.KeepaliveTimeoutSeconds)*time.Second + keepaliveGrace
+}
+
+func (s *eventSubService) Connect(ctx context.Context) error {
+	defer s.setSession("")
+
+	url := s.opts.WebSocketURL
+	backoff := minBackoff
+	// A reconnect keeps the subscriptions of the session, only new sessions have to subscribe
+	newSession := true
+	var previous *websocket.Conn
+
+	for {
+		conn, session, err := s.welcome(ctx, url)
+
+		// The old connection delivers until the new one is welcomed, it's closed either way
+		if previous != nil {
+			_ = previous.Close(websocket.StatusNormalClosure, "reconnected")
+			previous = nil
+		}
+
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			slog.Error("[eventsub] couldn't connect to the websocket", "url", url, "retry", backoff, "error", err.Error())
+			s.setSession("")
+
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(backoff):
+			}
+			backoff = min(backoff*2, maxBackoff)
+			url = s.opts.WebSocketURL
+			newSession = true
+			continue
+		}
+
+		backoff = minBackoff
+		s.setSession(session.ID)
+		slog.Info("[eventsub] websocket session started", "session", session.ID, "reconnect", !newSession)
+
+		// Twitch closes sessions which don't subscribe to anything within 10 seconds
+		if newSession {
+			go s.subscribeSession(ctx)
+		}
+
+		reconnectURL, err := s.read(ctx, conn, session)
+		if ctx.Err() != nil {
+			_ = conn.Close(websocket.StatusNormalClosure, "shutting down")
+			return nil
+		}
+
+		if reconnectURL != "" {
+			slog.Info("[eventsub] twitch asked to reconnect", "session", session.ID)
+			go func(conn *websocket.Conn) {
+				_, _ = s.read(ctx, conn, session)
+			}(conn)
+
+			previous = conn
+			url = reconnectURL
+			newSession = false
+			continue
+		}
+
+		slog.Warn("[eventsub] lost the websocket connection", "session", session.ID, "error", err.Error())
+		_ = conn.Close(websocket.StatusGoingAway, "reconnecting")
+		url = s.opts.WebSocketURL
+		newSession = true
+	}
+}
+
+// welcome connects to the WebSocket and waits for the session it's welcomed with
+func (s *eventSubService) welcome(ctx context.Context, url string) (*websocket.Conn, wsSession, error) {
+	dialCtx, cancel := context.WithTimeout(ctx, welcomeTimeout)
+	defer cancel()
+
+	conn, _, err := websocket.Dial(dialCtx, url, nil)
+	if err != nil {
+		return nil, wsSession{}, err
+	}
+	conn.SetReadLimit(readLimit)
+
+	msg, err := next(ctx, conn, welcomeTimeout)
+	if err != nil {
+		_ = conn.Close(websocket.StatusGoingAway, "no welcome")
+		return nil, wsSession{}, fmt.Errorf("waiting for the welcome: %w", err)
+	}
+	if msg.Metadata.MessageType != "session_welcome" {
+		_ = conn.Close(websocket.StatusProtocolError, "expected a welcome")
+		return nil, wsSession{}, fmt.Errorf("expected a welcome but got %q", msg.Metadata.MessageType)
+	}
+
+	return conn, msg.Payload.Session, nil
+}
+
+// read handles the messages of a connection until it's lost or Twitch asks to reconnect, in which case the URL to
+// reconnect to is returned
+func (s *eventSubService) read(ctx context.Context, conn *websocket.Conn, session wsSession) (string, error) {
+	for {
+		msg, err := next(ctx, conn, session.keepalive())
+		if err != nil {
+			return "", err
+		}
+
+		switch msg.Metadata.MessageType {
+		case "session_keepalive":
+
+		case "notification":
+			if s.Seen(msg.Metadata.MessageID) {
+				slog.Info("[eventsub] ignoring a duplicate message", "id", msg.Metadata.MessageID, "type", msg.Payload.Subscription.Type)
+				continue
+			}
+
+			if err := s.Notify(ctx, msg.Metadata.MessageID, msg.Payload.Subscription, msg.Payload.Event); err != nil {
+				slog.Error("[eventsub] couldn't handle the notification", "type", msg.Payload.Subscription.Type, "error", err.Error())
+			}
+
+		case "session_reconnect":
+			if msg.Payload.Session.ReconnectURL == "" {
+				return "", errors.New("twitch asked to reconnect without a URL")
+			}
+			return msg.Payload.Session.ReconnectURL, nil
+
+		case "revocation":
+			slog.Warn("[eventsub] subscription revoked", "id", msg.Payload.Subscription.ID, "type", msg.Payload.Subscription.Type, "reason", msg.Payload.Subscription.Status)
+			go s.Revoked(ctx, msg.Payload.Subscription)
+
+		default:
+			slog.Warn("[eventsub] unknown websocket message", "type", msg.Metadata.MessageType)
+		}
+	}
+}
+
+// subscribeSession creates the subscriptions of the enabled channels for the session
+func (s *eventSubService) subscribeSession(ctx context.Context) {
+	result, err := s.Reconcile(ctx)
+	if err != nil {
+		slog.Error("[eventsub] couldn't subscribe the websocket session", "error", err.Error())
+		return
+	}
+
+	slog.Info("[eventsub] websocket session subscribed", "created", len(result.Created), "kept", result.Kept)
+}
+
+// next reads the next message of a connection, the connection is closed when none arrives in time
+func next(ctx context.Context, conn *websocket.Conn, timeout time.Duration) (wsMessage, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	_, data, err := conn.Read(ctx)
+	if err != nil {
+		return wsMessage{}, err
+	}
+
+	var msg wsMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return wsMessage{}, fmt.Errorf("decoding the message: %w", err)
+	}
+	return msg, nil
+}
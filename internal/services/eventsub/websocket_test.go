@@ -0,0 +1,471 @@
+package eventsub
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/esfands/retpaladinbot/internal/db"
+	"github.com/esfands/retpaladinbot/internal/services/events"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/nicklaw5/helix/v2"
+	"nhooyr.io/websocket"
+)
+
+const testChannelID = "1234"
+
+// fakeHelix serves the client of the service with a fake Helix API
+type fakeHelix struct {
+	client *helix.Client
+}
+
+func (f *fakeHelix) Client() *helix.Client    { return f.client }
+func (f *fakeHelix) AppClient() *helix.Client { return f.client }
+func (f *fakeHelix) ValidateToken(string) (*helix.ValidateTokenResponse, error) {
+	return &helix.ValidateTokenResponse{}, nil
+}
+func (f *fakeHelix) Connect(context.Context, string, string, string, []string) error { return nil }
+func (f *fakeHelix) BroadcasterClient(context.Context, string, string) (*helix.Client, error) {
+	return f.client, nil
+}
+
+// Scopes grants every scope the subscriptions need
+func (f *fakeHelix) Scopes(context.Context, string) ([]string, error) {
+	var scopes []string
+	for _, t := range Types {
+		scopes = append(scopes, t.Scope)
+	}
+	return scopes, nil
+}
+
+// fakeAPI records the subscriptions which are created through it
+type fakeAPI struct {
+	mu      sync.Mutex
+	created []helix.EventSubSubscription
+}
+
+func (a *fakeAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	switch r.Method {
+	case http.MethodGet:
+		_, _ = w.Write([]byte(`{"data":[],"total":0,"pagination":{}}`))
+
+	case http.MethodPost:
+		var sub helix.EventSubSubscription
+		if err := json.NewDecoder(r.Body).Decode(&sub); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		a.mu.Lock()
+		sub.ID = fmt.Sprintf("sub-%d", len(a.created)+1)
+		sub.Status = helix.EventSubStatusEnabled
+		a.created = append(a.created, sub)
+		a.mu.Unlock()
+
+		w.WriteHeader(http.StatusAccepted)
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": []helix.EventSubSubscription{sub}})
+
+	case http.MethodDelete:
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (a *fakeAPI) subscriptions() []helix.EventSubSubscription {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return append([]helix.EventSubSubscription(nil), a.created...)
+}
+
+// fakeWebSocket is a fake EventSub WebSocket, every connection is handed to the script of the test
+type fakeWebSocket struct {
+	*httptest.Server
+
+	mu    sync.Mutex
+	dials int
+}
+
+func newFakeWebSocket(t *testing.T, script func(conn *websocket.Conn, dial int)) *fakeWebSocket {
+	t.Helper()
+
+	ws := &fakeWebSocket{}
+	ws.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			t.Errorf("accepting the websocket: %v", err)
+			return
+		}
+		defer conn.CloseNow()
+
+		ws.mu.Lock()
+		ws.dials++
+		dial := ws.dials
+		ws.mu.Unlock()
+
+		script(conn, dial)
+	}))
+	t.Cleanup(ws.Close)
+
+	return ws
+}
+
+func (ws *fakeWebSocket) url() string {
+	return "ws" + strings.TrimPrefix(ws.URL, "http")
+}
+
+func (ws *fakeWebSocket) dialCount() int {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	return ws.dials
+}
+
+// send writes a message of the EventSub WebSocket
+func send(t *testing.T, conn *websocket.Conn, messageID, messageType string, payload any) {
+	t.Helper()
+
+	data, err := json.Marshal(map[string]any{
+		"metadata": map[string]any{
+			"message_id":        messageID,
+			"message_type":      messageType,
+			"message_timestamp": time.Now().UTC().Format(time.RFC3339Nano),
+		},
+		"payload": payload,
+	})
+	if err != nil {
+		t.Fatalf("encoding the message: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := conn.Write(ctx, websocket.MessageText, data); err != nil {
+		t.Errorf("writing the %v message: %v", messageType, err)
+	}
+}
+
+func welcome(sessionID string, keepalive int) map[string]any {
+	return map[string]any{"session": map[string]any{
+		"id":                        sessionID,
+		"status":                    "connected",
+		"keepalive_timeout_seconds": keepalive,
+	}}
+}
+
+// wait blocks until the connection is closed by the client, or the test is over
+func wait(conn *websocket.Conn) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	for {
+		if _, _, err := conn.Read(ctx); err != nil {
+			return
+		}
+	}
+}
+
+type testService struct {
+	*eventSubService
+	api *fakeAPI
+	db  *sql.DB
+	bus events.Service
+}
+
+func newTestService(t *testing.T, wsURL string) *testService {
+	t.Helper()
+
+	conn, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("opening the database: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	queries := db.NewQueries(conn)
+	if err := queries.Migrate(context.Background(), db.MigrateOptions{}); err != nil {
+		t.Fatalf("migrating the database: %v", err)
+	}
+
+	api := &fakeAPI{}
+	apiServer := httptest.NewServer(api)
+	t.Cleanup(apiServer.Close)
+
+	client, err := helix.NewClient(&helix.Options{ClientID: "client", APIBaseURL: apiServer.URL})
+	if err != nil {
+		t.Fatalf("creating the helix client: %v", err)
+	}
+
+	bus := events.New()
+	t.Cleanup(bus.Close)
+
+	service := Setup(SetupOptions{
+		Helix:            &fakeHelix{client: client},
+		Queries:          queries,
+		Events:           bus,
+		Transport:        TransportWebSocket,
+		DefaultChannelID: testChannelID,
+		WebSocketURL:     wsURL,
+	}).(*eventSubService)
+
+	return &testService{eventSubService: service, api: api, db: conn, bus: bus}
+}
+
+// connect runs the transport until the test is over
+func (s *testService) connect(t *testing.T) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if err := s.Connect(ctx); err != nil {
+			t.Errorf("connecting: %v", err)
+		}
+	}()
+
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+}
+
+// eventually fails the test when the condition isn't met within a few seconds
+func eventually(t *testing.T, what string, condition func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %v", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestWebSocketSubscribesTheSession(t *testing.T) {
+	ws := newFakeWebSocket(t, func(conn *websocket.Conn, _ int) {
+		send(t, conn, "welcome", "session_welcome", welcome("session-1", 10))
+		wait(conn)
+	})
+
+	s := newTestService(t, ws.url())
+	s.connect(t)
+
+	eventually(t, "the subscriptions", func() bool {
+		return len(s.api.subscriptions()) == len(Types)
+	})
+
+	for _, sub := range s.api.subscriptions() {
+		if sub.Transport.Method != TransportWebSocket || sub.Transport.SessionID != "session-1" {
+			t.Errorf("%v was subscribed with %+v, want the websocket session", sub.Type, sub.Transport)
+		}
+	}
+	if got := s.session(); got != "session-1" {
+		t.Errorf("session = %q, want session-1", got)
+	}
+}
+
+func TestWebSocketOnlySubscribesWhatTheTokenIsAuthorizedFor(t *testing.T) {
+	ws := newFakeWebSocket(t, func(conn *websocket.Conn, _ int) {
+		send(t, conn, "welcome", "session_welcome", welcome("session-1", 10))
+		wait(conn)
+	})
+
+	s := newTestService(t, ws.url())
+	const otherChannelID = "5678"
+	err := s.opts.Queries.InsertChannel(context.Background(), db.Channel{ID: otherChannelID, Name: "other", Enabled: 1})
+	if err != nil {
+		t.Fatalf("inserting the channel: %v", err)
+	}
+
+	var unscoped int
+	for _, typ := range Types {
+		if typ.Scope == "" {
+			unscoped++
+		}
+	}
+
+	s.connect(t)
+
+	eventually(t, "the subscriptions", func() bool {
+		return len(s.api.subscriptions()) == len(Types)+unscoped
+	})
+
+	for _, sub := range s.api.subscriptions() {
+		typ, _ := GetType(sub.Type)
+		other := sub.Condition.BroadcasterUserID == otherChannelID || sub.Condition.ToBroadcasterUserID == otherChannelID
+		if other && typ.Scope != "" {
+			t.Errorf("%v was subscribed for the other channel, the token only belongs to the default one", sub.Type)
+		}
+	}
+}
+
+func TestWebSocketHandlesNotificationsOnce(t *testing.T) {
+	follow := map[string]any{
+		"subscription": map[string]any{
+			"id":        "sub-1",
+			"type":      helix.EventSubTypeChannelFollow,
+			"version":   "2",
+			"condition": map[string]any{"broadcaster_user_id": testChannelID, "moderator_user_id": testChannelID},
+		},
+		"event": map[string]any{
+			"user_id":             "42",
+			"user_login":          "follower",
+			"user_name":           "Follower",
+			"broadcaster_user_id": testChannelID,
+		},
+	}
+
+	ws := newFakeWebSocket(t, func(conn *websocket.Conn, _ int) {
+		send(t, conn, "welcome", "session_welcome", welcome("session-1", 10))
+		send(t, conn, "keepalive", "session_keepalive", map[string]any{})
+		// Twitch may deliver a notification more than once
+		send(t, conn, "follow-1", "notification", follow)
+		send(t, conn, "follow-1", "notification", follow)
+		wait(conn)
+	})
+
+	s := newTestService(t, ws.url())
+
+	received := make(chan events.Event, 2)
+	events.Subscribe(s.bus, events.EventSub, "test", func(event events.Event) {
+		received <- event
+	})
+
+	s.connect(t)
+
+	select {
+	case event := <-received:
+		if event.Type != helix.EventSubTypeChannelFollow || event.ChannelID != testChannelID {
+			t.Errorf("got a %v event of %v, want a follow of %v", event.Type, event.ChannelID, testChannelID)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the follow")
+	}
+
+	select {
+	case <-received:
+		t.Error("the duplicate follow was handled")
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	var stored int
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM events WHERE type = ? AND user_id = ?", helix.EventSubTypeChannelFollow, "42").Scan(&stored); err != nil {
+		t.Fatalf("counting the stored events: %v", err)
+	}
+	if stored != 1 {
+		t.Errorf("stored %d follows, want 1", stored)
+	}
+}
+
+func TestWebSocketReconnectKeepsTheSubscriptions(t *testing.T) {
+	subscribed := make(chan struct{})
+	oldClosed := make(chan struct{})
+
+	next := newFakeWebSocket(t, func(conn *websocket.Conn, _ int) {
+		send(t, conn, "welcome-2", "session_welcome", welcome("session-2", 10))
+		wait(conn)
+	})
+
+	ws := newFakeWebSocket(t, func(conn *websocket.Conn, dial int) {
+		if dial > 1 {
+			t.Error("the client connected to the original URL again instead of the reconnect URL")
+			return
+		}
+
+		send(t, conn, "welcome-1", "session_welcome", welcome("session-1", 10))
+		<-subscribed
+		send(t, conn, "reconnect", "session_reconnect", map[string]any{"session": map[string]any{
+			"id":            "session-1",
+			"status":        "reconnecting",
+			"reconnect_url": next.url(),
+		}})
+		wait(conn)
+		close(oldClosed)
+	})
+
+	s := newTestService(t, ws.url())
+	s.connect(t)
+
+	eventually(t, "the subscriptions", func() bool {
+		return len(s.api.subscriptions()) == len(Types)
+	})
+	close(subscribed)
+
+	eventually(t, "the new session", func() bool {
+		return s.session() == "session-2"
+	})
+
+	select {
+	case <-oldClosed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the old connection wasn't closed after the new one was welcomed")
+	}
+
+	// The subscriptions move to the new session along with the connection
+	time.Sleep(100 * time.Millisecond)
+	if got := len(s.api.subscriptions()); got != len(Types) {
+		t.Errorf("%d subscriptions were created, want %d", got, len(Types))
+	}
+}
+
+func TestWebSocketReconnectsWhenKeepalivesStop(t *testing.T) {
+	grace := keepaliveGrace
+	keepaliveGrace = 0
+	t.Cleanup(func() { keepaliveGrace = grace })
+
+	ws := newFakeWebSocket(t, func(conn *websocket.Conn, dial int) {
+		send(t, conn, "welcome", "session_welcome", welcome(fmt.Sprintf("session-%d", dial), 1))
+		// No keepalives are sent after the welcome
+		wait(conn)
+	})
+
+	s := newTestService(t, ws.url())
+	s.connect(t)
+
+	eventually(t, "a new connection", func() bool {
+		return ws.dialCount() >= 2
+	})
+	eventually(t, "the new session", func() bool {
+		return s.session() == "session-2"
+	})
+}
+
+func TestWebSocketResubscribesAfterARevocation(t *testing.T) {
+	ws := newFakeWebSocket(t, func(conn *websocket.Conn, _ int) {
+		send(t, conn, "welcome", "session_welcome", welcome("session-1", 10))
+		send(t, conn, "revocation", "revocation", map[string]any{"subscription": map[string]any{
+			"id":        "revoked-1",
+			"type":      helix.EventSubTypeStreamOnline,
+			"version":   "1",
+			"status":    "authorization_revoked",
+			"condition": map[string]any{"broadcaster_user_id": testChannelID},
+		}})
+		wait(conn)
+	})
+
+	s := newTestService(t, ws.url())
+	s.connect(t)
+
+	// Every type is subscribed for the session, stream.online once more after it was revoked
+	eventually(t, "the resubscription", func() bool {
+		return len(s.api.subscriptions()) == len(Types)+1
+	})
+
+	var resubscribed int
+	eventually(t, "the recorded revocation", func() bool {
+		err := s.db.QueryRow("SELECT resubscribed FROM eventsub_revocations WHERE subscription_id = ?", "revoked-1").Scan(&resubscribed)
+		return err == nil
+	})
+	if resubscribed != 1 {
+		t.Errorf("resubscribed = %d, want 1", resubscribed)
+	}
+}
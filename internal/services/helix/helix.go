@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log/slog"
 
+	"github.com/esfands/retpaladinbot/internal/db"
 	"github.com/esfands/retpaladinbot/internal/services/scheduler"
 	"github.com/nicklaw5/helix/v2"
 )
@@ -13,10 +14,16 @@ type SetupOptions struct {
 	ClientID     string
 	ClientSecret string
 	RedirectURI  string
+	// Queries is where the tokens the broadcasters connected are kept
+	Queries *db.Queries
 }
 
 func Setup(ctx context.Context, scheduler scheduler.Service, opts SetupOptions) (Service, error) {
-	svc := &helixService{}
+	svc := &helixService{
+		ctx:          ctx,
+		opts:         opts,
+		broadcasters: make(map[string]*helix.Client),
+	}
 	var err error
 
 	svc.client, err = helix.NewClientWithContext(ctx, &helix.Options{
@@ -28,8 +35,21 @@ func Setup(ctx context.Context, scheduler scheduler.Service, opts SetupOptions)
 		return nil, err
 	}
 
+	svc.appClient, err = helix.NewClientWithContext(ctx, &helix.Options{
+		ClientID:     opts.ClientID,
+		ClientSecret: opts.ClientSecret,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Subscriptions are managed on boot so the token is needed right away
+	if err := svc.refreshAppAccessToken(); err != nil {
+		slog.Error("twitch app access token error", "error", err.Error())
+	}
+
 	// Refresh this token every 1 day
-	_, err = scheduler.Scheduler().Every(1).Days().Do(func() {
+	_, err = scheduler.Scheduler().Every(1).Days().WaitForSchedule().Do(func() {
 		slog.Debug("refreshing twitch app access token")
 		err := svc.refreshAppAccessToken()
 		if err != nil {
@@ -1,23 +1,154 @@
 package helix
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/esfands/retpaladinbot/internal/db"
 	"github.com/nicklaw5/helix/v2"
 )
 
+var (
+	// ErrNotConnected is returned for channels whose broadcaster hasn't connected their Twitch account to the bot
+	ErrNotConnected = errors.New("the broadcaster hasn't connected their Twitch account to the bot")
+	// ErrMissingScope is returned when the broadcaster didn't grant the bot the scope something needs
+	ErrMissingScope = errors.New("the broadcaster didn't grant the bot the permission")
+)
+
 type Service interface {
+	// Client is the client of the app, it only uses the app access token. The tokens of users who log in are never
+	// set on it.
 	Client() *helix.Client
+	// AppClient is a client which only ever uses the app access token, EventSub webhooks can't be managed with a
+	// user access token
+	AppClient() *helix.Client
+	// ValidateToken tells who a user access token belongs to and which scopes it has
+	ValidateToken(accessToken string) (*helix.ValidateTokenResponse, error)
+	// Connect stores the user access token of the broadcaster of a channel, replacing the one stored before. Only
+	// the broadcaster connecting their own channel should ever call this.
+	Connect(ctx context.Context, channelID, accessToken, refreshToken string, scopes []string) error
+	// BroadcasterClient returns a client with the token the broadcaster of the channel connected, which has to
+	// include the scope unless it's empty
+	BroadcasterClient(ctx context.Context, channelID, scope string) (*helix.Client, error)
+	// Scopes returns the scopes the broadcaster of the channel granted the bot, ErrNotConnected is returned when
+	// they didn't connect their account
+	Scopes(ctx context.Context, channelID string) ([]string, error)
 }
 
 type helixService struct {
-	client *helix.Client
+	ctx       context.Context
+	opts      SetupOptions
+	client    *helix.Client
+	appClient *helix.Client
+
+	mu sync.Mutex
+	// broadcasters are the clients with the tokens of the broadcasters, by channel ID
+	broadcasters map[string]*helix.Client
 }
 
 func (h *helixService) Client() *helix.Client {
 	return h.client
 }
 
+func (h *helixService) AppClient() *helix.Client {
+	return h.appClient
+}
+
+func (h *helixService) ValidateToken(accessToken string) (*helix.ValidateTokenResponse, error) {
+	// Validating sets the token on the client for a moment, so it's done on one nobody else uses
+	client, err := helix.NewClientWithContext(h.ctx, &helix.Options{ClientID: h.opts.ClientID})
+	if err != nil {
+		return nil, err
+	}
+
+	valid, res, err := client.ValidateToken(accessToken)
+	if err != nil {
+		return nil, err
+	}
+	if !valid {
+		return nil, fmt.Errorf("invalid token: %v", res.ErrorMessage)
+	}
+
+	return res, nil
+}
+
+func (h *helixService) Connect(ctx context.Context, channelID, accessToken, refreshToken string, scopes []string) error {
+	err := h.opts.Queries.UpsertBroadcasterToken(ctx, db.BroadcasterToken{
+		ChannelID:    channelID,
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		Scopes:       strings.Join(scopes, " "),
+		UpdatedAt:    time.Now().Format(time.RFC3339),
+	})
+	if err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delete(h.broadcasters, channelID)
+	return nil
+}
+
+func (h *helixService) BroadcasterClient(ctx context.Context, channelID, scope string) (*helix.Client, error) {
+	token, err := h.opts.Queries.GetBroadcasterToken(ctx, channelID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotConnected
+	} else if err != nil {
+		return nil, err
+	}
+
+	if scope != "" && !slices.Contains(strings.Fields(token.Scopes), scope) {
+		return nil, fmt.Errorf("%w: %v", ErrMissingScope, scope)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if client, ok := h.broadcasters[channelID]; ok {
+		return client, nil
+	}
+
+	// The client refreshes the token when Twitch says it expired, the refreshed one is kept for the next start
+	client, err := helix.NewClientWithContext(h.ctx, &helix.Options{
+		ClientID:        h.opts.ClientID,
+		ClientSecret:    h.opts.ClientSecret,
+		UserAccessToken: token.AccessToken,
+		RefreshToken:    token.RefreshToken,
+	})
+	if err != nil {
+		return nil, err
+	}
+	client.OnUserAccessTokenRefreshed(func(accessToken, refreshToken string) {
+		err := h.opts.Queries.UpdateBroadcasterAccessToken(h.ctx, channelID, accessToken, refreshToken, time.Now().Format(time.RFC3339))
+		if err != nil {
+			slog.Error("Error storing the refreshed broadcaster token", "channel_id", channelID, "error", err)
+		}
+	})
+
+	h.broadcasters[channelID] = client
+	return client, nil
+}
+
+func (h *helixService) Scopes(ctx context.Context, channelID string) ([]string, error) {
+	token, err := h.opts.Queries.GetBroadcasterToken(ctx, channelID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotConnected
+	} else if err != nil {
+		return nil, err
+	}
+
+	return strings.Fields(token.Scopes), nil
+}
+
 func (h *helixService) refreshAppAccessToken() error {
 	res, err := h.Client().RequestAppAccessToken(
 		[]string{"user:read:email"},
@@ -27,6 +158,7 @@ func (h *helixService) refreshAppAccessToken() error {
 	}
 
 	h.Client().SetAppAccessToken(res.Data.AccessToken)
+	h.AppClient().SetAppAccessToken(res.Data.AccessToken)
 
 	fmt.Println("twitch app access token refreshed", res.Data.ExpiresIn)
 	return nil
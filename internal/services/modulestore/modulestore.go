@@ -0,0 +1,210 @@
+package modulestore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/esfands/retpaladinbot/internal/db"
+	"github.com/esfands/retpaladinbot/pkg/utils"
+)
+
+// Module describes a bot module, modules are registered by the bot when it starts
+type Module struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Settings    []Setting `json:"settings"`
+	// DisabledByDefault modules are off in a channel until they're turned on
+	DisabledByDefault bool `json:"disabled_by_default"`
+}
+
+// Setting is a value a module can be configured with in each channel
+type Setting struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Default     string `json:"default"`
+}
+
+// State is the state of a module in a channel
+type State struct {
+	Enabled bool `json:"enabled"`
+	// Settings holds every setting of the module, the ones which weren't changed have their default
+	Settings map[string]string `json:"settings"`
+}
+
+type Store interface {
+	// Register makes a module known, only registered modules can be changed
+	Register(module Module)
+	// Modules returns the registered modules
+	Modules() []Module
+	// Get returns a registered module by name
+	Get(name string) (Module, bool)
+	// State returns the state of a module in a channel, modules are enabled unless they were disabled or are
+	// disabled by default
+	State(channelID, name string) State
+	// SetEnabled enables or disables a module in a channel
+	SetEnabled(ctx context.Context, channelID, name string, enabled bool) error
+	// SetSetting changes a setting of a module in a channel, an empty value resets it to the default
+	SetSetting(ctx context.Context, channelID, name, setting, value string) error
+}
+
+type key struct {
+	channelID string
+	module    string
+}
+
+type stored struct {
+	enabled  bool
+	settings map[string]string
+}
+
+// databaseStore keeps the module states in memory and writes every change to the database
+type databaseStore struct {
+	queries *db.Queries
+
+	mu      sync.RWMutex
+	modules map[string]Module
+	states  map[key]stored
+}
+
+// Setup loads the module states of every channel from the database
+func Setup(ctx context.Context, queries *db.Queries) (Store, error) {
+	storedModules, err := queries.GetAllChannelModules(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &databaseStore{
+		queries: queries,
+		modules: make(map[string]Module),
+		states:  make(map[key]stored, len(storedModules)),
+	}
+
+	for _, module := range storedModules {
+		settings := make(map[string]string)
+		if err := json.Unmarshal([]byte(module.Settings), &settings); err != nil {
+			return nil, fmt.Errorf("settings of module %v in channel %v: %w", module.Module, module.ChannelID, err)
+		}
+
+		s.states[key{channelID: module.ChannelID, module: module.Module}] = stored{
+			enabled:  module.Enabled == 1,
+			settings: settings,
+		}
+	}
+
+	return s, nil
+}
+
+func (s *databaseStore) Register(module Module) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.modules[module.Name] = module
+}
+
+func (s *databaseStore) Modules() []Module {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	modules := make([]Module, 0, len(s.modules))
+	for _, module := range s.modules {
+		modules = append(modules, module)
+	}
+	slices.SortFunc(modules, func(a, b Module) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return modules
+}
+
+func (s *databaseStore) Get(name string) (Module, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	module, ok := s.modules[name]
+	return module, ok
+}
+
+func (s *databaseStore) State(channelID, name string) State {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	state := State{Enabled: !s.modules[name].DisabledByDefault, Settings: make(map[string]string)}
+	for _, setting := range s.modules[name].Settings {
+		state.Settings[setting.Name] = setting.Default
+	}
+
+	if st, ok := s.states[key{channelID: channelID, module: name}]; ok {
+		state.Enabled = st.enabled
+		maps.Copy(state.Settings, st.settings)
+	}
+
+	return state
+}
+
+func (s *databaseStore) SetEnabled(ctx context.Context, channelID, name string, enabled bool) error {
+	return s.update(ctx, channelID, name, func(st *stored) error {
+		st.enabled = enabled
+		return nil
+	})
+}
+
+func (s *databaseStore) SetSetting(ctx context.Context, channelID, name, setting, value string) error {
+	return s.update(ctx, channelID, name, func(st *stored) error {
+		if !slices.ContainsFunc(s.modules[name].Settings, func(known Setting) bool { return known.Name == setting }) {
+			return fmt.Errorf("module %v has no setting %v", name, setting)
+		}
+
+		if value == "" {
+			delete(st.settings, setting)
+		} else {
+			st.settings[setting] = value
+		}
+		return nil
+	})
+}
+
+// update changes the state of a module in a channel and stores it, nothing changes if storing fails
+func (s *databaseStore) update(ctx context.Context, channelID, name string, change func(st *stored) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.modules[name]; !ok {
+		return fmt.Errorf("unknown module %v", name)
+	}
+
+	k := key{channelID: channelID, module: name}
+	st, ok := s.states[k]
+	if !ok {
+		st = stored{enabled: !s.modules[name].DisabledByDefault}
+	}
+	st.settings = maps.Clone(st.settings)
+	if st.settings == nil {
+		st.settings = make(map[string]string)
+	}
+
+	if err := change(&st); err != nil {
+		return err
+	}
+
+	settings, err := json.Marshal(st.settings)
+	if err != nil {
+		return err
+	}
+
+	err = s.queries.UpsertChannelModule(ctx, db.ChannelModule{
+		ChannelID: channelID,
+		Module:    name,
+		Enabled:   utils.BoolToInt(st.enabled),
+		Settings:  string(settings),
+	})
+	if err != nil {
+		return err
+	}
+
+	s.states[k] = st
+	return nil
+}
@@ -0,0 +1,439 @@
+package rewards
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/esfands/retpaladinbot/internal/db"
+	helixservice "github.com/esfands/retpaladinbot/internal/services/helix"
+	"github.com/esfands/retpaladinbot/pkg/domain"
+	"github.com/esfands/retpaladinbot/pkg/utils"
+	"github.com/nicklaw5/helix/v2"
+)
+
+// DefaultQueue is the queue users are added to when an action doesn't name one
+const DefaultQueue = "default"
+
+var (
+	// ErrNotLoggedIn is returned when Twitch is asked about the rewards of a broadcaster who didn't connect their
+	// account, only their token can manage their rewards
+	ErrNotLoggedIn = errors.New("the broadcaster has to connect their Twitch account to manage channel point rewards")
+	// ErrRewardNotFound is returned when Twitch doesn't know a reward of the broadcaster
+	ErrRewardNotFound = errors.New("the reward doesn't exist")
+	// ErrAlreadyQueued is returned when a user joins a queue they're already in
+	ErrAlreadyQueued = errors.New("the user is already in the queue")
+)
+
+// Redemption statuses a redemption can be completed with
+const (
+	StatusFulfilled = "FULFILLED"
+	StatusCanceled  = "CANCELED"
+)
+
+// RewardOptions are the settings of a channel point reward, settings which are nil are left as they are or get
+// the default of Twitch
+type RewardOptions struct {
+	Title             *string `json:"title"`
+	Cost              *int    `json:"cost"`
+	Prompt            *string `json:"prompt"`
+	Enabled           *bool   `json:"is_enabled"`
+	BackgroundColor   *string `json:"background_color"`
+	UserInputRequired *bool   `json:"is_user_input_required"`
+	// MaxPerStream, MaxPerUserPerStream and GlobalCooldownSeconds turn the limit off when they're 0
+	MaxPerStream          *int  `json:"max_per_stream"`
+	MaxPerUserPerStream   *int  `json:"max_per_user_per_stream"`
+	GlobalCooldownSeconds *int  `json:"global_cooldown_seconds"`
+	SkipRequestQueue      *bool `json:"should_redemptions_skip_request_queue"`
+}
+
+type Service interface {
+	// Action returns the action of a channel point reward, sql.ErrNoRows is returned when it has none
+	Action(ctx context.Context, channelID, rewardID string) (domain.RewardAction, error)
+	// Actions returns the actions of the channel point rewards of a channel
+	Actions(ctx context.Context, channelID string) ([]domain.RewardAction, error)
+	// SaveAction validates and stores the action of a channel point reward
+	SaveAction(ctx context.Context, action domain.RewardAction) (domain.RewardAction, error)
+	// RemoveAction deletes the action of a channel point reward and reports whether it existed
+	RemoveAction(ctx context.Context, channelID, rewardID string) (bool, error)
+
+	// Rewards returns the channel point rewards of a channel from Twitch
+	Rewards(channelID string) ([]helix.ChannelCustomReward, error)
+	// CreateReward creates a channel point reward, the title and cost are required
+	CreateReward(channelID string, opts RewardOptions) (helix.ChannelCustomReward, error)
+	// UpdateReward changes the settings of a channel point reward which the bot created
+	UpdateReward(channelID, rewardID string, opts RewardOptions) (helix.ChannelCustomReward, error)
+	// Complete marks a redemption as fulfilled or canceled, canceling refunds the points
+	Complete(channelID, rewardID, redemptionID, status string) error
+
+	// Enqueue adds a user to a queue of a channel and returns their position
+	Enqueue(ctx context.Context, channelID, queue string, entry domain.QueueEntry) (int, error)
+	// Queue returns the users in a queue of a channel, the one who joined first is first
+	Queue(ctx context.Context, channelID, queue string) ([]domain.QueueEntry, error)
+	// Dequeue removes a user from a queue of a channel and reports whether they were in it
+	Dequeue(ctx context.Context, channelID, queue, userID string) (bool, error)
+	// ClearQueue removes every user from a queue of a channel
+	ClearQueue(ctx context.Context, channelID, queue string) error
+}
+
+type rewardsService struct {
+	queries *db.Queries
+	helix   helixservice.Service
+}
+
+func New(queries *db.Queries, helix helixservice.Service) Service {
+	return &rewardsService{
+		queries: queries,
+		helix:   helix,
+	}
+}
+
+// Validate checks an action and fills in the defaults
+func Validate(action *domain.RewardAction) error {
+	action.Target = strings.ToLower(strings.TrimSpace(action.Target))
+	action.Template = strings.TrimSpace(action.Template)
+
+	if action.RewardID == "" {
+		return errors.New("the reward ID can't be empty")
+	}
+	if !action.Action.Valid() {
+		return fmt.Errorf("unknown action %q, it has to be message, counter, command, queue or timeout", action.Action)
+	}
+
+	switch action.Action {
+	case domain.RewardActionMessage:
+		if action.Template == "" {
+			return errors.New("the message can't be empty")
+		}
+	case domain.RewardActionCounter:
+		if action.Target == "" {
+			return errors.New("the counter to increment has to be named")
+		}
+		if action.Amount == 0 {
+			action.Amount = 1
+		}
+	case domain.RewardActionCommand:
+		if action.Target == "" {
+			return errors.New("the custom command to run has to be named")
+		}
+	case domain.RewardActionQueue:
+		if action.Target == "" {
+			action.Target = DefaultQueue
+		}
+	case domain.RewardActionTimeout:
+		// Twitch allows timeouts of up to two weeks
+		if action.Amount < 1 || action.Amount > 1209600 {
+			return errors.New("the timeout has to be between 1 second and 2 weeks")
+		}
+	}
+
+	return nil
+}
+
+func (s *rewardsService) Action(ctx context.Context, channelID, rewardID string) (domain.RewardAction, error) {
+	stored, err := s.queries.GetRewardAction(ctx, channelID, rewardID)
+	if err != nil {
+		return domain.RewardAction{}, err
+	}
+	return toAction(stored), nil
+}
+
+func (s *rewardsService) Actions(ctx context.Context, channelID string) ([]domain.RewardAction, error) {
+	stored, err := s.queries.GetChannelRewardActions(ctx, channelID)
+	if err != nil {
+		return nil, err
+	}
+
+	actions := make([]domain.RewardAction, 0, len(stored))
+	for _, a := range stored {
+		actions = append(actions, toAction(a))
+	}
+	return actions, nil
+}
+
+func (s *rewardsService) SaveAction(ctx context.Context, action domain.RewardAction) (domain.RewardAction, error) {
+	if err := Validate(&action); err != nil {
+		return action, err
+	}
+
+	return action, s.queries.UpsertRewardAction(ctx, db.RewardAction{
+		ChannelID: action.ChannelID,
+		RewardID:  action.RewardID,
+		Action:    string(action.Action),
+		Target:    action.Target,
+		Amount:    action.Amount,
+		Template:  action.Template,
+		Complete:  utils.BoolToInt(action.Complete),
+		Enabled:   utils.BoolToInt(action.Enabled),
+	})
+}
+
+func (s *rewardsService) RemoveAction(ctx context.Context, channelID, rewardID string) (bool, error) {
+	return s.queries.DeleteRewardAction(ctx, channelID, rewardID)
+}
+
+func (s *rewardsService) Rewards(channelID string) ([]helix.ChannelCustomReward, error) {
+	client, err := s.client(channelID)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := client.GetCustomRewards(&helix.GetCustomRewardsParams{
+		BroadcasterID: channelID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := responseError(res.ResponseCommon); err != nil {
+		return nil, err
+	}
+
+	return res.Data.ChannelCustomRewards, nil
+}
+
+func (s *rewardsService) CreateReward(channelID string, opts RewardOptions) (helix.ChannelCustomReward, error) {
+	client, err := s.client(channelID)
+	if err != nil {
+		return helix.ChannelCustomReward{}, err
+	}
+	if opts.Title == nil || strings.TrimSpace(*opts.Title) == "" {
+		return helix.ChannelCustomReward{}, errors.New("the title can't be empty")
+	}
+	if opts.Cost == nil || *opts.Cost < 1 {
+		return helix.ChannelCustomReward{}, errors.New("the cost has to be at least 1")
+	}
+
+	params := &helix.ChannelCustomRewardsParams{
+		BroadcasterID: channelID,
+		IsEnabled:     true,
+	}
+	opts.apply(params)
+
+	res, err := client.CreateCustomReward(params)
+	if err != nil {
+		return helix.ChannelCustomReward{}, err
+	}
+	return firstReward(res)
+}
+
+func (s *rewardsService) UpdateReward(channelID, rewardID string, opts RewardOptions) (helix.ChannelCustomReward, error) {
+	client, err := s.client(channelID)
+	if err != nil {
+		return helix.ChannelCustomReward{}, err
+	}
+
+	// Twitch resets every setting which isn't sent, so the settings which don't change are sent as they are
+	res, err := client.GetCustomRewards(&helix.GetCustomRewardsParams{
+		BroadcasterID: channelID,
+		ID:            rewardID,
+	})
+	if err != nil {
+		return helix.ChannelCustomReward{}, err
+	}
+	current, err := firstReward(res)
+	if err != nil {
+		return helix.ChannelCustomReward{}, err
+	}
+
+	params := &helix.ChannelCustomRewardsParams{
+		Title:                             current.Title,
+		Cost:                              current.Cost,
+		Prompt:                            current.Prompt,
+		IsEnabled:                         current.IsEnabled,
+		BackgroundColor:                   current.BackgroundColor,
+		IsUserInputRequired:               current.IsUserInputRequired,
+		IsMaxPerStreamEnabled:             current.MaxPerStreamSetting.IsEnabled,
+		MaxPerStream:                      current.MaxPerStreamSetting.MaxPerStream,
+		IsMaxPerUserPerStreamEnabled:      current.MaxPerUserPerStreamSetting.IsEnabled,
+		MaxPerUserPerStream:               current.MaxPerUserPerStreamSetting.MaxPerUserPerStream,
+		IsGlobalCooldownEnabled:           current.GlobalCooldownSetting.IsEnabled,
+		GlobalCooldownSeconds:             current.GlobalCooldownSetting.GlobalCooldownSeconds,
+		ShouldRedemptionsSkipRequestQueue: current.ShouldRedemptionsSkipRequestQueue,
+	}
+	opts.apply(params)
+
+	if strings.TrimSpace(params.Title) == "" {
+		return helix.ChannelCustomReward{}, errors.New("the title can't be empty")
+	}
+	if params.Cost < 1 {
+		return helix.ChannelCustomReward{}, errors.New("the cost has to be at least 1")
+	}
+
+	res, err = client.UpdateCustomReward(&helix.UpdateChannelCustomRewardsParams{
+		ID:                                rewardID,
+		BroadcasterID:                     channelID,
+		Title:                             params.Title,
+		Cost:                              params.Cost,
+		Prompt:                            params.Prompt,
+		IsEnabled:                         params.IsEnabled,
+		BackgroundColor:                   params.BackgroundColor,
+		IsUserInputRequired:               params.IsUserInputRequired,
+		IsMaxPerStreamEnabled:             params.IsMaxPerStreamEnabled,
+		MaxPerStream:                      params.MaxPerStream,
+		IsMaxPerUserPerStreamEnabled:      params.IsMaxPerUserPerStreamEnabled,
+		MaxPerUserPerStream:               params.MaxPerUserPerStream,
+		IsGlobalCooldownEnabled:           params.IsGlobalCooldownEnabled,
+		GlobalCooldownSeconds:             params.GlobalCooldownSeconds,
+		ShouldRedemptionsSkipRequestQueue: params.ShouldRedemptionsSkipRequestQueue,
+	})
+	if err != nil {
+		return helix.ChannelCustomReward{}, err
+	}
+	return firstReward(res)
+}
+
+func (s *rewardsService) Complete(channelID, rewardID, redemptionID, status string) error {
+	client, err := s.client(channelID)
+	if err != nil {
+		return err
+	}
+
+	res, err := client.UpdateChannelCustomRewardsRedemptionStatus(&helix.UpdateChannelCustomRewardsRedemptionStatusParams{
+		ID:            redemptionID,
+		BroadcasterID: channelID,
+		RewardID:      rewardID,
+		Status:        status,
+	})
+	if err != nil {
+		return err
+	}
+	return responseError(res.ResponseCommon)
+}
+
+// client returns the client with the token the broadcaster of the channel connected, only it can manage their rewards
+func (s *rewardsService) client(channelID string) (*helix.Client, error) {
+	client, err := s.helix.BroadcasterClient(context.Background(), channelID, "channel:manage:redemptions")
+	if errors.Is(err, helixservice.ErrNotConnected) || errors.Is(err, helixservice.ErrMissingScope) {
+		return nil, fmt.Errorf("%w: %v", ErrNotLoggedIn, err)
+	}
+	return client, err
+}
+
+func (s *rewardsService) Enqueue(ctx context.Context, channelID, queue string, entry domain.QueueEntry) (int, error) {
+	added, err := s.queries.InsertQueueEntry(ctx, db.QueueEntry{
+		ChannelID: channelID,
+		Queue:     queue,
+		UserID:    entry.UserID,
+		UserLogin: entry.UserLogin,
+		UserName:  entry.UserName,
+		Input:     entry.Input,
+		AddedAt:   time.Now().Format(time.RFC3339),
+	})
+	if err != nil {
+		return 0, err
+	}
+	if !added {
+		return 0, ErrAlreadyQueued
+	}
+
+	entries, err := s.Queue(ctx, channelID, queue)
+	if err != nil {
+		return 0, err
+	}
+	for _, e := range entries {
+		if e.UserID == entry.UserID {
+			return e.Position, nil
+		}
+	}
+	return len(entries), nil
+}
+
+func (s *rewardsService) Queue(ctx context.Context, channelID, queue string) ([]domain.QueueEntry, error) {
+	stored, err := s.queries.GetQueueEntries(ctx, channelID, queue)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]domain.QueueEntry, 0, len(stored))
+	for i, e := range stored {
+		entries = append(entries, domain.QueueEntry{
+			Position:  i + 1,
+			UserID:    e.UserID,
+			UserLogin: e.UserLogin,
+			UserName:  e.UserName,
+			Input:     e.Input,
+			AddedAt:   e.AddedAt,
+		})
+	}
+	return entries, nil
+}
+
+func (s *rewardsService) Dequeue(ctx context.Context, channelID, queue, userID string) (bool, error) {
+	return s.queries.DeleteQueueEntry(ctx, channelID, queue, userID)
+}
+
+func (s *rewardsService) ClearQueue(ctx context.Context, channelID, queue string) error {
+	return s.queries.ClearQueue(ctx, channelID, queue)
+}
+
+func (o RewardOptions) apply(params *helix.ChannelCustomRewardsParams) {
+	if o.Title != nil {
+		params.Title = strings.TrimSpace(*o.Title)
+	}
+	if o.Cost != nil {
+		params.Cost = *o.Cost
+	}
+	if o.Prompt != nil {
+		params.Prompt = *o.Prompt
+	}
+	if o.Enabled != nil {
+		params.IsEnabled = *o.Enabled
+	}
+	if o.BackgroundColor != nil {
+		params.BackgroundColor = *o.BackgroundColor
+	}
+	if o.UserInputRequired != nil {
+		params.IsUserInputRequired = *o.UserInputRequired
+	}
+	if o.MaxPerStream != nil {
+		params.IsMaxPerStreamEnabled = *o.MaxPerStream > 0
+		params.MaxPerStream = *o.MaxPerStream
+	}
+	if o.MaxPerUserPerStream != nil {
+		params.IsMaxPerUserPerStreamEnabled = *o.MaxPerUserPerStream > 0
+		params.MaxPerUserPerStream = *o.MaxPerUserPerStream
+	}
+	if o.GlobalCooldownSeconds != nil {
+		params.IsGlobalCooldownEnabled = *o.GlobalCooldownSeconds > 0
+		params.GlobalCooldownSeconds = *o.GlobalCooldownSeconds
+	}
+	if o.SkipRequestQueue != nil {
+		params.ShouldRedemptionsSkipRequestQueue = *o.SkipRequestQueue
+	}
+}
+
+func toAction(a db.RewardAction) domain.RewardAction {
+	return domain.RewardAction{
+		ChannelID: a.ChannelID,
+		RewardID:  a.RewardID,
+		Action:    domain.RewardActionType(a.Action),
+		Target:    a.Target,
+		Amount:    a.Amount,
+		Template:  a.Template,
+		Complete:  a.Complete == 1,
+		Enabled:   a.Enabled == 1,
+	}
+}
+
+func firstReward(res *helix.ChannelCustomRewardResponse) (helix.ChannelCustomReward, error) {
+	if res.StatusCode == http.StatusNotFound {
+		return helix.ChannelCustomReward{}, ErrRewardNotFound
+	}
+	if err := responseError(res.ResponseCommon); err != nil {
+		return helix.ChannelCustomReward{}, err
+	}
+	if len(res.Data.ChannelCustomRewards) == 0 {
+		return helix.ChannelCustomReward{}, ErrRewardNotFound
+	}
+	return res.Data.ChannelCustomRewards[0], nil
+}
+
+func responseError(res helix.ResponseCommon) error {
+	if res.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("twitch responded with %d: %v", res.StatusCode, res.ErrorMessage)
+	}
+	return nil
+}
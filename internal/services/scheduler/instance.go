@@ -1,15 +1,57 @@
 package scheduler
 
-import "github.com/go-co-op/gocron"
+import (
+	"sync"
+	"time"
+
+	"github.com/go-co-op/gocron"
+)
 
 type Service interface {
 	Scheduler() *gocron.Scheduler
+	// Cron runs a job on a standard 5 field cron expression in a time zone, the tag identifies the job to remove it
+	Cron(tag, expression, timezone string, job func()) error
+	// Every runs a job at an interval, the first run is after the interval has passed
+	Every(tag string, interval time.Duration, job func()) error
+	// Remove stops and removes the jobs with the tag
+	Remove(tag string)
 }
 
 type schedulerService struct {
+	// mu guards building jobs, the gocron builder isn't safe to use from multiple goroutines
+	mu        sync.Mutex
 	scheduler *gocron.Scheduler
 }
 
 func (s *schedulerService) Scheduler() *gocron.Scheduler {
 	return s.scheduler
 }
+
+func (s *schedulerService) Cron(tag, expression, timezone string, job func()) error {
+	spec, err := cronSpec(expression, timezone)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err = s.scheduler.Cron(spec).Tag(tag).Do(job)
+	return err
+}
+
+func (s *schedulerService) Every(tag string, interval time.Duration, job func()) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err := s.scheduler.Every(interval).WaitForSchedule().Tag(tag).Do(job)
+	return err
+}
+
+func (s *schedulerService) Remove(tag string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// An error only means there's no job with the tag
+	_ = s.scheduler.RemoveByTag(tag)
+}
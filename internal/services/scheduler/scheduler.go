@@ -2,6 +2,8 @@ package scheduler
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/go-co-op/gocron"
@@ -16,3 +18,31 @@ func Setup(ctx context.Context) (Service, error) {
 
 	return svc, nil
 }
+
+// ValidateCron checks a cron expression and time zone without scheduling anything
+func ValidateCron(expression, timezone string) error {
+	spec, err := cronSpec(expression, timezone)
+	if err != nil {
+		return err
+	}
+
+	_, err = gocron.NewScheduler(time.UTC).Cron(spec).Do(func() {})
+	if err != nil {
+		return fmt.Errorf("invalid cron expression %q", expression)
+	}
+	return nil
+}
+
+func cronSpec(expression, timezone string) (string, error) {
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	if _, err := time.LoadLocation(timezone); err != nil {
+		return "", fmt.Errorf("unknown time zone %q", timezone)
+	}
+	if strings.Contains(expression, "TZ=") {
+		return "", fmt.Errorf("the time zone can't be part of the cron expression")
+	}
+
+	return fmt.Sprintf("CRON_TZ=%v %v", timezone, strings.TrimSpace(expression)), nil
+}
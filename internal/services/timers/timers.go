@@ -0,0 +1,192 @@
+package timers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/esfands/retpaladinbot/internal/db"
+	"github.com/esfands/retpaladinbot/pkg/domain"
+	"github.com/esfands/retpaladinbot/pkg/utils"
+)
+
+const (
+	// MinInterval is the shortest interval in minutes a timer can have
+	MinInterval = 5
+
+	DefaultInterval = 15
+	DefaultMinLines = 5
+)
+
+// ErrNotFound is returned when a timer doesn't exist
+var ErrNotFound = errors.New("timer not found")
+
+type Store interface {
+	// All returns the timers of every channel
+	All(ctx context.Context) ([]domain.Timer, error)
+	// List returns the timers of a channel
+	List(ctx context.Context, channelID string) ([]domain.Timer, error)
+	// Get returns a timer of a channel by name
+	Get(ctx context.Context, channelID, name string) (domain.Timer, error)
+	// Create validates and stores a new timer, the ID is filled in
+	Create(ctx context.Context, timer domain.Timer) (domain.Timer, error)
+	// Update validates and stores the changes to a timer
+	Update(ctx context.Context, timer domain.Timer) error
+	// Delete deletes a timer of a channel and reports whether it existed
+	Delete(ctx context.Context, channelID, name string) (bool, error)
+}
+
+type databaseStore struct {
+	queries *db.Queries
+}
+
+func NewStore(queries *db.Queries) Store {
+	return &databaseStore{
+		queries: queries,
+	}
+}
+
+// Validate checks the settings of a timer and fills in the defaults
+func Validate(timer *domain.Timer) error {
+	timer.Name = strings.ToLower(strings.TrimSpace(timer.Name))
+	if timer.Condition == "" {
+		timer.Condition = domain.StreamAlways
+	}
+	if timer.Messages == nil {
+		timer.Messages = []string{}
+	}
+
+	if timer.Name == "" {
+		return errors.New("the name can't be empty")
+	}
+	if timer.Interval < MinInterval {
+		return fmt.Errorf("the interval has to be at least %d minutes", MinInterval)
+	}
+	if timer.MinLines < 0 {
+		return errors.New("the minimum number of lines can't be negative")
+	}
+	if !timer.Condition.Valid() {
+		return fmt.Errorf("unknown condition %q, it has to be always, live or offline", timer.Condition)
+	}
+	for _, message := range timer.Messages {
+		if strings.TrimSpace(message) == "" {
+			return errors.New("messages can't be empty")
+		}
+	}
+
+	return nil
+}
+
+func (s *databaseStore) All(ctx context.Context) ([]domain.Timer, error) {
+	stored, err := s.queries.GetAllTimers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return toTimers(stored)
+}
+
+func (s *databaseStore) List(ctx context.Context, channelID string) ([]domain.Timer, error) {
+	stored, err := s.queries.GetChannelTimers(ctx, channelID)
+	if err != nil {
+		return nil, err
+	}
+	return toTimers(stored)
+}
+
+func (s *databaseStore) Get(ctx context.Context, channelID, name string) (domain.Timer, error) {
+	stored, err := s.queries.GetTimer(ctx, channelID, strings.ToLower(name))
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.Timer{}, ErrNotFound
+	} else if err != nil {
+		return domain.Timer{}, err
+	}
+	return toTimer(stored)
+}
+
+func (s *databaseStore) Create(ctx context.Context, timer domain.Timer) (domain.Timer, error) {
+	if err := Validate(&timer); err != nil {
+		return timer, err
+	}
+
+	if _, err := s.Get(ctx, timer.ChannelID, timer.Name); err == nil {
+		return timer, fmt.Errorf("there's already a timer called %v", timer.Name)
+	} else if !errors.Is(err, ErrNotFound) {
+		return timer, err
+	}
+
+	stored, err := fromTimer(timer)
+	if err != nil {
+		return timer, err
+	}
+
+	timer.ID, err = s.queries.InsertTimer(ctx, stored)
+	return timer, err
+}
+
+func (s *databaseStore) Update(ctx context.Context, timer domain.Timer) error {
+	if err := Validate(&timer); err != nil {
+		return err
+	}
+
+	stored, err := fromTimer(timer)
+	if err != nil {
+		return err
+	}
+
+	return s.queries.UpdateTimer(ctx, stored)
+}
+
+func (s *databaseStore) Delete(ctx context.Context, channelID, name string) (bool, error) {
+	return s.queries.DeleteTimer(ctx, channelID, strings.ToLower(name))
+}
+
+func toTimers(stored []db.Timer) ([]domain.Timer, error) {
+	timers := make([]domain.Timer, 0, len(stored))
+	for _, t := range stored {
+		timer, err := toTimer(t)
+		if err != nil {
+			return nil, err
+		}
+		timers = append(timers, timer)
+	}
+	return timers, nil
+}
+
+func toTimer(stored db.Timer) (domain.Timer, error) {
+	var messages []string
+	if err := json.Unmarshal([]byte(stored.Messages), &messages); err != nil {
+		return domain.Timer{}, fmt.Errorf("messages of timer %v: %w", stored.Name, err)
+	}
+
+	return domain.Timer{
+		ID:        stored.ID,
+		ChannelID: stored.ChannelID,
+		Name:      stored.Name,
+		Interval:  stored.Interval,
+		MinLines:  stored.MinLines,
+		Messages:  messages,
+		Condition: domain.StreamCondition(stored.Condition),
+		Enabled:   stored.Enabled == 1,
+	}, nil
+}
+
+func fromTimer(timer domain.Timer) (db.Timer, error) {
+	messages, err := json.Marshal(timer.Messages)
+	if err != nil {
+		return db.Timer{}, err
+	}
+
+	return db.Timer{
+		ID:        timer.ID,
+		ChannelID: timer.ChannelID,
+		Name:      timer.Name,
+		Interval:  timer.Interval,
+		MinLines:  timer.MinLines,
+		Messages:  string(messages),
+		Condition: string(timer.Condition),
+		Enabled:   utils.BoolToInt(timer.Enabled),
+	}, nil
+}
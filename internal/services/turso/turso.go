@@ -14,6 +14,8 @@ import (
 
 type SetupOptions struct {
 	URL string
+	// DefaultChannelID is the channel rows from before multi-channel support are assigned to
+	DefaultChannelID string
 }
 
 func Setup(ctx context.Context, opts SetupOptions) (Service, error) {
@@ -38,6 +40,16 @@ func Setup(ctx context.Context, opts SetupOptions) (Service, error) {
 
 	svc.queries = db.NewQueries(svc.db)
 
+	err = svc.queries.Migrate(ctx, db.MigrateOptions{
+		DefaultChannelID: opts.DefaultChannelID,
+	})
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Error migrating database: %v\n", err)
+		return nil, err
+	}
+
+	slog.Info("Turso database migrated")
+
 	go func() {
 		<-ctx.Done()
 		err := svc.db.Close()
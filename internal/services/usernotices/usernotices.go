@@ -0,0 +1,150 @@
+package usernotices
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/esfands/retpaladinbot/internal/db"
+	"github.com/esfands/retpaladinbot/pkg/domain"
+	"github.com/esfands/retpaladinbot/pkg/utils"
+)
+
+// Type is a kind of chat event the bot can respond to
+type Type struct {
+	// Name is the msg-id of the USERNOTICE, or a name of our own for EventSub events
+	Name        string
+	Description string
+	// EventSub is the subscription type the event is received from, it's empty for USERNOTICEs
+	EventSub string
+	// Amount is what the threshold is compared with, it's empty when the event has no amount
+	Amount   string
+	Template string
+	Enabled  bool
+}
+
+// Types are the chat events the bot can respond to along with their default responses, only subs are responded to
+// unless a channel turns the others on
+var Types = []Type{
+	{Name: "sub", Description: "Someone subscribed", Template: "${notice} PogU", Enabled: true},
+	{Name: "resub", Description: "Someone resubscribed", Amount: "months", Template: "${notice} PogU", Enabled: true},
+	{Name: "subgift", Description: "Someone gifted a sub to someone, gifts of a mass gift are left to submysterygift", Template: "${gifter} gifted a sub to ${recipient} PogU"},
+	{Name: "submysterygift", Description: "Someone gifted subs to the chat", Amount: "count", Template: "${gifter} is gifting ${count} subs to the chat PogU"},
+	{Name: "raid", Description: "Someone raided the channel", Amount: "viewers", Template: "${user} is raiding with ${viewers} viewers PogU"},
+	{Name: "bitsbadgetier", Description: "Someone unlocked a new bits badge", Amount: "count", Template: "${user} unlocked the ${count} bits badge PogU"},
+	{Name: "announcement", Description: "A moderator made an announcement", Template: "${user} announced: ${text}"},
+	{Name: "primepaidupgrade", Description: "Someone upgraded their Prime sub to a paid one", Template: "${user} upgraded their Prime sub to tier ${tier} PogU"},
+	{Name: "giftpaidupgrade", Description: "Someone continued their gifted sub", Template: "${user} is continuing the gift sub from ${gifter} PogU"},
+	{Name: "viewermilestone", Description: "Someone reached a watch streak", Amount: "count", Template: "${user} watched ${count} streams in a row PogU"},
+	// Subs and raids arrive in chat as well, so only the EventSub events chat doesn't have are responded to
+	{Name: "follow", EventSub: "channel.follow", Description: "Someone followed the channel", Template: "Thanks for the follow ${user} <3"},
+	{Name: "cheer", EventSub: "channel.cheer", Description: "Someone cheered bits", Amount: "count", Template: "${user} cheered ${count} bits PogU"},
+	{Name: "ban", EventSub: "channel.ban", Description: "Someone was banned or timed out", Template: "${user} got banned monkaS"},
+	{Name: "redemption", EventSub: "channel.channel_points_custom_reward_redemption.add", Description: "Someone redeemed a channel point reward", Template: "${user} redeemed ${reward}"},
+	{Name: "hypetrain", EventSub: "channel.hype_train.begin", Description: "A hype train started", Template: "A hype train is leaving the station PogU"},
+	{Name: "hypetrainlevel", EventSub: "channel.hype_train.progress", Description: "A hype train reached a new level", Amount: "count", Template: "The hype train reached level ${count} PogU"},
+	{Name: "hypetrainend", EventSub: "channel.hype_train.end", Description: "A hype train ended", Amount: "count", Template: "The hype train ended at level ${count} PogU"},
+	{Name: "adbreak", EventSub: "channel.ad_break.begin", Description: "An ad break started", Amount: "count", Template: "Ads are running for ${count} seconds, thanks for sticking around <3"},
+}
+
+// GetType returns a chat event the bot can respond to by name
+func GetType(name string) (Type, bool) {
+	for _, t := range Types {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return Type{}, false
+}
+
+// GetEventSubType returns the event the bot can respond to which is received from an EventSub subscription type
+func GetEventSubType(subscriptionType string) (Type, bool) {
+	for _, t := range Types {
+		if t.EventSub != "" && t.EventSub == subscriptionType {
+			return t, true
+		}
+	}
+	return Type{}, false
+}
+
+type Store interface {
+	// Get returns the response of a channel to a chat event, or the default response if it wasn't changed
+	Get(ctx context.Context, channelID, noticeType string) (domain.UserNoticeResponse, error)
+	// List returns the responses of a channel to every chat event
+	List(ctx context.Context, channelID string) ([]domain.UserNoticeResponse, error)
+	// Save stores the response of a channel to a chat event
+	Save(ctx context.Context, response domain.UserNoticeResponse) error
+	// Reset goes back to the default response of a chat event
+	Reset(ctx context.Context, channelID, noticeType string) error
+}
+
+type databaseStore struct {
+	queries *db.Queries
+}
+
+func NewStore(queries *db.Queries) Store {
+	return &databaseStore{
+		queries: queries,
+	}
+}
+
+func (s *databaseStore) Get(ctx context.Context, channelID, noticeType string) (domain.UserNoticeResponse, error) {
+	t, ok := GetType(noticeType)
+	if !ok {
+		return domain.UserNoticeResponse{}, fmt.Errorf("unknown chat event %q", noticeType)
+	}
+
+	stored, err := s.queries.GetUserNoticeResponse(ctx, channelID, noticeType)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.UserNoticeResponse{
+			ChannelID: channelID,
+			Type:      t.Name,
+			Enabled:   t.Enabled,
+			Template:  t.Template,
+		}, nil
+	} else if err != nil {
+		return domain.UserNoticeResponse{}, err
+	}
+
+	return domain.UserNoticeResponse{
+		ChannelID: stored.ChannelID,
+		Type:      stored.Type,
+		Enabled:   stored.Enabled == 1,
+		Template:  stored.Template,
+		Threshold: stored.Threshold,
+	}, nil
+}
+
+func (s *databaseStore) List(ctx context.Context, channelID string) ([]domain.UserNoticeResponse, error) {
+	responses := make([]domain.UserNoticeResponse, 0, len(Types))
+	for _, t := range Types {
+		response, err := s.Get(ctx, channelID, t.Name)
+		if err != nil {
+			return nil, err
+		}
+		responses = append(responses, response)
+	}
+	return responses, nil
+}
+
+func (s *databaseStore) Save(ctx context.Context, response domain.UserNoticeResponse) error {
+	if _, ok := GetType(response.Type); !ok {
+		return fmt.Errorf("unknown chat event %q", response.Type)
+	}
+	if response.Threshold < 0 {
+		return errors.New("the threshold can't be negative")
+	}
+
+	return s.queries.UpsertUserNoticeResponse(ctx, db.UserNoticeResponse{
+		ChannelID: response.ChannelID,
+		Type:      response.Type,
+		Enabled:   utils.BoolToInt(response.Enabled),
+		Template:  response.Template,
+		Threshold: response.Threshold,
+	})
+}
+
+func (s *databaseStore) Reset(ctx context.Context, channelID, noticeType string) error {
+	return s.queries.DeleteUserNoticeResponse(ctx, channelID, noticeType)
+}
@@ -0,0 +1,16 @@
+package domain
+
+// Announcement is a message which is sent to a channel on a cron schedule
+type Announcement struct {
+	ID        int    `json:"id"`
+	ChannelID string `json:"channel_id"`
+	// Cron is a standard 5 field cron expression, e.g. 0 12 * * * for every day at noon
+	Cron string `json:"cron"`
+	// Timezone is the IANA time zone the cron expression is evaluated in
+	Timezone string `json:"timezone"`
+	// Message is a template which can use the same variables as custom commands
+	Message   string          `json:"message"`
+	Condition StreamCondition `json:"condition"`
+	Enabled   bool            `json:"enabled"`
+	CreatedAt string          `json:"created_at"`
+}
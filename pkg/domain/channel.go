@@ -0,0 +1,16 @@
+package domain
+
+type Channel struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Prefix  string `json:"prefix"`
+	Enabled bool   `json:"enabled"`
+
+	// ThreadedReplies is whether command responses are sent as replies to the message which triggered them,
+	// when it's off the user is mentioned at the start of the response instead
+	ThreadedReplies bool `json:"threaded_replies"`
+	// CooldownNotice is whether users are told how long they have to wait when a command is on cooldown
+	CooldownNotice bool `json:"cooldown_notice"`
+	// CooldownBuckets is whether global cooldowns are kept separately for regular chatters, subscribers and VIPs
+	CooldownBuckets bool `json:"cooldown_buckets"`
+}
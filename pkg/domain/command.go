@@ -4,10 +4,69 @@ import (
 	"github.com/gempir/go-twitch-irc/v4"
 )
 
+const (
+	DefaultCustomCommandGlobalCooldown = 10
+	DefaultCustomCommandUserCooldown   = 30
+)
+
+// ResponseMode is how a custom command picks one of its responses
+type ResponseMode string
+
+const (
+	ResponseModeUniform    ResponseMode = "uniform"
+	ResponseModeWeighted   ResponseMode = "weighted"
+	ResponseModeRoundRobin ResponseMode = "roundrobin"
+)
+
+// Valid reports whether the response mode is known
+func (m ResponseMode) Valid() bool {
+	return m == ResponseModeUniform || m == ResponseModeWeighted || m == ResponseModeRoundRobin
+}
+
+type CustomCommandResponse struct {
+	Text string `json:"text"`
+	// Weight is how likely the response is picked compared to the others in the weighted mode
+	Weight int `json:"weight"`
+}
+
 type CustomCommand struct {
-	Name       string `json:"name"`
-	Response   string `json:"response"`
-	UsageCount int    `json:"usage_count"`
+	ChannelID string `json:"channel_id"`
+	Name      string `json:"name"`
+	// Response is the first of the responses, it's kept for clients which only know about a single response
+	Response     string                  `json:"response"`
+	Responses    []CustomCommandResponse `json:"responses"`
+	ResponseMode ResponseMode            `json:"response_mode"`
+	// AvoidRepeats is how many of the last picked responses aren't picked again, round-robin never repeats anyway
+	AvoidRepeats   int          `json:"avoid_repeats"`
+	Aliases        []string     `json:"aliases"`
+	Permissions    []Permission `json:"permissions"`
+	GlobalCooldown int          `json:"global_cooldown"`
+	UserCooldown   int          `json:"user_cooldown"`
+	EnabledOffline bool         `json:"enabled_offline"`
+	EnabledOnline  bool         `json:"enabled_online"`
+	Enabled        bool         `json:"enabled"`
+	UsageCount     int          `json:"usage_count"`
+	// ThreadedReply is whether the response is sent as a reply to the message which used the command, if the
+	// channel has threaded replies turned on
+	ThreadedReply bool `json:"threaded_reply"`
+}
+
+// NewCustomCommand creates a custom command with the default settings, it's usable by everyone at any time
+func NewCustomCommand(channelID, name, response string) CustomCommand {
+	return CustomCommand{
+		ChannelID:      channelID,
+		Name:           name,
+		Response:       response,
+		Responses:      []CustomCommandResponse{{Text: response, Weight: 1}},
+		ResponseMode:   ResponseModeUniform,
+		Aliases:        []string{},
+		Permissions:    []Permission{},
+		GlobalCooldown: DefaultCustomCommandGlobalCooldown,
+		UserCooldown:   DefaultCustomCommandUserCooldown,
+		EnabledOffline: true,
+		EnabledOnline:  true,
+		Enabled:        true,
+	}
 }
 
 type Command struct {
@@ -20,10 +79,12 @@ type Command struct {
 	UserCooldown       int          `json:"user_cooldown"`
 	EnabledOffline     bool         `json:"enabled_offline"`
 	EnabledOnline      bool         `json:"enabled_online"`
+	Enabled            bool         `json:"enabled"`
 	UsageCount         int          `json:"usage_count"`
 }
 
-type DefaultCommand interface {
+// DefaultCommandInfo describes a default command, no matter which shape its code has
+type DefaultCommandInfo interface {
 	Name() string
 	Aliases() []string
 	Permissions() []Permission
@@ -32,10 +93,62 @@ type DefaultCommand interface {
 	Conditions() DefaultCommandConditions
 	GlobalCooldown() int
 	UserCooldown() int
-	Code(user twitch.User, context []string) (string, error)
+}
+
+// DefaultCommand is the original command shape which only receives the user and the arguments,
+// new commands should implement invocation.Command instead
+type DefaultCommand interface {
+	DefaultCommandInfo
+	Code(channel Channel, user twitch.User, context []string) (string, error)
+}
+
+// ThreadedReplier is implemented by default commands which decide themselves whether their responses are sent as
+// threaded replies, commands which don't implement it always reply
+type ThreadedReplier interface {
+	ThreadedReply() bool
 }
 
 type DefaultCommandConditions struct {
 	EnabledOnline  bool `json:"enabled_online"`
 	EnabledOffline bool `json:"enabled_offline"`
 }
+
+// CommandOverride holds the settings of a default command which were changed in a channel,
+// nil fields keep the value the command defines itself
+type CommandOverride struct {
+	ChannelID      string        `json:"channel_id"`
+	Name           string        `json:"name"`
+	Enabled        *bool         `json:"enabled"`
+	GlobalCooldown *int          `json:"global_cooldown"`
+	UserCooldown   *int          `json:"user_cooldown"`
+	Permissions    *[]Permission `json:"permissions"`
+	EnabledOnline  *bool         `json:"enabled_online"`
+	EnabledOffline *bool         `json:"enabled_offline"`
+	// Aliases are added on top of the aliases the command defines itself
+	Aliases []string `json:"aliases"`
+	// ThreadedReply overrides whether the responses are sent as replies to the message which used the command
+	ThreadedReply *bool `json:"threaded_reply"`
+}
+
+// Apply returns the command with the overridden settings
+func (o CommandOverride) Apply(command Command) Command {
+	if o.GlobalCooldown != nil {
+		command.GlobalCooldown = *o.GlobalCooldown
+	}
+	if o.UserCooldown != nil {
+		command.UserCooldown = *o.UserCooldown
+	}
+	if o.Permissions != nil {
+		command.Permissions = *o.Permissions
+	}
+	if o.EnabledOnline != nil {
+		command.EnabledOnline = *o.EnabledOnline
+	}
+	if o.EnabledOffline != nil {
+		command.EnabledOffline = *o.EnabledOffline
+	}
+	command.Aliases = append(command.Aliases, o.Aliases...)
+	command.Enabled = o.Enabled == nil || *o.Enabled
+
+	return command
+}
@@ -8,3 +8,27 @@ const (
 	PermissionModerator   Permission = "moderator"
 	PermissionVIP         Permission = "vip"
 )
+
+// AssignablePermissions are the permissions a command can require, admin is only used internally
+var AssignablePermissions = []Permission{
+	PermissionBroadcaster,
+	PermissionModerator,
+	PermissionVIP,
+}
+
+// PermissionsForLevel returns the permissions which meet a permission level, higher levels are included so
+// a command for VIPs can also be used by moderators and the broadcaster. Everyone requires no permissions.
+func PermissionsForLevel(level string) ([]Permission, bool) {
+	switch Permission(level) {
+	case "everyone":
+		return []Permission{}, true
+	case PermissionVIP:
+		return []Permission{PermissionBroadcaster, PermissionModerator, PermissionVIP}, true
+	case PermissionModerator:
+		return []Permission{PermissionBroadcaster, PermissionModerator}, true
+	case PermissionBroadcaster:
+		return []Permission{PermissionBroadcaster}, true
+	default:
+		return nil, false
+	}
+}
@@ -0,0 +1,54 @@
+package domain
+
+// RewardActionType is what the bot does when a channel point reward is redeemed
+type RewardActionType string
+
+const (
+	// RewardActionMessage posts the template in chat
+	RewardActionMessage RewardActionType = "message"
+	// RewardActionCounter adds the amount to the counter named by the target
+	RewardActionCounter RewardActionType = "counter"
+	// RewardActionCommand runs the custom command named by the target with the input of the redemption as arguments
+	RewardActionCommand RewardActionType = "command"
+	// RewardActionQueue adds the redeemer to the queue named by the target
+	RewardActionQueue RewardActionType = "queue"
+	// RewardActionTimeout times the redeemer out for the amount of seconds, for rewards like "time me out"
+	RewardActionTimeout RewardActionType = "timeout"
+)
+
+// Valid reports whether the action is known
+func (a RewardActionType) Valid() bool {
+	switch a {
+	case RewardActionMessage, RewardActionCounter, RewardActionCommand, RewardActionQueue, RewardActionTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// RewardAction is what the bot does when a channel point reward of a channel is redeemed
+type RewardAction struct {
+	ChannelID string           `json:"channel_id"`
+	RewardID  string           `json:"reward_id"`
+	Action    RewardActionType `json:"action"`
+	// Target is the counter, custom command or queue the action works on
+	Target string `json:"target"`
+	// Amount is what a counter is incremented by or how many seconds a timeout lasts
+	Amount int `json:"amount"`
+	// Template is posted in chat after the action, ${reward}, ${count} and ${text} are the redemption
+	Template string `json:"template"`
+	// Complete is whether the redemption is marked as fulfilled when the action succeeds and canceled, which refunds
+	// the points, when it fails. Twitch only allows it for rewards created by the bot.
+	Complete bool `json:"complete"`
+	Enabled  bool `json:"enabled"`
+}
+
+// QueueEntry is a user waiting in a queue of a channel
+type QueueEntry struct {
+	Position  int    `json:"position"`
+	UserID    string `json:"user_id"`
+	UserLogin string `json:"user_login"`
+	UserName  string `json:"user_name"`
+	Input     string `json:"input"`
+	AddedAt   string `json:"added_at"`
+}
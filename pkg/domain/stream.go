@@ -0,0 +1,27 @@
+package domain
+
+// StreamCondition is the stream state a scheduled message is sent in
+type StreamCondition string
+
+const (
+	StreamAlways  StreamCondition = "always"
+	StreamLive    StreamCondition = "live"
+	StreamOffline StreamCondition = "offline"
+)
+
+// Valid reports whether the condition is known
+func (c StreamCondition) Valid() bool {
+	return c == StreamAlways || c == StreamLive || c == StreamOffline
+}
+
+// Allows reports whether a message with the condition can be sent while the stream is live or offline
+func (c StreamCondition) Allows(live bool) bool {
+	switch c {
+	case StreamLive:
+		return live
+	case StreamOffline:
+		return !live
+	default:
+		return true
+	}
+}
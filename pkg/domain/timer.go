@@ -0,0 +1,16 @@
+package domain
+
+// Timer is a group of messages which are posted in rotation, a message is only posted when both the interval has
+// passed and enough chat lines went by since the last one so a dead chat isn't spammed
+type Timer struct {
+	ID        int    `json:"id"`
+	ChannelID string `json:"channel_id"`
+	Name      string `json:"name"`
+	// Interval is the minimum number of minutes between two messages
+	Interval int `json:"interval"`
+	// MinLines is the minimum number of chat lines since the last message
+	MinLines  int             `json:"min_lines"`
+	Messages  []string        `json:"messages"`
+	Condition StreamCondition `json:"condition"`
+	Enabled   bool            `json:"enabled"`
+}
@@ -0,0 +1,12 @@
+package domain
+
+// UserNoticeResponse is how the bot responds to a kind of chat event, e.g. a resub or a raid
+type UserNoticeResponse struct {
+	ChannelID string `json:"channel_id"`
+	// Type is the msg-id of the USERNOTICE, e.g. resub
+	Type     string `json:"type"`
+	Enabled  bool   `json:"enabled"`
+	Template string `json:"template"`
+	// Threshold skips events below it, e.g. raids with fewer viewers, events without an amount are never skipped
+	Threshold int `json:"threshold"`
+}
@@ -31,6 +31,7 @@ var (
 	ErrValidationRejected apiErrorFunc = DefineError(10410, "Validation Rejected", fasthttp.StatusBadRequest)
 
 	// Other client errors
+	ErrExpiredMessage apiErrorFunc = DefineError(10420, "Expired Message", fasthttp.StatusForbidden)
 
 	// Server errors
 	ErrInternalServerError apiErrorFunc = DefineError(10500, "Internal Server Error", fasthttp.StatusInternalServerError)
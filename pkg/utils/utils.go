@@ -28,6 +28,15 @@ func GetTarget(user twitch.User, context []string) string {
 	return strings.ToLower(tagged)
 }
 
+// MentionTarget mentions the target at the start of a message when it's someone else than the user,
+// responses to the user themselves are sent as threaded replies and don't need a mention
+func MentionTarget(user twitch.User, target, message string) string {
+	if strings.EqualFold(user.Name, target) {
+		return message
+	}
+	return fmt.Sprintf("@%v, %v", target, message)
+}
+
 // B2S converts byte slice to a string without memory allocation.
 // See https://groups.google.com/forum/#!msg/Golang-Nuts/ENgbUzYvCuU/90yGx7GUAgAJ .
 //